@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultBranchTemplate is the suggested branch name when none is configured
+const defaultBranchTemplate = "{{.User}}/feature-{{.Date}}"
+
+// branchSlugMaxLen caps the length of the slug in suggested branch names
+const branchSlugMaxLen = 40
+
+// branchNameContext is the data available to the branch name template.
+// The branch is created before any message is generated, so Type and Slug
+// are guessed from the changes (staged ones, or else the working tree).
+type branchNameContext struct {
+	User   string // Lowercased git user.name, spaces as hyphens ("dev" if unset)
+	Date   string // YYYY-MM-DD
+	Type   string // Guessed commit type: docs, test, ci, build, or chore
+	Ticket string // Ticket ID from the current branch name (see branchTicket)
+	Slug   string // A few words describing the changes, e.g. "update-main-go"
+}
+
+// validateBranchTemplate parses the branch name template
+func validateBranchTemplate(text string) error {
+	if text == "" {
+		return nil
+	}
+	// Executing against empty data also catches unknown fields
+	tmpl, err := template.New("branch").Parse(text)
+	if err == nil {
+		err = tmpl.Execute(io.Discard, branchNameContext{})
+	}
+	if err != nil {
+		return fmt.Errorf("invalid branch_template: %w", err)
+	}
+	return nil
+}
+
+// generateDefaultBranchName suggests a name for a new branch from the
+// configured branch_template, falling back to the default template when
+// the configured one renders an invalid name. A name that is already taken
+// is numbered, e.g. "alice/feature-2024-05-01-2".
+func generateDefaultBranchName(diff, currentBranch string) string {
+	ctx := branchNameContextFor(diff, currentBranch)
+	text := getEffectiveConfig().BranchTemplate
+	if text == "" {
+		text = defaultBranchTemplate
+	}
+	name, err := renderBranchName(text, ctx)
+	if err != nil {
+		debugf("branch_template: %v", err)
+		name, _ = renderBranchName(defaultBranchTemplate, ctx)
+	}
+	local, remote := listBranches()
+	if branchCollision(name, local, remote) != "" {
+		if free := nextFreeBranchName(name, local, remote); free != "" {
+			name = free
+		}
+	}
+	return name
+}
+
+// branchNameContextFor gathers the values the branch name template can use
+func branchNameContextFor(diff, currentBranch string) branchNameContext {
+	ctx := branchNameContext{
+		User:   "dev",
+		Date:   time.Now().Format("2006-01-02"),
+		Ticket: branchTicket(getEffectiveConfig(), currentBranch),
+	}
+	output, err := runCommand(exec.Command("git", "config", "user.name"))
+	if err == nil && len(output) > 0 {
+		ctx.User = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(string(output))), " ", "-")
+	}
+
+	if diff == "" {
+		// Nothing staged yet; go by the changes in the working tree
+		if output, err := runCommand(exec.Command("git", "diff", "HEAD")); err == nil {
+			diff = string(output)
+		}
+	}
+	if changes := summarizeChanges(diff); len(changes) > 0 {
+		ctx.Type = heuristicCommitType(changes)
+		ctx.Slug = slugify(heuristicSubject(changes), branchSlugMaxLen)
+	}
+	return ctx
+}
+
+// renderBranchName expands a branch name template and cleans the result
+// into a valid branch name
+func renderBranchName(text string, ctx branchNameContext) (string, error) {
+	tmpl, err := template.New("branch").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var name strings.Builder
+	if err := tmpl.Execute(&name, ctx); err != nil {
+		return "", err
+	}
+	cleaned := cleanBranchName(name.String())
+	if err := checkRefFormat(cleaned); err != nil {
+		return "", fmt.Errorf("%q: %w", cleaned, err)
+	}
+	return cleaned, nil
+}
+
+var (
+	// Characters git doesn't allow in branch names, and whitespace
+	branchInvalidChars = regexp.MustCompile(`[\s~^:?*\[\\]+|\.\.+|@\{`)
+	branchRepeatedDash = regexp.MustCompile(`-{2,}`)
+)
+
+// cleanBranchName tidies a rendered template: invalid characters become
+// hyphens, and the separators left dangling by empty values (as in
+// "{{.Ticket}}-{{.Slug}}" without a ticket) are dropped along with empty
+// path segments
+func cleanBranchName(name string) string {
+	name = branchInvalidChars.ReplaceAllString(name, "-")
+	name = branchRepeatedDash.ReplaceAllString(name, "-")
+	var segments []string
+	for _, segment := range strings.Split(name, "/") {
+		segment = strings.Trim(segment, "-_.")
+		segment = strings.TrimSuffix(segment, ".lock")
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify lowercases text and joins its words with hyphens, cut at a word
+// boundary to at most maxLen characters
+func slugify(text string, maxLen int) string {
+	slug := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if len(slug) > maxLen {
+		slug = slug[:maxLen]
+		if i := strings.LastIndex(slug, "-"); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// remoteBranch is a branch on a remote, as seen in refs/remotes
+type remoteBranch struct {
+	remote string
+	name   string
+}
+
+// listBranches returns the local branches and the remote-tracking branches
+// known to the repository
+func listBranches() (local []string, remote []remoteBranch) {
+	remotes := listRemotes()
+	output, err := runCommand(exec.Command("git", "for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes"))
+	if err != nil {
+		debugf("git for-each-ref failed: %v", err)
+		return nil, nil
+	}
+	for _, ref := range strings.Fields(string(output)) {
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			local = append(local, name)
+			continue
+		}
+		for _, r := range remotes {
+			if name, ok := strings.CutPrefix(ref, "refs/remotes/"+r+"/"); ok && name != "HEAD" {
+				remote = append(remote, remoteBranch{remote: r, name: name})
+				break
+			}
+		}
+	}
+	return local, remote
+}
+
+// branchCollision describes why name can't be created next to the existing
+// branches: it already exists locally or on a remote, or it clashes with a
+// local branch that is a path prefix of it (or the other way around, as
+// "fix" and "fix/login" can't both exist). It returns "" when name is free.
+func branchCollision(name string, local []string, remote []remoteBranch) string {
+	for _, existing := range local {
+		if existing == name {
+			return tr("A branch named '%s' already exists", name)
+		}
+		if strings.HasPrefix(existing, name+"/") || strings.HasPrefix(name, existing+"/") {
+			return tr("'%s' clashes with the existing branch '%s'", name, existing)
+		}
+	}
+	for _, existing := range remote {
+		if existing.name == name {
+			return tr("A branch named '%s' already exists on %s", name, existing.remote)
+		}
+	}
+	return ""
+}
+
+// nextFreeBranchName appends -2, -3, ... to name until it collides with no
+// existing branch, or returns "" when numbering can't help
+func nextFreeBranchName(name string, local []string, remote []remoteBranch) string {
+	for i := 2; i < 100; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if branchCollision(candidate, local, remote) == "" {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// checkRefFormat reports whether git accepts name as a branch name. A
+// leading hyphen is a valid ref but would be read as an option by git
+// checkout, as git branch refuses it too.
+func checkRefFormat(name string) error {
+	if name == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+	if strings.HasPrefix(name, "-") {
+		return fmt.Errorf("branch name cannot start with a hyphen")
+	}
+	if _, err := runCommand(exec.Command("git", "check-ref-format", "refs/heads/"+name)); err != nil {
+		return fmt.Errorf("git check-ref-format rejects it")
+	}
+	return nil
+}
+
+// checkNewBranch makes sure name can be created before trying. When it
+// can't, it returns the problem and, where one exists, an alternative that
+// can: the name cleaned of invalid characters, or numbered past existing
+// branches.
+func checkNewBranch(name string) (problem, alternative string) {
+	local, remote := listBranches()
+	if err := checkRefFormat(name); err != nil {
+		problem = tr("'%s' is not a valid branch name: %v", name, err)
+		alternative = cleanBranchName(name)
+		if checkRefFormat(alternative) != nil {
+			return problem, ""
+		}
+		if branchCollision(alternative, local, remote) != "" {
+			alternative = nextFreeBranchName(alternative, local, remote)
+		}
+		return problem, alternative
+	}
+	if problem := branchCollision(name, local, remote); problem != "" {
+		return problem, nextFreeBranchName(name, local, remote)
+	}
+	return "", ""
+}
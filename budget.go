@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/burritocatai/gitcat/provider"
+)
+
+// Diff budgets are estimates: gitcat doesn't run the models' tokenizers,
+// which differ between providers and aren't all published, so it counts
+// characters and assumes few of them per token to stay under the limit.
+
+const (
+	charsPerToken        = 3     // Conservative estimate for code-heavy text
+	diffTokenCap         = 32000 // Upper bound on diff tokens regardless of context size
+	promptOverheadTokens = 500   // Instructions wrapped around the diff
+	defaultContextTokens = 8192  // Context assumed for unknown models
+	ollamaContextTokens  = 4096  // Ollama's default num_ctx, for models that don't set one
+	collapsedRunKeep     = 5     // Lines kept from a long run of added lines
+	collapsedRunMin      = 20    // Added-line runs longer than this are collapsed
+)
+
+// statFallbackNote tells the user the prompt carries a summary instead of the diff
+const statFallbackNote = "Large diff: sending file stats and the first lines of each file's changes"
+
+// modelContextWindows maps model name prefixes to their context size in
+// tokens. More specific prefixes come first.
+var modelContextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"claude-", 200000},
+	{"gpt-4.1", 1000000},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4", 8192},
+	{"gpt-3.5", 16385},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+}
+
+// estimateTokens estimates the token count of s from its length. It errs
+// high so the budget holds for tokenizers that split code finely.
+func estimateTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+// contextWindow returns the context size for the configured model, using
+// Config.ContextTokens when set
+func contextWindow(config *Config) int {
+	if config.ContextTokens > 0 {
+		return config.ContextTokens
+	}
+	if config.Provider == "ollama" {
+		return ollamaContextWindow(config)
+	}
+	for _, w := range modelContextWindows {
+		if strings.HasPrefix(config.Model, w.prefix) {
+			return w.tokens
+		}
+	}
+	return defaultContextTokens
+}
+
+// ollamaContextWindows caches the context size Ollama reported for each
+// model, so that it is asked once a run
+var ollamaContextWindows sync.Map
+
+// ollamaContextWindow asks Ollama for the num_ctx the model runs with,
+// falling back to Ollama's default when the model doesn't set one or the
+// server can't be reached
+func ollamaContextWindow(config *Config) int {
+	if tokens, ok := ollamaContextWindows.Load(config.Model); ok {
+		return tokens.(int)
+	}
+	tokens := ollamaContextTokens
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if n, err := provider.OllamaContextLength(ctx, config.ProviderSettings(), config.Model); err != nil {
+		debugf("context length of %s unknown, assuming %d: %v", config.Model, tokens, err)
+	} else if n > 0 {
+		tokens = n
+	}
+	ollamaContextWindows.Store(config.Model, tokens)
+	return tokens
+}
+
+// diffTokenBudget returns how many tokens of diff fit in a request that
+// reserves maxTokens for the response
+func diffTokenBudget(config *Config, maxTokens int) int {
+	budget := contextWindow(config) - maxTokens - promptOverheadTokens
+	return max(min(budget, diffTokenCap), 0)
+}
+
+// fitDiffToBudget returns the diff unchanged if it fits within budget
+// tokens and maxLines lines (0 or less means no line limit); otherwise it
+// trims progressively: collapsing long runs of added lines, then capping
+// lines per hunk, and finally keeping only file and hunk headers. ok is
+// false if even the headers don't fit.
+func fitDiffToBudget(diff string, budget, maxLines int) (string, bool) {
+	fits := func(s string) bool {
+		return estimateTokens(s) <= budget && (maxLines <= 0 || strings.Count(s, "\n") <= maxLines)
+	}
+	if fits(diff) {
+		return diff, true
+	}
+
+	files := splitDiff(diff)
+	for i := range files {
+		files[i].hunks = collapseAddedRuns(files[i].hunks)
+	}
+	if out := joinDiff(files); fits(out) {
+		return out, true
+	}
+
+	for _, hunkLines := range []int{100, 30, 10, 0} {
+		trimmed := make([]fileDiff, len(files))
+		for i, f := range files {
+			f.hunks = capHunkLines(f.hunks, hunkLines)
+			trimmed[i] = f
+		}
+		if out := joinDiff(trimmed); fits(out) {
+			return out, true
+		}
+	}
+	return "", false
+}
+
+// collapseAddedRuns shortens long runs of consecutive added lines (new
+// files, pasted blobs) to their first few lines plus an omission marker
+func collapseAddedRuns(hunks string) string {
+	var out []string
+	var run []string
+	flush := func() {
+		if len(run) > collapsedRunMin {
+			out = append(out, run[:collapsedRunKeep]...)
+			out = append(out, fmt.Sprintf("+[... %d added lines omitted ...]", len(run)-collapsedRunKeep))
+		} else {
+			out = append(out, run...)
+		}
+		run = nil
+	}
+	for _, line := range strings.Split(hunks, "\n") {
+		if strings.HasPrefix(line, "+") {
+			run = append(run, line)
+			continue
+		}
+		flush()
+		out = append(out, line)
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// capHunkLines keeps every hunk header and at most maxLines lines of each
+// hunk's body
+func capHunkLines(hunks string, maxLines int) string {
+	var out []string
+	kept, omitted := 0, 0
+	flush := func() {
+		if omitted > 0 {
+			out = append(out, fmt.Sprintf("[... %d lines omitted ...]", omitted))
+		}
+		kept, omitted = 0, 0
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(hunks, "\n"), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			flush()
+			out = append(out, line)
+			continue
+		}
+		if kept < maxLines {
+			out = append(out, line)
+			kept++
+		} else {
+			omitted++
+		}
+	}
+	flush()
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// statFallbackDiff builds a summary for diffs too large to trim: the
+// --stat output followed by the first lines of each file's changes. The
+// number of lines per file, then the stat itself, shrink until it fits.
+func statFallbackDiff(stat, diff string, budget, maxLines int) (string, bool) {
+	statLines := strings.Split(strings.TrimRight(stat, "\n"), "\n")
+	for _, statCap := range []int{len(statLines), 200, 50} {
+		statCap = min(statCap, len(statLines))
+		summary := strings.Join(statLines[:statCap], "\n") + "\n"
+		if statCap < len(statLines) {
+			summary += fmt.Sprintf("[... %d more stat lines omitted ...]\n", len(statLines)-statCap)
+		}
+
+		for _, fileLines := range []int{20, 5, 0} {
+			files := splitDiff(diff)
+			for i := range files {
+				files[i].hunks = firstLines(files[i].hunks, fileLines)
+			}
+			out := "The full diff is too large to include. Summary (git diff --staged --stat):\n" + summary
+			if fileLines > 0 {
+				out += "\nFirst lines of each file's changes:\n" + joinDiff(files)
+			}
+			if estimateTokens(out) <= budget && (maxLines <= 0 || strings.Count(out, "\n") <= maxLines) {
+				return out, true
+			}
+		}
+	}
+	return "", false
+}
+
+// firstLines keeps the first n lines of a file's hunks
+func firstLines(hunks string, n int) string {
+	lines := strings.Split(strings.TrimSuffix(hunks, "\n"), "\n")
+	if hunks == "" || len(lines) <= n {
+		return hunks
+	}
+	kept := append(lines[:n:n], fmt.Sprintf("[... %d lines omitted ...]", len(lines)-n))
+	return strings.Join(kept, "\n") + "\n"
+}
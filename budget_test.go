@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// lines returns n lines made by format, which gets the line number
+func lines(n int, format string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, format+"\n", i)
+	}
+	return b.String()
+}
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   int
+	}{
+		{"configured", Config{Model: "claude-sonnet-4", ContextTokens: 50000}, 50000},
+		{"claude", Config{Model: "claude-sonnet-4"}, 200000},
+		{"more specific prefix first", Config{Model: "gpt-4o-mini"}, 128000},
+		{"gpt-4", Config{Model: "gpt-4-0613"}, 8192},
+		{"unknown", Config{Model: "mistral-large"}, defaultContextTokens},
+		{"configured ollama", Config{Provider: "ollama", Model: "llama3", ContextTokens: 16384}, 16384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contextWindow(&tt.config); got != tt.want {
+				t.Errorf("contextWindow() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffTokenBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		context   int
+		maxTokens int
+		want      int
+	}{
+		{"small context", 8192, 500, 8192 - 500 - promptOverheadTokens},
+		{"capped", 200000, 500, diffTokenCap},
+		{"no room", 1000, 1000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{ContextTokens: tt.context}
+			if got := diffTokenBudget(config, tt.maxTokens); got != tt.want {
+				t.Errorf("diffTokenBudget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitDiffToBudget(t *testing.T) {
+	header := "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n"
+	newFile := header + "@@ -0,0 +1,100 @@\n" + lines(100, "+added %d")
+	changed := header + "@@ -1,200 +1,200 @@\n" + lines(100, "-old %d\n+new %[1]d")
+	collapsed := header + "@@ -0,0 +1,100 @@\n" + lines(5, "+added %d") + "+[... 95 added lines omitted ...]\n"
+	capped := header + "@@ -1,200 +1,200 @@\n" + lines(15, "-old %d\n+new %[1]d") + "[... 170 lines omitted ...]\n"
+	headers := header + "@@ -1,200 +1,200 @@\n[... 200 lines omitted ...]\n"
+
+	tests := []struct {
+		name     string
+		diff     string
+		budget   int
+		maxLines int
+		want     string
+		wantOK   bool
+	}{
+		{"fits", changed, estimateTokens(changed), 0, changed, true},
+		{"added runs collapsed", newFile, estimateTokens(collapsed), 0, collapsed, true},
+		{"hunks capped", changed, estimateTokens(capped), 0, capped, true},
+		{"headers only", changed, estimateTokens(headers), 0, headers, true},
+		{"line limit", changed, diffTokenCap, strings.Count(capped, "\n"), capped, true},
+		{"too small", changed, estimateTokens(headers) - 1, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fitDiffToBudget(tt.diff, tt.budget, tt.maxLines)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("fitDiffToBudget() = %v,\n%s\nwant %v,\n%s", ok, got, tt.wantOK, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollapseAddedRuns(t *testing.T) {
+	tests := []struct {
+		name  string
+		hunks string
+		want  string
+	}{
+		{"short run", "@@ -1 +1,20 @@\n" + lines(20, "+l%d"), "@@ -1 +1,20 @@\n" + lines(20, "+l%d")},
+		{"long run", "@@ -1 +1,21 @@\n" + lines(21, "+l%d"), "@@ -1 +1,21 @@\n" + lines(5, "+l%d") + "+[... 16 added lines omitted ...]\n"},
+		{
+			"runs split by context",
+			"@@ -1,2 +1,23 @@\n" + lines(21, "+a%d") + " ctx\n+b\n",
+			"@@ -1,2 +1,23 @@\n" + lines(5, "+a%d") + "+[... 16 added lines omitted ...]\n ctx\n+b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collapseAddedRuns(tt.hunks); got != tt.want {
+				t.Errorf("collapseAddedRuns() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapHunkLines(t *testing.T) {
+	hunks := "@@ -1,4 +1,4 @@\n" + lines(4, " l%d") + "@@ -20,2 +20,2 @@\n" + lines(2, " m%d")
+	tests := []struct {
+		name     string
+		maxLines int
+		want     string
+	}{
+		{"under the cap", 4, hunks},
+		{"capped", 2, "@@ -1,4 +1,4 @@\n l1\n l2\n[... 2 lines omitted ...]\n@@ -20,2 +20,2 @@\n m1\n m2\n"},
+		{"headers only", 0, "@@ -1,4 +1,4 @@\n[... 4 lines omitted ...]\n@@ -20,2 +20,2 @@\n[... 2 lines omitted ...]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capHunkLines(hunks, tt.maxLines); got != tt.want {
+				t.Errorf("capHunkLines(%d) =\n%s\nwant\n%s", tt.maxLines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		hunks string
+		n     int
+		want  string
+	}{
+		{"empty", "", 3, ""},
+		{"short", "@@ -1 +1 @@\n+a\n", 3, "@@ -1 +1 @@\n+a\n"},
+		{"cut", "@@ -1 +1,3 @@\n+a\n+b\n+c\n", 2, "@@ -1 +1,3 @@\n+a\n[... 2 lines omitted ...]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstLines(tt.hunks, tt.n); got != tt.want {
+				t.Errorf("firstLines(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatFallbackDiff(t *testing.T) {
+	header := "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n"
+	diff := header + "@@ -0,0 +1,100 @@\n" + lines(100, "+added %d")
+	stat := " a.txt | 100 +++++\n 1 file changed, 100 insertions(+)\n"
+
+	got, ok := statFallbackDiff(stat, diff, 1000, 0)
+	if !ok || !strings.Contains(got, stat) || !strings.Contains(got, "+added 19\n[... 81 lines omitted ...]") {
+		t.Errorf("statFallbackDiff() = %v,\n%s\nwant the stat and the first 20 lines of a.txt", ok, got)
+	}
+	// With room for the stat only, the file's lines are dropped
+	got, ok = statFallbackDiff(stat, diff, 60, 0)
+	if !ok || strings.Contains(got, "+added") {
+		t.Errorf("statFallbackDiff() = %v,\n%s\nwant the stat alone", ok, got)
+	}
+	if got, ok := statFallbackDiff(stat, diff, 10, 0); ok {
+		t.Errorf("statFallbackDiff() = %q, want nothing to fit", got)
+	}
+}
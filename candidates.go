@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxCandidates caps how many messages are generated at once
+const maxCandidates = 5
+
+// candidate is one generated commit message offered on the candidates screen
+type candidate struct {
+	message string
+	model   string
+}
+
+// candidateMsg delivers one of the messages generated for a round of
+// candidates, or why it failed
+type candidateMsg struct {
+	round int
+	model string
+	msg   tea.Msg // commitMsgMsg or commitMsgErrMsg
+}
+
+// candidateCount returns how many commit messages to generate to choose
+// from: --candidates, or candidates in the config, between 1 and
+// maxCandidates
+func candidateCount() int {
+	return max(1, min(getEffectiveConfig().Candidates, maxCandidates))
+}
+
+// generate asks for the commit message for req: one, or a round of
+// candidates generated concurrently when more are requested
+func (m model) generate(req commitRequest) (model, tea.Cmd) {
+	n := candidateCount()
+	config := getEffectiveConfig()
+	if n == 1 {
+		m, ctx, tick := m.beginRequest(config.GetCommitModel())
+		return m, tea.Batch(tick, generateCommitMsg(ctx, req))
+	}
+	models := []string{config.GetCommitModel()}
+	if config.CandidateModel != "" && config.CandidateModel != models[0] {
+		models = append(models, config.CandidateModel)
+	}
+	m, ctx, tick := m.beginRequest(strings.Join(models, ", "))
+
+	m.candidateRound++
+	m.candidates = nil
+	m.candidateErrs = nil
+	m.candidatesPending = n
+	round := m.candidateRound
+	cmds := []tea.Cmd{tick}
+	for i := range n {
+		// Alternate models so each writes its share whatever n is
+		model := models[i%len(models)]
+		cmds = append(cmds, func() tea.Msg {
+			return candidateMsg{round: round, model: model, msg: generateCommitMsgWith(ctx, req, model)}
+		})
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// receiveCandidate adds a generated message to the candidates screen as it
+// arrives, opening the screen with the first. When every request of the
+// round fails, it is reported like a single failed generation.
+func (m model) receiveCandidate(msg candidateMsg) (tea.Model, tea.Cmd) {
+	if msg.round != m.candidateRound || (m.phase != "generating" && m.phase != "candidates") {
+		return m, nil // From an earlier round, or the user moved on
+	}
+	m.candidatesPending--
+	switch result := msg.msg.(type) {
+	case commitMsgMsg:
+		m.candidates = append(m.candidates, candidate{message: strings.TrimSpace(string(result)), model: msg.model})
+	case commitMsgErrMsg:
+		debugf("candidate from %s failed: %s", msg.model, result)
+		m.candidateErrs = append(m.candidateErrs, string(result))
+	}
+
+	if len(m.candidates) == 0 {
+		if m.candidatesPending == 0 {
+			return m.Update(commitMsgErrMsg(m.candidateErrs[len(m.candidateErrs)-1]))
+		}
+		return m, nil
+	}
+	if m.phase == "generating" {
+		m.phase = "candidates"
+		m.cursor = 0
+	}
+	m.choices = nil
+	for _, c := range m.candidates {
+		subject, _, _ := strings.Cut(c.message, "\n")
+		m.choices = append(m.choices, subject)
+	}
+	return m, nil
+}
+
+// pickCandidate takes the highlighted candidate on as the generated message
+func (m model) pickCandidate() (tea.Model, tea.Cmd) {
+	picked := m.candidates[m.cursor].message
+	// Requests still running are no longer needed
+	m.cancelPending()
+	m.candidateRound++
+	m.candidates = nil
+	// A message that breaks commitlint is regenerated before the confirm
+	// screen, as a single generation
+	m.phase = "generating"
+	return m.Update(commitMsgMsg(picked))
+}
+
+// candidatesView lists the candidates that have arrived, with the
+// highlighted one in full
+func (m model) candidatesView() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	showModels := false
+	for _, c := range m.candidates {
+		showModels = showModels || c.model != m.candidates[0].model
+	}
+
+	s := m.splitProgress()
+	s += titleStyle.Render(tr("Pick a commit message:")) + "\n\n"
+	for i, choice := range m.choices {
+		cursor := " "
+		if m.cursor == i {
+			cursor = ">"
+			choice = selectedStyle.Render(choice)
+		}
+		if showModels {
+			choice += " " + dimStyle.Render("("+m.candidates[i].model+")")
+		}
+		s += fmt.Sprintf("%s %s\n", cursor, choice)
+	}
+	if m.candidatesPending > 0 {
+		s += dimStyle.Render(tr("  %d more generating...", m.candidatesPending)) + "\n"
+	}
+	if len(m.candidateErrs) > 0 {
+		s += dimStyle.Render(tr("  %d failed: %s", len(m.candidateErrs), truncateRunes(m.candidateErrs[len(m.candidateErrs)-1], 100))) + "\n"
+	}
+	if m.cursor < len(m.candidates) {
+		s += "\n" + m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("14")), m.candidates[m.cursor].message) + "\n"
+	}
+	return s + "\n" + tr("(use arrow keys to select, enter to confirm, esc to go back)") + "\n"
+}
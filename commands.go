@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// command is a gitcat subcommand. Flags are parsed into the package's
+// flag variables whichever command runs; each command only accepts the
+// flags it uses, besides the global ones.
+type command struct {
+	name    string
+	args    string // Positional arguments in the usage line, e.g. "[<range>]"
+	summary string
+	flags   []string
+	run     func(args []string)
+}
+
+var (
+	// globalFlags choose the provider and model and how much diff they get,
+	// and turn on debug logging; every command takes them
+	globalFlags = []string{"model", "m", "commit-model", "pr-model", "provider", "p", "ollama-url", "openai-url", "openai-api-key", "max-diff-lines", "debug"}
+	// messageFlags shape a generated commit message and how it is committed
+	messageFlags = []string{"offline", "auto-type", "type", "scope", "breaking", "signoff", "co-author", "candidates", "verbosity", "ticket", "notes", "no-verify", "yes"}
+	// pushFlags push the branch after committing and shape the PR created
+	// from it
+	pushFlags = []string{"push", "create-pr", "pr-diff", "reviewer", "assignee", "label", "auto-merge", "merge-strategy", "push-remote", "pr-remote"}
+	// legacyFlags are the flags bare gitcat took for what gitcat pr does now
+	legacyFlags = []string{"pr", "squash", "base"}
+)
+
+// commands lists gitcat's subcommands in the order help shows them. It is
+// filled in by init, since help refers back to it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "commit", summary: "Stage, generate a commit message, commit, then offer to push and create a PR (the default)",
+			flags: slices.Concat(messageFlags, pushFlags, []string{"reuse-last", "add-all", "branch", "loop", "allow-empty"}), run: runCommit},
+		{name: "pr", summary: "Generate a PR from the branch's commits without committing",
+			flags: []string{"squash", "base", "pr-diff", "ticket", "reviewer", "assignee", "label", "auto-merge", "merge-strategy", "push-remote", "pr-remote", "no-verify", "yes"}, run: runPR},
+		{name: "reword", args: "[<range>]", summary: "Regenerate the last commit's message, or each message in a range, and amend it",
+			flags: messageFlags, run: runReword},
+		{name: "squash", args: "[<base>]", summary: "Squash the branch's commits into one with a generated message",
+			flags: slices.Concat(messageFlags, pushFlags), run: runSquash},
+		{name: "revert", args: "<commit>", summary: "Revert a commit with a message explaining why",
+			flags: slices.Concat(messageFlags, pushFlags), run: runRevert},
+		{name: "release", args: "[<version>]", summary: "Write release notes since the last tag, then tag and publish the release",
+			flags: []string{"offline", "no-verify"}, run: runRelease},
+		{name: "explain", args: "[<commit|range>]", summary: "Explain in plain words what a commit or range changed and why it matters",
+			flags: []string{"offline"}, run: runExplain},
+		{name: "review", summary: "Review the staged changes for bugs, missing tests, and style, then commit",
+			flags: slices.Concat(messageFlags, pushFlags, []string{"loop"}), run: runReview},
+		{name: "standup", summary: "Summarize your commits since yesterday, across branches, for a standup update",
+			flags: []string{"since", "author", "offline"}, run: runStandup},
+		{name: "lint", args: "[<range>]", summary: "Check the branch's commit messages against the repository's rules, suggesting rewrites",
+			flags: []string{"offline", "verbosity"}, run: runLint},
+		{name: "config", summary: "Open the configuration TUI to set provider, models, and endpoints",
+			run: func([]string) { runConfigUI() }},
+		{name: "help", args: "[<command>]", summary: "Show this help, or the options of a command",
+			run: runHelp},
+	}
+}
+
+// findCommand returns the command called name, or nil
+func findCommand(name string) *command {
+	i := slices.IndexFunc(commands, func(c command) bool { return c.name == name })
+	if i < 0 {
+		return nil
+	}
+	return &commands[i]
+}
+
+// parseCommand picks the command from the arguments flag.Parse left and
+// parses the flags given after its name, before or after its positional
+// arguments (gitcat reword HEAD~3.. --signoff). Everything after "--" is
+// positional. Bare gitcat runs commit, still taking the flags it took
+// before gitcat pr existed.
+func parseCommand() (cmd *command, args []string, bare bool) {
+	if flag.NArg() == 0 {
+		return findCommand("commit"), nil, true
+	}
+	cmd = findCommand(flag.Arg(0))
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q (see gitcat help)\n", flag.Arg(0))
+		os.Exit(exitError)
+	}
+	flag.Usage = func() { printCommandHelp(cmd) }
+	rest := flag.Args()[1:]
+	for len(rest) > 0 {
+		// Parse stops at the first positional argument, or just after "--"
+		flag.CommandLine.Parse(rest)
+		parsed := rest[:len(rest)-flag.NArg()]
+		rest = flag.Args()
+		if len(parsed) > 0 && parsed[len(parsed)-1] == "--" {
+			return cmd, append(args, rest...), false
+		}
+		if len(rest) > 0 {
+			args = append(args, rest[0])
+			rest = rest[1:]
+		}
+	}
+	return cmd, args, false
+}
+
+// accepts reports whether cmd takes the flag called name
+func (c *command) accepts(name string, bare bool) bool {
+	return slices.Contains(globalFlags, name) || slices.Contains(c.flags, name) || (bare && slices.Contains(legacyFlags, name))
+}
+
+// checkCommandFlags exits with an error when a flag was given that cmd
+// doesn't use, rather than ignoring it
+func checkCommandFlags(cmd *command, bare bool) {
+	flag.Visit(func(f *flag.Flag) {
+		if cmd.accepts(f.Name, bare) {
+			return
+		}
+		if bare {
+			fmt.Fprintf(os.Stderr, "Error: --%s is not an option of gitcat (see gitcat help)\n", f.Name)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: --%s is not an option of gitcat %s (see gitcat help %s)\n", f.Name, cmd.name, cmd.name)
+		}
+		os.Exit(exitError)
+	})
+}
+
+// flagShorthands maps flags to their one-letter forms
+var flagShorthands = map[string]string{"model": "m", "provider": "p"}
+
+// flagArgs names the value of each flag that takes one, for help
+var flagArgs = map[string]string{
+	"model": "model", "commit-model": "model", "pr-model": "model", "provider": "provider",
+	"ollama-url": "url", "openai-url": "url", "openai-api-key": "key", "max-diff-lines": "n",
+	"type": "type", "scope": "scope", "co-author": "who", "candidates": "n", "verbosity": "level",
+	"ticket": "id", "branch": "name", "base": "ref", "pr-diff": "depth", "reviewer": "who",
+	"assignee": "who", "label": "label", "merge-strategy": "strategy", "push-remote": "remote",
+	"pr-remote": "remote", "since": "date", "author": "who",
+}
+
+// flagHelp lists names as help shows options, one per line with its usage.
+// Shorthands are shown with the long form rather than on their own.
+func flagHelp(names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		f := flag.Lookup(name)
+		if f == nil || len(name) == 1 {
+			continue
+		}
+		label := "--" + name
+		if short, ok := flagShorthands[name]; ok {
+			label = "-" + short + ", " + label
+		}
+		if arg, ok := flagArgs[name]; ok {
+			label += " <" + arg + ">"
+		}
+		fmt.Fprintf(&b, "    %-30s%s\n", label, f.Usage)
+	}
+	return b.String()
+}
+
+// printCommandHelp shows the usage and options of cmd
+func printCommandHelp(cmd *command) {
+	usage := "gitcat " + cmd.name + " [OPTIONS]"
+	if cmd.args != "" {
+		usage += " " + cmd.args
+	}
+	fmt.Printf("gitcat %s - %s\n\nUSAGE:\n    %s\n", cmd.name, cmd.summary, usage)
+	if cmd.name == "commit" {
+		fmt.Println("    gitcat [OPTIONS]")
+	}
+	if len(cmd.flags) > 0 {
+		fmt.Printf("\nOPTIONS:\n%s", flagHelp(cmd.flags))
+	}
+	fmt.Printf("\nGLOBAL OPTIONS:\n%s", flagHelp(globalFlags))
+}
+
+// runHelp shows the help of the command named in args, or gitcat's
+func runHelp(args []string) {
+	if len(args) == 0 {
+		printHelp()
+		return
+	}
+	cmd := findCommand(args[0])
+	if len(args) > 1 || cmd == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q (see gitcat help)\n", strings.Join(args, " "))
+		os.Exit(exitError)
+	}
+	printCommandHelp(cmd)
+}
+
+// commandList lists the commands with their summaries, for help
+func commandList() string {
+	var b strings.Builder
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "    %-30s%s\n", cmd.name, cmd.summary)
+	}
+	return b.String()
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxLintRetries is how many times a message that breaks the repository's
+// commitlint rules is regenerated before it is shown to the user as is
+const maxLintRetries = 2
+
+// commitlint rule severities; 0 disables a rule
+const (
+	lintWarning = 1
+	lintError   = 2
+)
+
+// lintRule is a commitlint rule: [level, "always"|"never", value]
+type lintRule struct {
+	level int
+	never bool
+	value json.RawMessage
+}
+
+// commitlintConfig holds the rules gitcat checks generated messages against
+type commitlintConfig struct {
+	source string // File the rules came from, for display
+	rules  map[string]lintRule
+}
+
+// conventionalRules mirror @commitlint/config-conventional, the preset most
+// repositories extend
+var conventionalRules = map[string]lintRule{
+	"header-max-length":    {lintError, false, json.RawMessage(`100`)},
+	"type-enum":            {lintError, false, json.RawMessage(`["build","chore","ci","docs","feat","fix","perf","refactor","revert","style","test"]`)},
+	"type-case":            {lintError, false, json.RawMessage(`"lower-case"`)},
+	"type-empty":           {lintError, true, nil},
+	"subject-case":         {lintError, true, json.RawMessage(`["sentence-case","start-case","pascal-case","upper-case"]`)},
+	"subject-empty":        {lintError, true, nil},
+	"subject-full-stop":    {lintError, true, json.RawMessage(`"."`)},
+	"body-leading-blank":   {lintWarning, false, nil},
+	"body-max-line-length": {lintError, false, json.RawMessage(`100`)},
+}
+
+// loadCommitlintConfig reads the repository's commitlint rules from
+// .commitlintrc(.json) or package.json, falling back to the limits in a
+// commitizen config. It returns nil if the repository has neither. Only
+// JSON configs can be read; JavaScript and YAML configs are skipped.
+func loadCommitlintConfig() *commitlintConfig {
+	root, err := getRepoRoot()
+	if err != nil {
+		return nil
+	}
+	for _, name := range []string{".commitlintrc", ".commitlintrc.json"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		config, err := parseCommitlint(name, data)
+		if err == nil {
+			return config
+		}
+		debugf("ignoring %s: %v", name, err)
+	}
+
+	var pkg struct {
+		Commitlint json.RawMessage `json:"commitlint"`
+		Config     struct {
+			Commitizen json.RawMessage `json:"commitizen"`
+		} `json:"config"`
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil && json.Unmarshal(data, &pkg) == nil {
+		if len(pkg.Commitlint) > 0 {
+			if config, err := parseCommitlint("package.json", pkg.Commitlint); err == nil {
+				return config
+			}
+		}
+		if len(pkg.Config.Commitizen) > 0 {
+			if config, err := parseCommitizen("package.json", pkg.Config.Commitizen); err == nil {
+				return config
+			}
+		}
+	}
+
+	for _, name := range []string{".czrc", ".cz.json"} {
+		if data, err := os.ReadFile(filepath.Join(root, name)); err == nil {
+			if config, err := parseCommitizen(name, data); err == nil {
+				return config
+			}
+		}
+	}
+	for _, name := range []string{"commitlint.config.js", "commitlint.config.cjs", "commitlint.config.mjs", "commitlint.config.ts", ".commitlintrc.yaml", ".commitlintrc.yml"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			debugf("found %s; only JSON commitlint configs are supported", name)
+		}
+	}
+	return nil
+}
+
+// parseCommitlint reads a commitlint JSON config, starting from the
+// config-conventional rules when it extends that preset
+func parseCommitlint(source string, data []byte) (*commitlintConfig, error) {
+	var raw struct {
+		Extends json.RawMessage              `json:"extends"`
+		Rules   map[string][]json.RawMessage `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	config := &commitlintConfig{source: source, rules: make(map[string]lintRule)}
+
+	var extends []string
+	if json.Unmarshal(raw.Extends, &extends) != nil {
+		var single string
+		if json.Unmarshal(raw.Extends, &single) == nil {
+			extends = []string{single}
+		}
+	}
+	for _, preset := range extends {
+		if strings.Contains(preset, "config-conventional") {
+			for name, rule := range conventionalRules {
+				config.rules[name] = rule
+			}
+		}
+	}
+
+	for name, parts := range raw.Rules {
+		var rule lintRule
+		if len(parts) == 0 || json.Unmarshal(parts[0], &rule.level) != nil {
+			return nil, fmt.Errorf("rule %s: expected [level, when, value]", name)
+		}
+		if len(parts) > 1 {
+			var when string
+			json.Unmarshal(parts[1], &when)
+			rule.never = when == "never"
+		}
+		if len(parts) > 2 {
+			rule.value = parts[2]
+		}
+		config.rules[name] = rule
+	}
+	return config, nil
+}
+
+// parseCommitizen maps the limits in a commitizen (cz-conventional-changelog
+// style) config onto the equivalent commitlint rules
+func parseCommitizen(source string, data []byte) (*commitlintConfig, error) {
+	var raw struct {
+		MaxHeaderWidth int                        `json:"maxHeaderWidth"`
+		MaxLineWidth   int                        `json:"maxLineWidth"`
+		Types          map[string]json.RawMessage `json:"types"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	config := &commitlintConfig{source: source, rules: make(map[string]lintRule)}
+	if raw.MaxHeaderWidth > 0 {
+		config.rules["header-max-length"] = lintRule{lintError, false, json.RawMessage(fmt.Sprint(raw.MaxHeaderWidth))}
+	}
+	if raw.MaxLineWidth > 0 {
+		config.rules["body-max-line-length"] = lintRule{lintError, false, json.RawMessage(fmt.Sprint(raw.MaxLineWidth))}
+	}
+	if len(raw.Types) > 0 {
+		types := make([]string, 0, len(raw.Types))
+		for name := range raw.Types {
+			types = append(types, name)
+		}
+		slices.Sort(types)
+		value, _ := json.Marshal(types)
+		config.rules["type-enum"] = lintRule{lintError, false, value}
+	}
+	if len(config.rules) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+	return config, nil
+}
+
+// validate returns a description of each error-level rule message breaks
+func (c *commitlintConfig) validate(message string) []string {
+	if c == nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	header := lines[0]
+	commitType, scope := messageType(message), messageScope(header)
+	_, subject, _ := strings.Cut(header, ":")
+	subject = strings.TrimSpace(subject)
+	if commitType == "" {
+		subject = header
+	}
+	var body []string
+	if len(lines) > 1 {
+		body = lines[1:]
+	}
+
+	var violations []string
+	check := func(name string, ok func(rule lintRule) bool, describe func(rule lintRule) string) {
+		rule, found := c.rules[name]
+		if !found || rule.level < lintError || ok(rule) {
+			return
+		}
+		violations = append(violations, describe(rule))
+	}
+	length := func(rule lintRule) int {
+		var n int
+		json.Unmarshal(rule.value, &n)
+		return n
+	}
+	list := func(rule lintRule) []string {
+		var values []string
+		if json.Unmarshal(rule.value, &values) != nil {
+			var single string
+			if json.Unmarshal(rule.value, &single) == nil {
+				values = []string{single}
+			}
+		}
+		return values
+	}
+	mustNot := func(rule lintRule) string {
+		if rule.never {
+			return "must not"
+		}
+		return "must"
+	}
+
+	check("header-max-length", func(r lintRule) bool { return len([]rune(header)) <= length(r) }, func(r lintRule) string {
+		return fmt.Sprintf("header must not be longer than %d characters (is %d)", length(r), len([]rune(header)))
+	})
+	check("header-min-length", func(r lintRule) bool { return len([]rune(header)) >= length(r) }, func(r lintRule) string {
+		return fmt.Sprintf("header must be at least %d characters", length(r))
+	})
+	check("subject-max-length", func(r lintRule) bool { return len([]rune(subject)) <= length(r) }, func(r lintRule) string {
+		return fmt.Sprintf("subject must not be longer than %d characters (is %d)", length(r), len([]rune(subject)))
+	})
+	check("type-empty", func(r lintRule) bool { return (commitType == "") == !r.never }, func(r lintRule) string {
+		return fmt.Sprintf("type %s be empty", mustNot(r))
+	})
+	check("scope-empty", func(r lintRule) bool { return (scope == "") == !r.never }, func(r lintRule) string {
+		return fmt.Sprintf("scope %s be empty", mustNot(r))
+	})
+	check("subject-empty", func(r lintRule) bool { return (subject == "") == !r.never }, func(r lintRule) string {
+		return fmt.Sprintf("subject %s be empty", mustNot(r))
+	})
+	check("type-enum", func(r lintRule) bool { return commitType == "" || slices.Contains(list(r), commitType) != r.never }, func(r lintRule) string {
+		return fmt.Sprintf("type %s be one of [%s]", mustNot(r), strings.Join(list(r), ", "))
+	})
+	check("scope-enum", func(r lintRule) bool {
+		if scope == "" || len(list(r)) == 0 {
+			return true
+		}
+		for _, s := range strings.Split(scope, ",") {
+			if slices.Contains(list(r), strings.TrimSpace(s)) == r.never {
+				return false
+			}
+		}
+		return true
+	}, func(r lintRule) string {
+		return fmt.Sprintf("scope %s be one of [%s]", mustNot(r), strings.Join(list(r), ", "))
+	})
+	for _, part := range []struct{ name, value string }{{"type", commitType}, {"scope", scope}, {"subject", subject}} {
+		check(part.name+"-case", func(r lintRule) bool { return part.value == "" || matchesAnyCase(part.value, list(r)) != r.never }, func(r lintRule) string {
+			return fmt.Sprintf("%s %s be %s", part.name, mustNot(r), strings.Join(list(r), " or "))
+		})
+	}
+	check("subject-full-stop", func(r lintRule) bool {
+		stop := "."
+		if values := list(r); len(values) > 0 {
+			stop = values[0]
+		}
+		return strings.HasSuffix(subject, stop) != r.never
+	}, func(r lintRule) string {
+		return fmt.Sprintf("subject %s end with a full stop", mustNot(r))
+	})
+	check("body-leading-blank", func(r lintRule) bool { return len(body) == 0 || (body[0] == "") != r.never }, func(r lintRule) string {
+		return fmt.Sprintf("body %s begin with a blank line", mustNot(r))
+	})
+	check("body-max-line-length", func(r lintRule) bool {
+		for _, line := range body {
+			if len([]rune(line)) > length(r) && !strings.Contains(line, "://") {
+				return false
+			}
+		}
+		return true
+	}, func(r lintRule) string {
+		return fmt.Sprintf("body lines must not be longer than %d characters", length(r))
+	})
+	return violations
+}
+
+// matchesAnyCase reports whether s is in any of the commitlint cases
+func matchesAnyCase(s string, cases []string) bool {
+	for _, c := range cases {
+		if matchesCase(s, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCase reports whether s is already in the named commitlint case
+func matchesCase(s, name string) bool {
+	runes := []rune(s)
+	upperFirst := func(s string) string {
+		r := []rune(s)
+		if len(r) > 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		return string(r)
+	}
+	switch name {
+	case "lower-case", "lowercase", "lowerCase":
+		return s == strings.ToLower(s)
+	case "upper-case", "uppercase":
+		return s == strings.ToUpper(s)
+	case "sentence-case", "sentencecase":
+		i := strings.IndexFunc(s, unicode.IsLetter)
+		return i >= 0 && s == s[:i]+upperFirst(s[i:])
+	case "start-case":
+		for _, word := range strings.Fields(s) {
+			if word != upperFirst(word) {
+				return false
+			}
+		}
+		return true
+	case "pascal-case", "camel-case":
+		if strings.ContainsAny(s, " -_") || len(runes) == 0 {
+			return false
+		}
+		return unicode.IsUpper(runes[0]) == (name == "pascal-case")
+	case "kebab-case":
+		return s == strings.ToLower(s) && !strings.ContainsAny(s, " _")
+	case "snake-case":
+		return s == strings.ToLower(s) && !strings.ContainsAny(s, " -")
+	}
+	return true // Unknown cases are not enforced
+}
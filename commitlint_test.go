@@ -0,0 +1,201 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCommitlint(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantErr   bool
+		wantRules []string
+		check     func(t *testing.T, c *commitlintConfig)
+	}{
+		{
+			name:      "extends conventional",
+			data:      `{"extends": ["@commitlint/config-conventional"]}`,
+			wantRules: []string{"body-leading-blank", "body-max-line-length", "header-max-length", "subject-case", "subject-empty", "subject-full-stop", "type-case", "type-empty", "type-enum"},
+		},
+		{
+			name:      "extends as a string",
+			data:      `{"extends": "@commitlint/config-conventional", "rules": {"header-max-length": [2, "always", 72]}}`,
+			wantRules: []string{"body-leading-blank", "body-max-line-length", "header-max-length", "subject-case", "subject-empty", "subject-full-stop", "type-case", "type-empty", "type-enum"},
+			check: func(t *testing.T, c *commitlintConfig) {
+				if got := string(c.rules["header-max-length"].value); got != "72" {
+					t.Errorf("header-max-length = %s, want the override 72", got)
+				}
+			},
+		},
+		{
+			name:      "own rules only",
+			data:      `{"rules": {"scope-enum": [2, "always", ["api", "cli"]], "scope-empty": [2, "never"], "subject-case": [0]}}`,
+			wantRules: []string{"scope-empty", "scope-enum", "subject-case"},
+			check: func(t *testing.T, c *commitlintConfig) {
+				if rule := c.rules["scope-empty"]; rule.level != lintError || !rule.never {
+					t.Errorf("scope-empty = %+v, want an error-level never rule", rule)
+				}
+				if rule := c.rules["subject-case"]; rule.level != 0 {
+					t.Errorf("subject-case level = %d, want disabled", rule.level)
+				}
+			},
+		},
+		{name: "rule without level", data: `{"rules": {"type-enum": []}}`, wantErr: true},
+		{name: "invalid JSON", data: `{"rules":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseCommitlint(".commitlintrc", []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCommitlint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := ruleNames(c); !slices.Equal(got, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", got, tt.wantRules)
+			}
+			if tt.check != nil {
+				tt.check(t, c)
+			}
+		})
+	}
+}
+
+func TestParseCommitizen(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantErr   bool
+		wantRules map[string]string
+	}{
+		{
+			name: "limits and types",
+			data: `{"path": "cz-conventional-changelog", "maxHeaderWidth": 72, "maxLineWidth": 80, "types": {"fix": {}, "feat": {}}}`,
+			wantRules: map[string]string{
+				"header-max-length":    "72",
+				"body-max-line-length": "80",
+				"type-enum":            `["feat","fix"]`,
+			},
+		},
+		{name: "header only", data: `{"maxHeaderWidth": 50}`, wantRules: map[string]string{"header-max-length": "50"}},
+		{name: "no limits", data: `{"path": "cz-conventional-changelog"}`, wantErr: true},
+		{name: "invalid JSON", data: `not json`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseCommitizen(".czrc", []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCommitizen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(c.rules) != len(tt.wantRules) {
+				t.Errorf("rules = %v, want %v", ruleNames(c), tt.wantRules)
+			}
+			for name, want := range tt.wantRules {
+				if got := string(c.rules[name].value); got != want {
+					t.Errorf("%s = %s, want %s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitlintValidate(t *testing.T) {
+	conventional, err := parseCommitlint(".commitlintrc", []byte(`{"extends": ["@commitlint/config-conventional"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scoped, err := parseCommitlint(".commitlintrc", []byte(`{"rules": {
+		"scope-enum": [2, "always", ["api", "cli"]],
+		"scope-empty": [2, "never"],
+		"header-max-length": [1, "always", 10],
+		"subject-max-length": [2, "always", 30]
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  *commitlintConfig
+		message string
+		want    []string // Substrings of the expected violations, in order
+	}{
+		{"valid message", conventional, "feat(api): add pagination to list endpoints", nil},
+		{"valid with body", conventional, "fix: handle empty config\n\nThe file may exist but be empty.", nil},
+		{"unknown type", conventional, "feature: add pagination", []string{"type must be one of"}},
+		{"missing type", conventional, "add pagination", []string{"type must not be empty"}},
+		{"sentence-case subject", conventional, "feat: Add pagination", []string{"subject must not be sentence-case"}},
+		{"upper-case type", conventional, "FEAT: add pagination", []string{"type must be one of", "type must be lower-case"}},
+		{"full stop", conventional, "docs: fix typo.", []string{"subject must not end with a full stop"}},
+		{"long header", conventional, "feat: " + strings.Repeat("a", 100), []string{"header must not be longer than 100 characters (is 106)"}},
+		{"long body line", conventional, "fix: wrap\n\n" + strings.Repeat("b", 101), []string{"body lines must not be longer than 100 characters"}},
+		{"long URL in body", conventional, "fix: link\n\nhttps://example.com/" + strings.Repeat("c", 100), nil},
+		{"body warning only", conventional, "fix: wrap\nno blank line", nil},
+		{"scope in enum", scoped, "feat(cli): add flag", nil},
+		{"list of scopes", scoped, "feat(api, cli): add flag", nil},
+		{"scope not in enum", scoped, "feat(web): add page", []string{"scope must be one of [api, cli]"}},
+		{"scope required", scoped, "feat: add page", []string{"scope must not be empty"}},
+		{"long subject", scoped, "feat(api): " + strings.Repeat("d", 31), []string{"subject must not be longer than 30 characters (is 31)"}},
+		{"nil config", nil, "anything at all.", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.validate(tt.message)
+			if len(got) != len(tt.want) {
+				t.Fatalf("validate(%q) = %q, want %d violation(s) like %q", tt.message, got, len(tt.want), tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("violation %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchesCase(t *testing.T) {
+	tests := []struct {
+		s, name string
+		want    bool
+	}{
+		{"add flag", "lower-case", true},
+		{"Add flag", "lower-case", false},
+		{"ADD FLAG", "upper-case", true},
+		{"Add flag", "upper-case", false},
+		{"Add flag", "sentence-case", true},
+		{"add flag", "sentence-case", false},
+		{"123 Add flag", "sentence-case", true},
+		{"Add Flag", "start-case", true},
+		{"Add flag", "start-case", false},
+		{"AddFlag", "pascal-case", true},
+		{"addFlag", "pascal-case", false},
+		{"addFlag", "camel-case", true},
+		{"add flag", "camel-case", false},
+		{"add-flag", "kebab-case", true},
+		{"add_flag", "kebab-case", false},
+		{"add_flag", "snake-case", true},
+		{"add-flag", "snake-case", false},
+		{"Anything", "dot-case", true},
+	}
+	for _, tt := range tests {
+		if got := matchesCase(tt.s, tt.name); got != tt.want {
+			t.Errorf("matchesCase(%q, %q) = %v, want %v", tt.s, tt.name, got, tt.want)
+		}
+	}
+}
+
+// ruleNames lists the rules of c in order
+func ruleNames(c *commitlintConfig) []string {
+	var names []string
+	for name := range c.rules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commit type list modes (Config.CommitTypesMode)
+const (
+	commitTypesExtend  = "extend"  // Custom types follow the defaults (default)
+	commitTypesReplace = "replace" // Only the custom types are offered
+)
+
+// defaultCommitTypes are the Conventional Commits types
+var defaultCommitTypes = []CommitType{
+	{Name: "feat", Description: "New feature"},
+	{Name: "fix", Description: "Bug fix"},
+	{Name: "docs", Description: "Documentation changes"},
+	{Name: "style", Description: "Code style changes (formatting, etc.)"},
+	{Name: "refactor", Description: "Code refactoring"},
+	{Name: "perf", Description: "Performance improvements"},
+	{Name: "test", Description: "Test changes"},
+	{Name: "build", Description: "Build system changes"},
+	{Name: "ci", Description: "CI configuration changes"},
+	{Name: "chore", Description: "Other changes"},
+}
+
+// commitTypesFor returns the types offered in the picker: the defaults
+// followed by the configured ones, or only the configured ones in replace
+// mode. A configured type named like a default replaces it in place.
+func commitTypesFor(config *Config) []CommitType {
+	if config == nil || len(config.CommitTypes) == 0 {
+		return defaultCommitTypes
+	}
+	if config.CommitTypesMode == commitTypesReplace {
+		return config.CommitTypes
+	}
+
+	types := append([]CommitType{}, defaultCommitTypes...)
+	for _, custom := range config.CommitTypes {
+		replaced := false
+		for i, t := range types {
+			if t.Name == custom.Name {
+				types[i] = custom
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			types = append(types, custom)
+		}
+	}
+	return types
+}
+
+// validateCommitTypes checks the configured type list and mode
+func validateCommitTypes(config *Config) error {
+	switch config.CommitTypesMode {
+	case "", commitTypesExtend, commitTypesReplace:
+	default:
+		return fmt.Errorf("unknown commit_types_mode %q (use extend or replace)", config.CommitTypesMode)
+	}
+	if config.CommitTypesMode == commitTypesReplace && len(config.CommitTypes) == 0 {
+		return fmt.Errorf("commit_types_mode is replace but commit_types is empty")
+	}
+	for _, t := range config.CommitTypes {
+		if t.Name == "" || strings.ContainsAny(t.Name, " \t():!") {
+			return fmt.Errorf("invalid commit type name %q", t.Name)
+		}
+	}
+	return nil
+}
+
+// messageType extracts the type from a conventional commit subject such as
+// "fix(api)!: ...", or "" if the message doesn't follow the format
+func messageType(message string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	prefix, _, ok := strings.Cut(subject, ":")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexAny(prefix, "(!"); i >= 0 {
+		prefix = prefix[:i]
+	}
+	if prefix == "" || strings.ContainsAny(prefix, " \t") {
+		return ""
+	}
+	return prefix
+}
+
+// markBreaking makes message a conventional breaking change: "!" after the
+// type/scope and a BREAKING CHANGE footer, which repeats the subject's
+// description if the message doesn't already have one
+func markBreaking(message string) string {
+	subject, rest, hasBody := strings.Cut(strings.TrimRight(message, "\n"), "\n")
+	prefix, description, ok := strings.Cut(subject, ":")
+	if ok && !strings.ContainsAny(prefix, " \t") && !strings.HasSuffix(prefix, "!") {
+		subject = prefix + "!:" + description
+	}
+	message = subject
+	if hasBody {
+		message += "\n" + rest
+	}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return message
+		}
+	}
+	return message + "\n\nBREAKING CHANGE: " + strings.TrimSpace(description)
+}
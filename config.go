@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/burritocatai/gitcat/config"
+	"github.com/burritocatai/gitcat/generate"
+)
+
+// The config file's types live in the config package, so that Go programs
+// importing gitcat load and pass the same settings
+type (
+	Config           = config.Config
+	CommitType       = config.CommitType
+	GenerationParams = config.GenerationParams
+	TrailerRule      = config.TrailerRule
+	RedactRule       = config.RedactRule
+	DepthRule        = config.DepthRule
+)
+
+// loadConfig loads the configuration from the config file and checks the
+// settings of gitcat's own features
+func loadConfig() (*Config, error) {
+	path, err := config.Path()
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfig(loaded); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	loaded.Logf = debugf
+	return loaded, nil
+}
+
+// validateConfig checks the settings the config package leaves to gitcat
+func validateConfig(c *Config) error {
+	if _, err := compileRedactions(c.Redact); err != nil {
+		return err
+	}
+	if c.DiffDepth != "" && !validDiffDepth(c.DiffDepth) {
+		return fmt.Errorf("unknown diff_depth %q (use full, hunks, or stat)", c.DiffDepth)
+	}
+	for _, rule := range c.DiffDepthOverrides {
+		if !validDiffDepth(rule.Depth) {
+			return fmt.Errorf("unknown depth %q for %q (use full, hunks, or stat)", rule.Depth, rule.Path)
+		}
+	}
+	if c.PRDiff != "" && c.PRDiff != prDiffNone && !validDiffDepth(c.PRDiff) {
+		return fmt.Errorf("unknown pr_diff %q (use full, hunks, stat, or none)", c.PRDiff)
+	}
+	if err := validateCommitTypes(c); err != nil {
+		return err
+	}
+	if err := validateTrailerRules(c.Trailers); err != nil {
+		return err
+	}
+	if err := validateTicketConfig(c); err != nil {
+		return err
+	}
+	if err := validateBranchTemplate(c.BranchTemplate); err != nil {
+		return err
+	}
+	if c.Verbosity != "" && !slices.Contains(generate.Verbosities, c.Verbosity) {
+		return fmt.Errorf("unknown verbosity %q (use terse, standard, or detailed)", c.Verbosity)
+	}
+	if c.MergeStrategy != "" && !slices.Contains(mergeStrategies, c.MergeStrategy) {
+		return fmt.Errorf("unknown merge_strategy %q (use merge, squash, or rebase)", c.MergeStrategy)
+	}
+	return nil
+}
+
+// saveConfig saves the configuration to the config file
+func saveConfig(c *Config) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	return config.Save(path, c)
+}
+
+// getEffectiveConfig returns the config with CLI flag overrides applied
+func getEffectiveConfig() *Config {
+	// -p and -m are shorthands; the specific model flags take precedence
+	// over -m/--model
+	providerName := *providerFlag
+	if *pFlag != "" {
+		providerName = *pFlag
+	}
+	modelName := *modelFlag
+	if *mFlag != "" {
+		modelName = *mFlag
+	}
+	return appConfig.WithOverrides(config.Overrides{
+		Provider:     providerName,
+		Model:        modelName,
+		CommitModel:  *commitModelFlag,
+		PRModel:      *prModelFlag,
+		OllamaURL:    *ollamaURLFlag,
+		OpenAIURL:    *openaiURLFlag,
+		OpenAIAPIKey: *openaiAPIKeyFlag,
+		MaxDiffLines: *maxDiffLinesFlag,
+		Candidates:   *candidatesFlag,
+	})
+}
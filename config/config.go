@@ -0,0 +1,416 @@
+// Package config reads and writes gitcat's config file, config.json in
+// Dir, and holds the settings generation and the providers run with. Load
+// fills in the defaults the gitcat command starts from; it leaves checking
+// the fields that only the command's own features use, such as trailers and
+// branch templates, to the command.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/burritocatai/gitcat/provider"
+)
+
+// Defaults for settings the config file leaves out
+const (
+	DefaultAnthropicModel = "claude-sonnet-4-5-20250929"
+	DefaultOllamaModel    = "llama3.2"
+	DefaultOpenAIModel    = "gpt-4o"
+	DefaultOllamaURL      = "http://localhost:11434"
+	DefaultMaxDiffLines   = 1000 // Diffs longer than this are trimmed before prompting
+	DefaultStyleExamples  = 10   // Recent commit messages shown to the model as style examples
+)
+
+// Response budgets, in tokens, unless the config's generation parameters
+// override them
+const (
+	CommitMaxTokens = 1024 // Commit messages
+	PRMaxTokens     = 2048 // PR content and the other prose the PR model writes, and reviews
+	SplitMaxTokens  = 2048 // Split plans
+)
+
+// Config is gitcat's configuration, as stored in config.json. Commands
+// apply their flags on top of it with WithOverrides.
+type Config struct {
+	Provider     string `json:"provider"`                 // "anthropic", "ollama", or "openai"
+	Model        string `json:"model"`                    // Default model name (fallback)
+	CommitModel  string `json:"commit_model,omitempty"`   // Model for commit message generation
+	PRModel      string `json:"pr_model,omitempty"`       // Model for PR description generation
+	OllamaURL    string `json:"ollama_url"`               // Ollama server URL
+	OpenAIURL    string `json:"openai_url,omitempty"`     // OpenAI-compatible endpoint URL
+	OpenAIAPIKey string `json:"openai_api_key,omitempty"` // OpenAI-compatible API key
+
+	AnthropicVersion string   `json:"anthropic_version,omitempty"` // anthropic-version header (default "2023-06-01")
+	AnthropicBeta    []string `json:"anthropic_beta,omitempty"`    // Beta features sent in the anthropic-beta header
+
+	// Extra gitignore-style patterns whose diffs are left out of the prompt,
+	// applied after the built-in lockfile/generated defaults ("!" re-includes)
+	PromptExclude []string `json:"prompt_exclude,omitempty"`
+	// Path globs whose content is never sent; the prompt gets only the
+	// filename and added/removed line counts
+	PromptPathsOnly []string `json:"prompt_paths_only,omitempty"`
+
+	SecretScan string       `json:"secret_scan,omitempty"` // "warn" (default), "block", or "off"
+	Redact     []RedactRule `json:"redact,omitempty"`      // Regex masks applied to the diff before prompting
+
+	ContextTokens int `json:"context_tokens,omitempty"` // Model context size override for diff budgeting
+	MaxDiffLines  int `json:"max_diff_lines,omitempty"` // Line limit for the prompt diff (-1 for none)
+
+	// How much of each file's diff the prompt includes: "full" (default),
+	// "hunks" (hunk headers only), or "stat" (line counts only)
+	DiffDepth          string      `json:"diff_depth,omitempty"`
+	DiffDepthOverrides []DepthRule `json:"diff_depth_overrides,omitempty"` // Per-path depth, last match wins
+
+	// How much of the branch's diff the PR prompt includes besides the
+	// commit log, as a diff depth (default none; per repo: git config
+	// gitcat.prDiff). Each file's diff_depth still applies when shallower.
+	PRDiff string `json:"pr_diff,omitempty"`
+
+	UseEditor bool `json:"use_editor,omitempty"` // Edit messages in $EDITOR by default instead of inline
+
+	CommitTypes     []CommitType `json:"commit_types,omitempty"`      // Custom commit types for the picker
+	CommitTypesMode string       `json:"commit_types_mode,omitempty"` // "extend" (default) or "replace" the built-in types
+
+	Signoff  bool `json:"signoff,omitempty"`   // Always add Signed-off-by (per repo: git config gitcat.signoff true)
+	NoVerify bool `json:"no_verify,omitempty"` // Skip git hooks on commit and push (per repo: git config gitcat.noVerify true)
+
+	TicketPattern   string `json:"ticket_pattern,omitempty"`   // Regex for ticket IDs in branch names (default ABC-123 or #123)
+	TicketPlacement string `json:"ticket_placement,omitempty"` // "footer" (default), "prefix", or "off"
+	TicketTrailer   string `json:"ticket_trailer,omitempty"`   // Footer key for tickets (default "Refs")
+	TicketFooter    string `json:"ticket_footer,omitempty"`    // Go template for the ticket footer, e.g. "Jira: https://jira.example.com/browse/{{.Ticket}}" (default "<ticket_trailer>: {{.Ticket}}")
+
+	Glossary map[string]string `json:"glossary,omitempty"` // Project terms and their meanings, e.g. "TLM": "Telemetry module"
+
+	StyleExamples int `json:"style_examples,omitempty"` // Recent commit messages shown as style examples (default 10, -1 for none)
+
+	ExampleCommits []string `json:"example_commits,omitempty"` // Commits whose messages are shown as the standard to meet (per repo: git config --add gitcat.exampleCommit <sha>)
+
+	Candidates     int    `json:"candidates,omitempty"`      // Commit messages generated at once to pick from (default 1, at most 5)
+	CandidateModel string `json:"candidate_model,omitempty"` // Second model of the same provider that writes every other candidate
+
+	CommitParams *GenerationParams `json:"commit_params,omitempty"` // Generation overrides for commit messages
+	PRParams     *GenerationParams `json:"pr_params,omitempty"`     // Generation overrides for PR content, release notes, explanations, notes, and standups
+	ReviewParams *GenerationParams `json:"review_params,omitempty"` // Generation overrides for reviews
+	SplitParams  *GenerationParams `json:"split_params,omitempty"`  // Generation overrides for split plans
+
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"` // Cap on requests to the provider, for accounts with a low rate limit (default: paced by its rate limit headers only)
+
+	SkipScope bool `json:"skip_scope,omitempty"` // Skip the scope input and write bare "type: description" subjects (per repo: git config gitcat.skipScope true)
+
+	Strict5072 bool `json:"strict_50_72,omitempty"` // 50-character subjects, body wrapped at 72 (per repo: git config gitcat.strict5072 true)
+
+	Verbosity string `json:"verbosity,omitempty"` // How much body generated messages get: "terse", "standard" (default), or "detailed" (per repo: git config gitcat.verbosity)
+
+	Loop bool `json:"loop,omitempty"` // Offer another commit while uncommitted changes remain
+
+	Notes bool `json:"notes,omitempty"` // Attach an AI explanation of each new commit as a git note in refs/notes/gitcat (per repo: git config gitcat.notes true)
+
+	BranchTemplate string `json:"branch_template,omitempty"` // Go template for suggested branch names, e.g. "{{.User}}/{{.Type}}/{{.Ticket}}-{{.Slug}}"
+
+	StageScope string `json:"stage_scope,omitempty"` // Files offered for staging: "repo" (default) or "cwd" (the directory gitcat runs in)
+
+	StashUnstaged string `json:"stash_unstaged,omitempty"` // Set unstaged changes aside while committing: "ask" (default), "always", or "never"
+
+	Language string `json:"language,omitempty"` // TUI language, e.g. "es" (default from GITCAT_LANG or LANG)
+
+	VerifyCommand string `json:"verify_command,omitempty"` // Check run before generating, e.g. "go test ./..." (per repo: git config gitcat.verifyCommand)
+
+	CoAuthors []string      `json:"co_authors,omitempty"` // Frequent pairing partners, "Name <email>"
+	Trailers  []TrailerRule `json:"trailers,omitempty"`   // Trailers added to every commit, values may be templates
+
+	// Added to every PR gitcat creates (per repo: git config --add
+	// gitcat.reviewer, gitcat.assignee, gitcat.label)
+	PRReviewers []string `json:"pr_reviewers,omitempty"`
+	PRAssignees []string `json:"pr_assignees,omitempty"`
+	PRLabels    []string `json:"pr_labels,omitempty"`
+
+	PRPathLabels map[string][]string `json:"pr_path_labels,omitempty"` // Labels added when the PR changes matching paths, e.g. {"docs": ["docs/**", "**/*.md"]} (per repo: git config gitcat.pathLabel "docs=docs/**", or .github/labeler.yml)
+
+	SuggestReviewers bool `json:"suggest_reviewers,omitempty"` // Suggest CODEOWNERS of the changed paths as reviewers (per repo: git config gitcat.suggestReviewers true)
+
+	GitHubAPI bool `json:"github_api,omitempty"` // Call the GitHub API directly even when gh is installed, authenticating with gh's token (per repo: git config gitcat.githubAPI true)
+
+	GiteaHosts []string `json:"gitea_hosts,omitempty"` // Gitea and Forgejo hosts to open PRs on, e.g. "git.example.com" (codeberg.org is built in; per repo: git config gitcat.giteaHost)
+
+	PushRemote     string `json:"push_remote,omitempty"`     // Remote to push branches to (default: git's branch.<name>.pushRemote or remote.pushDefault, the branch's remote, or origin; per repo: git config gitcat.pushRemote)
+	UpstreamRemote string `json:"upstream_remote,omitempty"` // Remote PRs target when the push remote is a fork (default "upstream"; per repo: git config gitcat.upstreamRemote)
+
+	ConventionalPRTitle bool `json:"conventional_pr_title,omitempty"` // Put PR titles in type(scope): subject format (on by default in repos with a semantic PR check; per repo: git config gitcat.conventionalPRTitle true)
+
+	AutoMerge     bool   `json:"auto_merge,omitempty"`     // Enable auto-merge on created PRs (per repo: git config gitcat.autoMerge true)
+	MergeStrategy string `json:"merge_strategy,omitempty"` // Auto-merge strategy: "merge" (default), "squash", or "rebase" (per repo: git config gitcat.mergeStrategy)
+
+	// Logf, when set, receives a debug log of the requests made for this
+	// config. It is not saved.
+	Logf func(format string, args ...any) `json:"-"`
+}
+
+// CommitType is an entry in the commit type picker. The description is
+// shown next to the name and passed to the model as guidance.
+type CommitType struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// GenerationParams overrides how the model generates one kind of output:
+// commit messages, PR content, reviews, or split plans. Unset fields keep gitcat's
+// defaults, and the provider's own for Temperature.
+type GenerationParams struct {
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // Response budget (not sent to Ollama)
+	Temperature *float64 `json:"temperature,omitempty"` // Sampling temperature, e.g. 0.2 for terse subjects
+	Stop        []string `json:"stop,omitempty"`        // Stop sequences that end the response
+}
+
+// TrailerRule is a trailer added to every commit. Value is a Go template
+// over the commit's branch, ticket, type, scope, author, date, and
+// Change-Id, e.g. "{{.Branch}}"; trailers that render empty are skipped.
+type TrailerRule struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// RedactRule masks text matching Pattern in the diff before it is sent to
+// the model. Replace may reference capture groups ($1); empty means
+// "[REDACTED]".
+type RedactRule struct {
+	Pattern string `json:"pattern"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// DepthRule overrides the diff depth for paths matching a gitignore-style glob
+type DepthRule struct {
+	Path  string `json:"path"`
+	Depth string `json:"depth"`
+}
+
+// Dir returns the directory holding gitcat's config.json, prompt
+// templates, and locales: ~/.config/gitcat, or %AppData%\gitcat on Windows.
+// Windows setups from before gitcat used %AppData% keep their
+// ~/.config/gitcat as long as there is no %AppData%\gitcat.
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	legacyDir := filepath.Join(homeDir, ".config", "gitcat")
+	if runtime.GOOS != "windows" {
+		return legacyDir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	dir = filepath.Join(dir, "gitcat")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if info, err := os.Stat(legacyDir); err == nil && info.IsDir() {
+			return legacyDir, nil
+		}
+	}
+	return dir, nil
+}
+
+// Path returns the path to the config file
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Default returns the config gitcat runs with before one is saved
+func Default() *Config {
+	return &Config{
+		Provider:      provider.Anthropic,
+		Model:         DefaultAnthropicModel,
+		OllamaURL:     DefaultOllamaURL,
+		MaxDiffLines:  DefaultMaxDiffLines,
+		StyleExamples: DefaultStyleExamples,
+	}
+}
+
+// DefaultModel returns the model used with providerName when none is
+// configured
+func DefaultModel(providerName string) string {
+	switch providerName {
+	case provider.Ollama:
+		return DefaultOllamaModel
+	case provider.OpenAI:
+		return DefaultOpenAIModel
+	default:
+		return DefaultAnthropicModel
+	}
+}
+
+// Load reads the config file at path and fills in defaults for missing
+// values. A file that doesn't exist gives the Default config.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	// Set defaults for missing values
+	if config.Provider == "" {
+		config.Provider = provider.Anthropic
+	}
+	if config.Model == "" {
+		config.Model = DefaultModel(config.Provider)
+	}
+	if config.OllamaURL == "" {
+		config.OllamaURL = DefaultOllamaURL
+	}
+	if config.MaxDiffLines == 0 {
+		config.MaxDiffLines = DefaultMaxDiffLines
+	}
+	if config.StyleExamples == 0 {
+		config.StyleExamples = DefaultStyleExamples
+	}
+	return &config, nil
+}
+
+// Save writes config to the file at path, creating its directory
+func Save(path string, config *Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// Overrides are settings given for a single run, such as command-line
+// flags. Empty fields keep the config's value.
+type Overrides struct {
+	Provider     string
+	Model        string // Default model for both commits and PRs
+	CommitModel  string // Takes precedence over Model for commits
+	PRModel      string // Takes precedence over Model for PRs
+	OllamaURL    string
+	OpenAIURL    string
+	OpenAIAPIKey string
+	MaxDiffLines int
+	Candidates   int
+}
+
+// WithOverrides returns a copy of c with o applied
+func (c *Config) WithOverrides(o Overrides) *Config {
+	config := *c
+	if o.Provider != "" {
+		config.Provider = o.Provider
+	}
+	if o.Model != "" {
+		config.Model = o.Model
+	}
+	if o.CommitModel != "" {
+		config.CommitModel = o.CommitModel
+	}
+	if o.PRModel != "" {
+		config.PRModel = o.PRModel
+	}
+	if o.OllamaURL != "" {
+		config.OllamaURL = o.OllamaURL
+	}
+	if o.OpenAIURL != "" {
+		config.OpenAIURL = o.OpenAIURL
+	}
+	if o.OpenAIAPIKey != "" {
+		config.OpenAIAPIKey = o.OpenAIAPIKey
+	}
+	if o.MaxDiffLines != 0 {
+		config.MaxDiffLines = o.MaxDiffLines
+	}
+	if o.Candidates != 0 {
+		config.Candidates = o.Candidates
+	}
+	return &config
+}
+
+// GetCommitModel returns the model to use for commit message generation.
+// Falls back to the default Model if CommitModel is not set.
+func (c *Config) GetCommitModel() string {
+	if c.CommitModel != "" {
+		return c.CommitModel
+	}
+	return c.Model
+}
+
+// GetPRModel returns the model to use for PR description generation.
+// Falls back to the default Model if PRModel is not set.
+func (c *Config) GetPRModel() string {
+	if c.PRModel != "" {
+		return c.PRModel
+	}
+	return c.Model
+}
+
+// ProviderSettings returns what the provider package needs to send
+// requests for this config
+func (c *Config) ProviderSettings() provider.Settings {
+	return provider.Settings{
+		Provider:          c.Provider,
+		AnthropicVersion:  c.AnthropicVersion,
+		AnthropicBeta:     c.AnthropicBeta,
+		OllamaURL:         c.OllamaURL,
+		OpenAIURL:         c.OpenAIURL,
+		OpenAIAPIKey:      c.OpenAIAPIKey,
+		RequestsPerMinute: c.RequestsPerMinute,
+		Logf:              c.Logf,
+	}
+}
+
+// WithDefault returns the parameters for a request whose response budget is
+// maxTokens unless p overrides it. p may be nil.
+func (p *GenerationParams) WithDefault(maxTokens int) GenerationParams {
+	params := GenerationParams{MaxTokens: maxTokens}
+	if p == nil {
+		return params
+	}
+	if p.MaxTokens > 0 {
+		params.MaxTokens = p.MaxTokens
+	}
+	params.Temperature = p.Temperature
+	params.Stop = p.Stop
+	return params
+}
+
+// GetCommitParams returns the parameters for commit messages
+func (c *Config) GetCommitParams() GenerationParams {
+	return c.CommitParams.WithDefault(CommitMaxTokens)
+}
+
+// GetPRParams returns the parameters for PR content and the other prose the
+// PR model writes: release notes, explanations, commit notes, and standups
+func (c *Config) GetPRParams() GenerationParams {
+	return c.PRParams.WithDefault(PRMaxTokens)
+}
+
+// GetReviewParams returns the parameters for reviews of the staged changes
+func (c *Config) GetReviewParams() GenerationParams {
+	return c.ReviewParams.WithDefault(PRMaxTokens)
+}
+
+// GetSplitParams returns the parameters for plans that split the staged
+// changes into commits
+func (c *Config) GetSplitParams() GenerationParams {
+	return c.SplitParams.WithDefault(SplitMaxTokens)
+}
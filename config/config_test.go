@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string // "" for no file
+		want    *Config
+		wantErr bool
+	}{
+		{"no file", "", Default(), false},
+		{
+			"defaults filled in",
+			`{"provider": "ollama", "commit_model": "qwen2.5-coder"}`,
+			&Config{Provider: "ollama", Model: DefaultOllamaModel, CommitModel: "qwen2.5-coder", OllamaURL: DefaultOllamaURL, MaxDiffLines: DefaultMaxDiffLines, StyleExamples: DefaultStyleExamples},
+			false,
+		},
+		{
+			"values kept",
+			`{"model": "gpt-4.1", "provider": "openai", "ollama_url": "http://gpu:11434", "max_diff_lines": -1, "style_examples": -1}`,
+			&Config{Provider: "openai", Model: "gpt-4.1", OllamaURL: "http://gpu:11434", MaxDiffLines: -1, StyleExamples: -1},
+			false,
+		},
+		{"invalid JSON", `{"provider": `, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitcat", "config.json")
+	config := Default()
+	config.CommitTypes = []CommitType{{Name: "wip", Description: "Work in progress"}}
+	config.Logf = t.Logf
+	if err := Save(path, config); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	config.Logf = nil // Not saved
+	if !reflect.DeepEqual(got, config) {
+		t.Errorf("Load() after Save() = %+v, want %+v", got, config)
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	base := &Config{Provider: "anthropic", Model: "claude-haiku", PRModel: "claude-opus", MaxDiffLines: 1000}
+	got := base.WithOverrides(Overrides{Provider: "ollama", Model: "llama3.2", CommitModel: "qwen2.5-coder", MaxDiffLines: -1})
+
+	if got.Provider != "ollama" || got.MaxDiffLines != -1 {
+		t.Errorf("WithOverrides() = provider %q, max diff lines %d, want ollama, -1", got.Provider, got.MaxDiffLines)
+	}
+	if got.GetCommitModel() != "qwen2.5-coder" || got.GetPRModel() != "claude-opus" {
+		t.Errorf("WithOverrides() models = %q, %q, want qwen2.5-coder, claude-opus", got.GetCommitModel(), got.GetPRModel())
+	}
+	if base.Provider != "anthropic" || base.Model != "claude-haiku" {
+		t.Errorf("WithOverrides() changed the config it was called on: %+v", base)
+	}
+}
+
+func TestGenerationParams(t *testing.T) {
+	temperature := 0.2
+	config := &Config{
+		CommitParams: &GenerationParams{Temperature: &temperature, Stop: []string{"\n\n\n"}},
+		ReviewParams: &GenerationParams{MaxTokens: 4096},
+	}
+	tests := []struct {
+		name string
+		got  GenerationParams
+		want GenerationParams
+	}{
+		{"commit", config.GetCommitParams(), GenerationParams{MaxTokens: CommitMaxTokens, Temperature: &temperature, Stop: []string{"\n\n\n"}}},
+		{"PR", config.GetPRParams(), GenerationParams{MaxTokens: PRMaxTokens}},
+		{"review", config.GetReviewParams(), GenerationParams{MaxTokens: 4096}},
+		{"split", config.GetSplitParams(), GenerationParams{MaxTokens: SplitMaxTokens}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("params = %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+}
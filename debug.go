@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// debugLogger is nil unless --debug or GITCAT_DEBUG enabled logging
+var debugLogger *log.Logger
+
+// getStateDir returns the directory for gitcat state files (logs, drafts).
+// Honors XDG_STATE_HOME and falls back to ~/.local/state/gitcat, or
+// %LocalAppData%\gitcat on Windows.
+func getStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "gitcat"), nil
+	}
+	if onWindows {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get local app data directory: %w", err)
+		}
+		return filepath.Join(dir, "gitcat"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "state", "gitcat"), nil
+}
+
+// getDebugLogPath returns the path to the debug log file
+func getDebugLogPath() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "debug.log"), nil
+}
+
+// debugEnabled reports whether debug logging was requested via flag or env
+func debugEnabled() bool {
+	if *debugFlag {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("GITCAT_DEBUG")) {
+	case "", "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+// initDebugLog opens the debug log file in append mode when debugging is enabled
+func initDebugLog() error {
+	if !debugEnabled() {
+		return nil
+	}
+
+	logPath, err := getDebugLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open debug log: %w", err)
+	}
+
+	debugLogger = log.New(f, "", log.LstdFlags|log.Lmicroseconds)
+	debugf("gitcat %s started: %s", Version, strings.Join(redactArgs(os.Args), " "))
+	return nil
+}
+
+// redactArgs returns args with the values of secret flags, such as
+// --openai-api-key, replaced so that they never reach the log
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 1; i < len(redacted); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(redacted[i], "-"), "=")
+		if !strings.HasPrefix(redacted[i], "-") || !secretFlag(name) {
+			continue
+		}
+		if hasValue {
+			if value != "" {
+				redacted[i] = redacted[i][:len(redacted[i])-len(value)] + "[REDACTED]"
+			}
+		} else if i+1 < len(redacted) {
+			i++
+			redacted[i] = "[REDACTED]"
+		}
+	}
+	return redacted
+}
+
+// secretFlag reports whether the flag called name takes a credential
+func secretFlag(name string) bool {
+	return strings.HasSuffix(name, "api-key") || strings.HasSuffix(name, "token")
+}
+
+// debugf writes a line to the debug log if logging is enabled
+func debugf(format string, args ...any) {
+	if debugLogger == nil {
+		return
+	}
+	debugLogger.Printf(format, args...)
+}
+
+// runCommand runs cmd, returning its combined output, and logs the command,
+// exit status, and duration to the debug log
+func runCommand(cmd *exec.Cmd) ([]byte, error) {
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		debugf("exec %q failed after %s: %v\n%s", cmd.Args, time.Since(start), err, strings.TrimSpace(string(output)))
+	} else {
+		debugf("exec %q ok in %s", cmd.Args, time.Since(start))
+	}
+	return output, err
+}
+
+// doRequest sends req and logs its method, URL, response status, and
+// duration to the debug log. Headers are never logged since they carry
+// tokens.
+func doRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	debugf("http %s %s", req.Method, req.URL.Redacted())
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		debugf("http %s %s failed after %s: %v", req.Method, req.URL.Redacted(), time.Since(start), err)
+		return nil, err
+	}
+	debugf("http %s %s status=%d in %s", req.Method, req.URL.Redacted(), resp.StatusCode, time.Since(start))
+	return resp, nil
+}
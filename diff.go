@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Diff depths control how much of each file's diff goes into the prompt
+const (
+	diffDepthFull  = "full"  // Complete hunks
+	diffDepthHunks = "hunks" // Hunk headers only
+	diffDepthStat  = "stat"  // Filename and line counts only
+)
+
+// validDiffDepth reports whether depth is a known diff depth
+func validDiffDepth(depth string) bool {
+	switch depth {
+	case diffDepthFull, diffDepthHunks, diffDepthStat:
+		return true
+	}
+	return false
+}
+
+// diffDepthFor returns the depth for path: the last matching override, or
+// the configured default
+func diffDepthFor(config *Config, path string) string {
+	depth := config.DiffDepth
+	for _, rule := range config.DiffDepthOverrides {
+		if parseIgnorePatterns([]string{rule.Path}).match(path) {
+			depth = rule.Depth
+		}
+	}
+	if depth == "" {
+		return diffDepthFull
+	}
+	return depth
+}
+
+// fileDiff is one file's section of a unified git diff
+type fileDiff struct {
+	path   string // Destination path (b/ side)
+	header string // "diff --git" line up to the first hunk
+	hunks  string // Hunk content, empty for binary or mode-only changes
+}
+
+// text returns the full diff section for the file
+func (f fileDiff) text() string {
+	return f.header + f.hunks
+}
+
+// splitDiff breaks a unified diff into per-file sections
+func splitDiff(diff string) []fileDiff {
+	var files []fileDiff
+	var current *fileDiff
+	inHunks := false
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "diff --git ") {
+			if current != nil {
+				files = append(files, *current)
+			}
+			current = &fileDiff{path: diffHeaderPath(line)}
+			inHunks = false
+		}
+		if current == nil {
+			continue
+		}
+		if strings.HasPrefix(line, "@@") {
+			inHunks = true
+		}
+		if inHunks {
+			current.hunks += line
+		} else {
+			current.header += line
+			// Prefer the +++ path, which handles spaces in names unambiguously
+			if strings.HasPrefix(line, "+++ ") && !strings.HasPrefix(line, "+++ /dev/null") {
+				current.path = unquoteDiffPath(strings.TrimPrefix(line, "+++ "), "b/")
+			}
+		}
+	}
+	if current != nil {
+		files = append(files, *current)
+	}
+	return files
+}
+
+// diffHeaderPath extracts the destination path from a "diff --git a/x b/x" line
+func diffHeaderPath(line string) string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "diff --git "), "\n")
+	if idx := strings.LastIndex(line, " \"b/"); idx >= 0 {
+		return unquoteDiffPath(line[idx+1:], "b/")
+	}
+	if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+		return line[idx+3:]
+	}
+	return line
+}
+
+// unquoteDiffPath strips git's C-style quoting, the trailing tab git adds
+// after names containing spaces, and the a/ or b/ prefix
+func unquoteDiffPath(p, prefix string) string {
+	p = strings.TrimRight(p, "\t\n")
+	if strings.HasPrefix(p, "\"") {
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			p = unquoted
+		}
+	}
+	return strings.TrimPrefix(p, prefix)
+}
+
+// joinDiff reassembles per-file sections into a single diff
+func joinDiff(files []fileDiff) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(f.text())
+	}
+	return b.String()
+}
+
+// withholdContent replaces a file's diff with a note naming the path and
+// its change stats, so the model knows the file changed without seeing its
+// content
+func withholdContent(f fileDiff, reason string) fileDiff {
+	added, removed := f.lineStats()
+	return fileDiff{
+		path:   f.path,
+		header: fmt.Sprintf("diff --git a/%s b/%s\n# content withheld from prompt (%s): +%d -%d lines\n", f.path, f.path, reason, added, removed),
+	}
+}
+
+// describeHeader rewrites the git metadata in a file's diff header (index
+// lines, rename and mode lines, "Binary files differ") as plain statements
+// such as "# renamed a.go -> b.go" or "# binary file added, 120 KB", which
+// models read far more reliably than raw git output
+func describeHeader(f fileDiff) fileDiff {
+	var kept, notes []string
+	var oldMode, newMode, renameFrom, renameTo, similarity, oldBlob, newBlob string
+	var added, deleted, copied, binary bool
+
+	for _, line := range strings.SplitAfter(f.header, "\n") {
+		trimmed := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(trimmed, "index "):
+			blobs, _, _ := strings.Cut(strings.TrimPrefix(trimmed, "index "), " ")
+			oldBlob, newBlob, _ = strings.Cut(blobs, "..")
+		case strings.HasPrefix(trimmed, "old mode "):
+			oldMode = strings.TrimPrefix(trimmed, "old mode ")
+		case strings.HasPrefix(trimmed, "new mode "):
+			newMode = strings.TrimPrefix(trimmed, "new mode ")
+		case strings.HasPrefix(trimmed, "similarity index "):
+			similarity = strings.TrimPrefix(trimmed, "similarity index ")
+		case strings.HasPrefix(trimmed, "rename from "), strings.HasPrefix(trimmed, "copy from "):
+			copied = strings.HasPrefix(trimmed, "copy ")
+			_, from, _ := strings.Cut(trimmed, " from ")
+			renameFrom = unquoteDiffPath(from, "")
+		case strings.HasPrefix(trimmed, "rename to "), strings.HasPrefix(trimmed, "copy to "):
+			_, to, _ := strings.Cut(trimmed, " to ")
+			renameTo = unquoteDiffPath(to, "")
+		case strings.HasPrefix(trimmed, "Binary files "), trimmed == "GIT binary patch":
+			binary = true
+		default:
+			if strings.HasPrefix(trimmed, "new file mode ") {
+				added = true
+			} else if strings.HasPrefix(trimmed, "deleted file mode ") {
+				deleted = true
+			}
+			kept = append(kept, line)
+		}
+	}
+
+	if renameFrom != "" {
+		verb := "renamed"
+		if copied {
+			verb = "copied"
+		}
+		note := fmt.Sprintf("# %s %s -> %s", verb, renameFrom, renameTo)
+		if similarity != "" {
+			note += fmt.Sprintf(" (%s similar)", similarity)
+		}
+		notes = append(notes, note)
+	}
+	if oldMode != "" && newMode != "" {
+		note := fmt.Sprintf("# mode changed %s -> %s", oldMode, newMode)
+		if newMode == "100755" {
+			note += " (made executable)"
+		} else if oldMode == "100755" {
+			note += " (no longer executable)"
+		}
+		notes = append(notes, note)
+	}
+	if binary {
+		switch {
+		case added:
+			notes = append(notes, "# binary file added"+blobSizeSuffix(newBlob))
+		case deleted:
+			notes = append(notes, "# binary file deleted"+blobSizeSuffix(oldBlob))
+		default:
+			note := "# binary file modified"
+			if oldSize, newSize := blobSize(oldBlob), blobSize(newBlob); oldSize >= 0 && newSize >= 0 {
+				note += fmt.Sprintf(", %s -> %s", formatSize(oldSize), formatSize(newSize))
+			}
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) == 0 {
+		return f
+	}
+
+	// Notes go right after the "diff --git" line, ahead of any ---/+++ lines
+	header := kept[0] + strings.Join(notes, "\n") + "\n" + strings.Join(kept[1:], "")
+	return fileDiff{path: f.path, header: header, hunks: f.hunks}
+}
+
+// blobSizeSuffix formats a blob's size as ", 120 KB", or "" if unknown
+func blobSizeSuffix(blob string) string {
+	if size := blobSize(blob); size >= 0 {
+		return ", " + formatSize(size)
+	}
+	return ""
+}
+
+// blobSize returns the size in bytes of a (possibly abbreviated) blob
+// object, or -1 if it can't be determined
+func blobSize(blob string) int64 {
+	if blob == "" || strings.Trim(blob, "0") == "" {
+		return -1
+	}
+	output, err := runCommand(exec.Command("git", "cat-file", "-s", blob))
+	if err != nil {
+		return -1
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// formatSize renders a byte count as B, KB, or MB
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// lineStats counts the lines the file's hunks add and remove
+func (f fileDiff) lineStats() (added, removed int) {
+	for _, line := range strings.Split(f.hunks, "\n") {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			added++
+		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// promptDiff returns the staged diff as it should be sent to the model,
+// with content of user-excluded, .gitcatignore'd, sensitive, and
+// lockfile/generated files withheld, git metadata described in plain
+// words, submodule bumps summarized by their commits, each file reduced to
+// its configured depth, and configured redactions applied
+func (m model) promptDiff() string {
+	config := getEffectiveConfig()
+	return m.filterPromptDiff(m.diff, func(path string) string { return diffDepthFor(config, path) })
+}
+
+// promptFilters returns a model with only the configured prompt filters set,
+// for sending the diffs of existing commits outside the commit flow
+func promptFilters(config *Config) model {
+	m := model{
+		promptIgnore:      loadGitcatIgnore(),
+		promptAutoExclude: autoExcludeMatcher(config),
+		promptPathsOnly:   parseIgnorePatterns(config.PromptPathsOnly),
+	}
+	// Patterns were validated when the config was loaded
+	m.promptRedactions, _ = compileRedactions(config.Redact)
+	return m
+}
+
+// filterPromptDiff prepares diff for the prompt as promptDiff describes,
+// reducing each file to the depth depthFor gives for its path
+func (m model) filterPromptDiff(diff string, depthFor func(path string) string) string {
+	files := splitDiff(diff)
+	for i, f := range files {
+		f = describeHeader(describeSubmodule(f))
+		files[i] = f
+		if _, ok := m.promptExcluded[f.path]; ok {
+			files[i] = withholdContent(f, "excluded by user")
+		} else if m.promptIgnore.match(f.path) {
+			files[i] = withholdContent(f, "matched "+gitcatIgnoreFile)
+		} else if m.promptPathsOnly.match(f.path) {
+			files[i] = withholdContent(f, "sensitive path")
+		} else if m.promptAutoExclude.match(f.path) {
+			files[i] = withholdContent(f, "lockfile or generated file")
+		} else {
+			switch depthFor(f.path) {
+			case diffDepthHunks:
+				files[i].hunks = capHunkLines(f.hunks, 0)
+			case diffDepthStat:
+				files[i] = withholdContent(f, "stat only")
+			}
+		}
+	}
+	return applyRedactions(joinDiff(files), m.promptRedactions)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// draft is a generated or edited commit message saved until it is committed,
+// so it survives a crash or an accidental quit
+type draft struct {
+	Repo    string    `json:"repo"`
+	Branch  string    `json:"branch"`
+	Message string    `json:"message"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// draftStore keeps the drafts of one repository. It is located once, when
+// the run starts, so that saving a draft doesn't have to ask git again.
+// The zero value keeps no drafts.
+type draftStore struct {
+	dir  string // The drafts directory under the state directory
+	repo string // The repository root
+}
+
+// newDraftStore locates the drafts of the current repository
+func newDraftStore() (draftStore, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return draftStore{}, err
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return draftStore{}, err
+	}
+	return draftStore{dir: filepath.Join(stateDir, "drafts"), repo: root}, nil
+}
+
+// path returns the draft file for branch
+func (s draftStore) path(branch string) string {
+	sum := sha256.Sum256([]byte(s.repo + "\x00" + branch))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// load returns the saved draft for branch, or nil if there is none
+func (s draftStore) load(branch string) *draft {
+	if s.dir == "" {
+		return nil
+	}
+	draftPath := s.path(branch)
+	data, err := os.ReadFile(draftPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			debugf("failed to read draft: %v", err)
+		}
+		return nil
+	}
+	var d draft
+	if err := json.Unmarshal(data, &d); err != nil || d.Message == "" {
+		debugf("ignoring unreadable draft %s", draftPath)
+		return nil
+	}
+	return &d
+}
+
+// save writes message as the draft for branch. Failures are logged rather
+// than surfaced, since drafts are a convenience.
+func (s draftStore) save(branch, message string) {
+	if s.dir == "" || message == "" {
+		return
+	}
+	data, err := json.MarshalIndent(draft{
+		Repo:    s.repo,
+		Branch:  branch,
+		Message: message,
+		SavedAt: time.Now(),
+	}, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		debugf("failed to create draft directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(s.path(branch), data, 0600); err != nil {
+		debugf("failed to save draft: %v", err)
+	}
+}
+
+// clear removes the draft for branch once its message is committed
+func (s draftStore) clear(branch string) {
+	if s.dir == "" {
+		return
+	}
+	if err := os.Remove(s.path(branch)); err != nil && !os.IsNotExist(err) {
+		debugf("failed to remove draft: %v", err)
+	}
+}
+
+// draftAge describes how long ago a draft was saved, e.g. "5m ago"
+func draftAge(d *draft) string {
+	age := time.Since(d.SavedAt)
+	switch {
+	case age < time.Minute:
+		return tr("just now")
+	case age < time.Hour:
+		return tr("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return tr("%dh ago", int(age.Hours()))
+	}
+	return tr("%dd ago", int(age.Hours()/24))
+}
+
+// keepDraft saves the message being written as the branch's draft. A
+// reworded commit already has its old message, and an unfinished revert is
+// undone on exit, so neither keeps a draft.
+func (m model) keepDraft() {
+	if m.reword == nil && m.revert == nil {
+		m.drafts.save(m.currentBranch, m.generatedMsg)
+	}
+}
+
+// dropDraft removes the branch's draft once its message is committed
+func (m model) dropDraft() {
+	if m.reword == nil && m.revert == nil {
+		m.drafts.clear(m.currentBranch)
+	}
+}
+
+// typingMessage reports whether the message is being typed in, and so is
+// only saved as a draft when its screen is left rather than on every key
+func (m model) typingMessage() bool {
+	return m.phase == "edit" || m.phase == "manual_input"
+}
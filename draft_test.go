@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDiscardDraftKeepsTypeFlags(t *testing.T) {
+	types := []CommitType{{Name: "feat"}, {Name: "fix"}}
+	tests := []struct {
+		name      string
+		typeName  string
+		autoType  bool
+		wantPhase string
+		wantType  int
+	}{
+		{"--type", "fix", false, "scope", 1},
+		{"--auto-type", "", true, "scope", len(types)},
+		{"no flags", "", false, "type", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, &Config{})
+			setFlag(t, typeFlag, tt.typeName)
+			setFlag(t, autoTypeFlag, tt.autoType)
+			setFlag(t, yesFlag, true)
+
+			m := model{
+				phase:       "resume_draft",
+				choices:     []string{"Resume saved message", "Discard it and start over"},
+				commitTypes: types,
+				savedDraft:  &draft{Message: "fix: old attempt"},
+			}
+			// --yes discards the draft, as a scripted run starts over
+			m, ok := m.scriptAnswer()
+			if !ok || m.cursor != 1 {
+				t.Fatalf("scriptAnswer() = cursor %d, %v, want the discard choice", m.cursor, ok)
+			}
+			next, _ := m.update(tea.KeyMsg{Type: tea.KeyEnter})
+			got := next.(model)
+			if got.phase != tt.wantPhase || got.typeSelected != tt.wantType {
+				t.Errorf("after discarding: phase %q, type %d, want %q, %d", got.phase, got.typeSelected, tt.wantPhase, tt.wantType)
+			}
+			if got.savedDraft != nil {
+				t.Error("the discarded draft is still loaded")
+			}
+		})
+	}
+}
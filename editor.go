@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorScissors marks the start of the help text appended to files opened
+// in the editor. As with git's --cleanup=scissors, everything from this line
+// down is dropped, so markdown headings above it survive.
+const editorScissors = "# ------------------------ >8 ------------------------"
+
+// editorHelp returns the help text appended below the scissors line
+func editorHelp(target string) string {
+	help := "\n" + editorScissors + "\n# Do not modify or remove the line above; everything below it is ignored.\n# Save an empty message to keep the previous text.\n"
+	switch target {
+	case editTargetPR:
+		help += "# The first line is the PR title; the body follows after a blank line.\n"
+	case editTargetPRTitle:
+		help += "# Only the first line is used as the PR title.\n"
+	}
+	return help
+}
+
+// editorFinishedMsg carries the edited text back from $EDITOR. target
+// identifies what was being edited.
+type editorFinishedMsg struct {
+	target  string
+	content string
+	err     error
+}
+
+// Editor targets
+const (
+	editTargetCommit  = "commit"
+	editTargetPR      = "pr"       // Title and body together
+	editTargetPRTitle = "pr_title" // Title alone, from the title input
+	editTargetPRBody  = "pr_body"  // Body alone, from the body input
+)
+
+// getEditor returns the editor git would use (GIT_EDITOR, core.editor,
+// VISUAL, EDITOR), falling back to vi, or notepad on Windows
+func getEditor() string {
+	output, err := runCommand(exec.Command("git", "var", "GIT_EDITOR"))
+	if editor := strings.TrimSpace(string(output)); err == nil && editor != "" {
+		return editor
+	}
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			return editor
+		}
+	}
+	return defaultEditor()
+}
+
+// openEditor writes content to a temporary file, suspends the TUI while the
+// user edits it, and reports the cleaned-up result as an editorFinishedMsg
+func openEditor(target, content string) tea.Cmd {
+	f, err := os.CreateTemp("", "gitcat-EDITMSG-*")
+	if err != nil {
+		return func() tea.Msg {
+			return editorFinishedMsg{target: target, err: fmt.Errorf("failed to create temp file: %w", err)}
+		}
+	}
+	_, err = f.WriteString(content + "\n" + editorHelp(target))
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return func() tea.Msg {
+			return editorFinishedMsg{target: target, err: fmt.Errorf("failed to write temp file: %w", err)}
+		}
+	}
+
+	// The editor setting may include arguments (e.g. "code --wait"), so let
+	// the shell split it the way git does
+	editor := getEditor()
+	cmd := shellCommand(editor+` "$@"`, f.Name())
+	debugf("exec: %s %s", editor, f.Name())
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(f.Name())
+		if err != nil {
+			return editorFinishedMsg{target: target, err: fmt.Errorf("editor %q failed: %w", editor, err)}
+		}
+		data, err := os.ReadFile(f.Name())
+		if err != nil {
+			return editorFinishedMsg{target: target, err: fmt.Errorf("failed to read edited file: %w", err)}
+		}
+		return editorFinishedMsg{target: target, content: cutEditorHelp(normalizeNewlines(string(data)))}
+	})
+}
+
+// splitTitleBody splits edited PR text into the title (first line) and body
+func splitTitleBody(text string) (title, body string) {
+	title, body, _ = strings.Cut(text, "\n")
+	return strings.TrimSpace(title), strings.Trim(body, "\n")
+}
+
+// cutEditorHelp drops the help text below the scissors line, trailing
+// whitespace, and surrounding blank lines
+func cutEditorHelp(text string) string {
+	if i := strings.Index(text, editorScissors); i >= 0 {
+		text = text[:i]
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+
+	"github.com/burritocatai/gitcat/generate"
+)
+
+// explainRange resolves the argument of gitcat explain to the base and head
+// of the changes to explain: a commit (explained against its parent) or a
+// range such as main..feature or HEAD~3.. (an empty side means HEAD)
+func explainRange(spec string) (base, head string, err error) {
+	verify := func(rev string) error {
+		if _, err := runCommand(exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")); err != nil {
+			return fmt.Errorf("unknown revision %s", rev)
+		}
+		return nil
+	}
+	if from, to, isRange := strings.Cut(strings.Replace(spec, "...", "..", 1), ".."); isRange {
+		if from == "" {
+			from = "HEAD"
+		}
+		if to == "" {
+			to = "HEAD"
+		}
+		if err := verify(from); err != nil {
+			return "", "", err
+		}
+		return from, to, verify(to)
+	}
+	if err := verify(spec); err != nil {
+		return "", "", err
+	}
+	if verify(spec+"^") != nil {
+		return "", "", fmt.Errorf("%s is the first commit, so there is nothing to compare it with", spec)
+	}
+	return spec + "^", spec, nil
+}
+
+// explainPrompt asks for a plain-language account of the changes between
+// base and head, from their commit messages and as much of the diff as
+// fits the model's context. A diff the secret scan flags is left out with
+// a warning, or refused when secret_scan is "block".
+func explainPrompt(base, head string, config *Config) (string, error) {
+	gitLog, err := getGitLog(base, head)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(gitLog) == "" {
+		return "", fmt.Errorf("no commits in %s..%s", base, head)
+	}
+	budget := diffTokenBudget(config, config.GetPRParams().MaxTokens) - estimateTokens(gitLog)
+	diff, err := promptFilters(config).prPromptDiff(base, head, diffDepthFull, budget, config)
+	var secrets diffSecretsError
+	switch {
+	case errors.As(err, &secrets) && config.SecretScan != secretScanBlock:
+		fmt.Fprintf(os.Stderr, "Warning: %v, so only the commit messages are sent\n", err)
+		diff = "(left out)"
+	case err != nil:
+		return "", err
+	case diff == "":
+		diff = "(too large to include)"
+	}
+
+	return fmt.Sprintf(`You are explaining changes in a git repository to a developer who is new to this part of the code. Based on the following commit messages and diff, explain in plain language what changed and why it matters.
+
+%s Where the reason for a change isn't given, say so rather than guessing.
+
+Commit messages:
+%s
+
+Diff:
+%s
+%s
+Write the explanation in Markdown with these sections:
+## Summary
+One or two sentences on what the changes do.
+## What changed
+Bullets covering the notable changes, naming the files, functions, or settings involved.
+## Why it matters
+How the changes affect users, callers, or the rest of the code, including anything that behaves differently now or could break.
+
+Respond with ONLY the explanation, with no preamble or code fences around it.`, groundingRule("commit messages and diff"), gitLog, diff, generate.GlossaryPrompt(config.Glossary)), nil
+}
+
+// renderMarkdown styles the headings and bullets of a model's Markdown
+// answer for the terminal and wraps it to width (none when 0)
+func renderMarkdown(text string, width int) string {
+	headingStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	wrap := func(style lipgloss.Style, s string, indent int) string {
+		if width > indent {
+			style = style.Width(width - indent)
+		}
+		return style.Render(s)
+	}
+
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			out = append(out, headingStyle.Render(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			bullet := strings.Repeat(" ", indent) + "• "
+			body := wrap(lipgloss.NewStyle(), trimmed[2:], lipgloss.Width(bullet))
+			body = strings.ReplaceAll(body, "\n", "\n"+strings.Repeat(" ", lipgloss.Width(bullet)))
+			out = append(out, bullet+body)
+		default:
+			out = append(out, wrap(lipgloss.NewStyle(), line, 0))
+		}
+	}
+	// Wrapping pads lines to the full width
+	lines := strings.Split(strings.Join(out, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runExplain handles gitcat explain [<commit|range>]: it prints an
+// explanation of what the commit or range changed, HEAD by default
+func runExplain(args []string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat explain [OPTIONS] [<commit|range>]")
+		os.Exit(exitError)
+	}
+	if *offlineFlag {
+		fmt.Fprintln(os.Stderr, "Error: gitcat explain needs an AI provider and can't run with --offline")
+		os.Exit(exitError)
+	}
+	spec := "HEAD"
+	if len(args) == 1 {
+		spec = args[0]
+	}
+	base, head, err := explainRange(spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot explain %s: %v\n", spec, err)
+		os.Exit(exitGitFailure)
+	}
+
+	config := getEffectiveConfig()
+	config.Model = config.GetPRModel()
+	prompt, err := explainPrompt(base, head, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot explain %s: %v\n", spec, err)
+		if errors.As(err, new(diffSecretsError)) {
+			os.Exit(exitError)
+		}
+		os.Exit(exitGitFailure)
+	}
+
+	fmt.Fprintf(os.Stderr, "Explaining %s...\n", spec)
+	msg := generateMsg(context.Background(), config, prompt, config.GetPRParams(), true)
+	explanation, ok := msg.(prContentMsg)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", msg)
+		os.Exit(exitAPIFailure)
+	}
+
+	width := 0
+	if term.IsTerminal(os.Stdout.Fd()) {
+		if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil {
+			width = w
+		}
+	}
+	fmt.Println(renderMarkdown(string(explanation), width))
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// prTarget is where the branch's PR is opened: the repository that
+// receives the PR, and the head naming the branch in it, as gh pr create
+// --repo and --head take them
+type prTarget struct {
+	repo  string     // "owner/name", "" to leave it to gh
+	head  string     // "branch", or "fork-owner:branch" from a fork
+	gitea *giteaHost // The Gitea or Forgejo instance, nil for GitHub
+}
+
+// remoteURL returns the fetch URL of a remote, with url.<base>.insteadOf
+// rewrites applied
+func remoteURL(remote string) (string, error) {
+	output, err := runCommand(exec.Command("git", "remote", "get-url", remote))
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s URL: %w", remote, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// listRemotes returns the names of the repository's remotes
+func listRemotes() []string {
+	output, err := runCommand(exec.Command("git", "remote"))
+	if err != nil {
+		debugf("git remote failed: %v", err)
+		return nil
+	}
+	return strings.Fields(string(output))
+}
+
+// gitConfigString reads a git config key, "" if unset
+func gitConfigString(key string) string {
+	output, err := runCommand(exec.Command("git", "config", "--get", key))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// configuredPushRemote returns the remote chosen for pushing branch by
+// --push-remote, git config gitcat.pushRemote, push_remote in the config,
+// or git's own branch.<name>.pushRemote and remote.pushDefault, in that
+// order, skipping names that aren't remotes. It returns "" when none is set.
+func configuredPushRemote(branch string) string {
+	remotes := listRemotes()
+	candidates := []string{*pushRemoteFlag, gitConfigString("gitcat.pushRemote"), getEffectiveConfig().PushRemote}
+	if branch != "" {
+		candidates = append(candidates, gitConfigString("branch."+branch+".pushRemote"))
+	}
+	candidates = append(candidates, gitConfigString("remote.pushDefault"))
+	for _, name := range candidates {
+		if name != "" && slices.Contains(remotes, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// pushRemote returns the remote branch is pushed to: the configured one,
+// else the remote of the branch's upstream, else origin, else the only
+// remote. It returns "origin" when nothing better is known.
+func pushRemote(branch string) string {
+	if remote := configuredPushRemote(branch); remote != "" {
+		return remote
+	}
+	remotes := listRemotes()
+	if branch != "" {
+		if remote := gitConfigString("branch." + branch + ".remote"); slices.Contains(remotes, remote) {
+			return remote
+		}
+	}
+	if len(remotes) == 1 {
+		return remotes[0]
+	}
+	return "origin"
+}
+
+// upstreamRemote returns the remote that PRs target when it isn't the one
+// branches are pushed to: --pr-remote, the configured upstream_remote (per
+// repo: git config gitcat.upstreamRemote), or else a remote named
+// "upstream" if there is one. It returns "" when none exists.
+func upstreamRemote() string {
+	name := *prRemoteFlag
+	if name == "" {
+		name = gitConfigString("gitcat.upstreamRemote")
+	}
+	if name == "" {
+		name = getEffectiveConfig().UpstreamRemote
+	}
+	if name == "" {
+		name = "upstream"
+	}
+	if _, err := remoteURL(name); err != nil {
+		return ""
+	}
+	return name
+}
+
+// prRemote returns the remote hosting the PR for branch
+func prRemote(branch string) string {
+	if remote := upstreamRemote(); remote != "" {
+		return remote
+	}
+	return pushRemote(branch)
+}
+
+// baseRemote is the remote whose default branch feature branches start
+// from: the one hosting PRs for the current branch
+func baseRemote() string {
+	branch, _ := getCurrentBranch()
+	return prRemote(branch)
+}
+
+// resolvePRTarget works out where the PR for branch goes. The branch is
+// pushed to its push remote; if an upstream remote points at another
+// GitHub repository, or gh reports that the push remote is a fork, the PR
+// is opened there with the fork's branch as its head. Otherwise it goes to
+// the push remote's own repository, named explicitly so gh doesn't pick
+// another remote.
+func resolvePRTarget(branch string) prTarget {
+	url, err := remoteURL(pushRemote(branch))
+	if err != nil {
+		return prTarget{}
+	}
+	if host, pushRepo := giteaRepo(url); host != nil {
+		return resolveGiteaTarget(host, pushRepo, branch)
+	}
+	pushRepo := githubRepo(url)
+	if pushRepo == "" {
+		return prTarget{}
+	}
+	owner, _, _ := strings.Cut(pushRepo, "/")
+
+	if remote := upstreamRemote(); remote != "" {
+		if url, err := remoteURL(remote); err == nil {
+			if repo := githubRepo(url); repo != "" && !strings.EqualFold(repo, pushRepo) {
+				return prTarget{repo: repo, head: owner + ":" + branch}
+			}
+		}
+	}
+
+	if useGitHubAPI() {
+		if repo, err := apiGetRepo(pushRepo); err == nil && repo.Fork && repo.Parent != nil {
+			return prTarget{repo: repo.Parent.FullName, head: owner + ":" + branch}
+		}
+		return prTarget{repo: pushRepo, head: branch}
+	}
+	cmd := exec.Command("gh", "repo", "view", pushRepo, "--json", "isFork,parent",
+		"--jq", `if .isFork and .parent then .parent.owner.login + "/" + .parent.name else "" end`)
+	if output, err := runCommand(cmd); err == nil {
+		if parent := strings.TrimSpace(string(output)); parent != "" {
+			return prTarget{repo: parent, head: owner + ":" + branch}
+		}
+	}
+	return prTarget{repo: pushRepo, head: branch}
+}
+
+// ghArgs renders the target as gh pr flags
+func (t prTarget) ghArgs() []string {
+	if t.repo == "" {
+		return nil
+	}
+	return []string{"--repo", t.repo, "--head", t.head}
+}
+
+// forkOwner returns the owner of the fork holding the head branch, or ""
+// when the branch is in the receiving repository
+func (t prTarget) forkOwner() string {
+	owner, _, _ := strings.Cut(t.head, ":")
+	if owner == t.head {
+		return ""
+	}
+	return owner
+}
+
+// withPRTarget resolves where the PR goes, once per run
+func (m model) withPRTarget() model {
+	if m.prTarget == nil {
+		target := resolvePRTarget(m.currentBranch)
+		m.prTarget = &target
+	}
+	return m
+}
+
+// enterUpstreamPromptPhase asks where to push a branch without an upstream.
+// With a push remote configured, or only one remote, it just confirms;
+// otherwise each remote is offered, the default push remote first.
+func (m model) enterUpstreamPromptPhase() model {
+	m.phase = "upstream_prompt"
+	m.cursor = 0
+	remote := pushRemote(m.currentBranch)
+	m.upstreamRemotes = []string{remote}
+	if configuredPushRemote(m.currentBranch) == "" {
+		for _, r := range listRemotes() {
+			if r != remote {
+				m.upstreamRemotes = append(m.upstreamRemotes, r)
+			}
+		}
+	}
+	if len(m.upstreamRemotes) == 1 {
+		m.choices = []string{tr("Yes, set upstream and push")}
+	} else {
+		m.choices = nil
+		for _, r := range m.upstreamRemotes {
+			m.choices = append(m.choices, tr("Yes, push to %s", r))
+		}
+	}
+	m.choices = append(m.choices, tr("No, skip"))
+	return m
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/burritocatai/gitcat/generate"
+)
+
+// trailerLine matches git trailers ("Key: value") and BREAKING CHANGE
+// footers, which must stay on one line
+var trailerLine = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*|BREAKING CHANGE): `)
+
+// listMarker matches a bullet or numbered list item and its marker
+var listMarker = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)`)
+
+// strictFormatEnabled reports whether messages must follow the 50/72 rule,
+// from the gitcat config or the repository's gitcat.strict5072 git config
+func strictFormatEnabled() bool {
+	return getEffectiveConfig().Strict5072 || gitConfigBool("gitcat.strict5072")
+}
+
+// messageVerbosity returns how much body generated messages get: from
+// --verbosity, the repository's gitcat.verbosity git config, or verbosity
+// in the gitcat config
+func messageVerbosity() string {
+	verbosity := *verbosityFlag
+	if verbosity == "" {
+		verbosity = gitConfigString("gitcat.verbosity")
+	}
+	if verbosity == "" {
+		verbosity = getEffectiveConfig().Verbosity
+	}
+	if !slices.Contains(generate.Verbosities, verbosity) {
+		if verbosity != "" {
+			debugf("ignoring unknown verbosity %q", verbosity)
+		}
+		return generate.VerbosityStandard
+	}
+	return verbosity
+}
+
+// terseMessage cuts a message down to its subject, keeping a BREAKING
+// CHANGE footer, for models that write a body when told not to
+func terseMessage(message string) string {
+	subject, rest, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	for _, line := range strings.Split(rest, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE: ") || strings.HasPrefix(line, "BREAKING-CHANGE: ") {
+			return subject + "\n\n" + line
+		}
+	}
+	return subject
+}
+
+// subjectViolations reports a subject over the 50/72 limit
+func subjectViolations(message string) []string {
+	subject, _, _ := strings.Cut(message, "\n")
+	if n := len([]rune(subject)); n > generate.StrictSubjectLimit {
+		return []string{fmt.Sprintf("subject line must be at most %d characters (is %d)", generate.StrictSubjectLimit, n)}
+	}
+	return nil
+}
+
+// wrapBody hard-wraps the body of message at width columns. List items get
+// a hanging indent; trailers, indented code, and lines without spaces
+// (URLs, paths) are left alone.
+func wrapBody(message string, width int) string {
+	lines := strings.Split(message, "\n")
+	out := []string{lines[0]}
+	for _, line := range lines[1:] {
+		if len([]rune(line)) <= width || trailerLine.MatchString(line) ||
+			strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") || !strings.Contains(strings.TrimSpace(line), " ") {
+			out = append(out, line)
+			continue
+		}
+		prefix, indent := "", ""
+		if m := listMarker.FindString(line); m != "" {
+			prefix, indent = m, strings.Repeat(" ", len(m))
+		}
+		current := prefix
+		for _, word := range strings.Fields(line[len(prefix):]) {
+			if current != prefix && current != indent && len([]rune(current))+1+len([]rune(word)) > width {
+				out = append(out, current)
+				current = indent
+			}
+			if current == prefix || current == indent {
+				current += word
+			} else {
+				current += " " + word
+			}
+		}
+		out = append(out, current)
+	}
+	return strings.Join(out, "\n")
+}
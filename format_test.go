@@ -0,0 +1,96 @@
+package main
+
+import "testing"
+
+func TestWrapBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "subject left alone",
+			message: "feat: a subject line that is longer than the width",
+			want:    "feat: a subject line that is longer than the width",
+		},
+		{
+			name:    "paragraph",
+			message: "fix: x\n\nthe quick brown fox jumps over the lazy dog",
+			want:    "fix: x\n\nthe quick brown fox\njumps over the lazy\ndog",
+		},
+		{
+			name:    "short lines kept",
+			message: "fix: x\n\nshort line\nanother one",
+			want:    "fix: x\n\nshort line\nanother one",
+		},
+		{
+			name:    "bullet gets a hanging indent",
+			message: "fix: x\n\n- item with many words beyond the width",
+			want:    "fix: x\n\n- item with many\n  words beyond the\n  width",
+		},
+		{
+			name:    "numbered item",
+			message: "fix: x\n\n1. first step of the long procedure",
+			want:    "fix: x\n\n1. first step of the\n   long procedure",
+		},
+		{
+			name:    "trailer kept on one line",
+			message: "fix: x\n\nSigned-off-by: A Very Long Name <a.very.long.name@example.com>",
+			want:    "fix: x\n\nSigned-off-by: A Very Long Name <a.very.long.name@example.com>",
+		},
+		{
+			name:    "breaking change footer kept",
+			message: "feat!: x\n\nBREAKING CHANGE: the config file moved somewhere else",
+			want:    "feat!: x\n\nBREAKING CHANGE: the config file moved somewhere else",
+		},
+		{
+			name:    "indented code kept",
+			message: "fix: x\n\n    if err := run(ctx, args); err != nil { return err }",
+			want:    "fix: x\n\n    if err := run(ctx, args); err != nil { return err }",
+		},
+		{
+			name:    "URL kept",
+			message: "fix: x\n\nhttps://example.com/a/very/long/path/to/an/issue",
+			want:    "fix: x\n\nhttps://example.com/a/very/long/path/to/an/issue",
+		},
+		{
+			name:    "word longer than the width",
+			message: "fix: x\n\nsee internal/provider/ratelimit.go for details",
+			want:    "fix: x\n\nsee\ninternal/provider/ratelimit.go\nfor details",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapBody(tt.message, 20); got != tt.want {
+				t.Errorf("wrapBody() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerseMessage(t *testing.T) {
+	tests := []struct {
+		message, want string
+	}{
+		{"fix: x", "fix: x"},
+		{"fix: x\n\nsome body\nmore body", "fix: x"},
+		{"feat!: x\n\nbody\n\nBREAKING CHANGE: config moved", "feat!: x\n\nBREAKING CHANGE: config moved"},
+		{"  feat: x\n", "feat: x"},
+	}
+	for _, tt := range tests {
+		if got := terseMessage(tt.message); got != tt.want {
+			t.Errorf("terseMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestSubjectViolations(t *testing.T) {
+	if got := subjectViolations("fix: short subject\n\n" + "a body line that can be as long as it likes"); got != nil {
+		t.Errorf("subjectViolations() = %q, want none", got)
+	}
+	got := subjectViolations("fix: a subject line well over the fifty character limit")
+	want := "subject line must be at most 50 characters (is 55)"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("subjectViolations() = %q, want [%q]", got, want)
+	}
+}
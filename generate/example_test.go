@@ -0,0 +1,63 @@
+package generate_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/burritocatai/gitcat/config"
+	"github.com/burritocatai/gitcat/generate"
+)
+
+// A diff goes in and a commit message comes out. In a repository, the diff
+// would come from gitops.Repo{}.StagedDiff() and the config from
+// config.Load(config.Path()).
+func Example() {
+	// A stand-in for a local Ollama server, so the example runs offline
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"message": {"role": "assistant", "content": "feat(api): add a health check endpoint\n"}}`)
+	}))
+	defer server.Close()
+
+	c := config.Default()
+	c.Provider = "ollama"
+	c.OllamaURL = server.URL
+
+	diff := `diff --git a/api/health.go b/api/health.go
+new file mode 100644
+--- /dev/null
++++ b/api/health.go
+@@ -0,0 +1,5 @@
++package api
++
++func Health(w http.ResponseWriter, r *http.Request) {
++	w.WriteHeader(http.StatusOK)
++}
+`
+	message, err := generate.CommitMessage(context.Background(), c, generate.Request{
+		Diff:  diff,
+		Type:  config.CommitType{Name: "feat", Description: "A new feature"},
+		Scope: "api",
+	})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(message)
+	// Output: feat(api): add a health check endpoint
+}
+
+func ExampleCommitPrompt() {
+	data := generate.CommitPrompt(generate.Request{
+		Diff:     "diff --git a/go.mod b/go.mod\n",
+		Type:     config.CommitType{Name: "build"},
+		Breaking: true,
+		Strict:   true,
+	})
+	fmt.Println(data.Format)
+	fmt.Println(data.SubjectLimit)
+	// Output:
+	// build!: <description>
+	// 50
+}
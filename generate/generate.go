@@ -0,0 +1,30 @@
+// Package generate builds gitcat's commit message prompt from a diff and
+// sends prompts to the configured model. Together with the config package
+// for settings and the gitops package for the staged diff, it lets a Go
+// program write commit messages the way the gitcat command does, without
+// its terminal interface.
+//
+// Prompt templates, commitlint checks, and the filtering and fitting of the
+// diff to the model's context stay in the command: CommitPrompt takes the
+// diff as it should be sent.
+package generate
+
+import (
+	"context"
+
+	"github.com/burritocatai/gitcat/config"
+	"github.com/burritocatai/gitcat/provider"
+)
+
+// Text sends prompt to c's provider and model, and returns the response
+func Text(ctx context.Context, c *config.Config, prompt string, params config.GenerationParams) (string, error) {
+	return provider.Generate(ctx, c.ProviderSettings(), c.Model, prompt, provider.Params(params))
+}
+
+// CommitMessage asks c's commit model for a message for req, with the
+// built-in prompt and the config's commit generation parameters
+func CommitMessage(ctx context.Context, c *config.Config, req Request) (string, error) {
+	commit := *c
+	commit.Model = c.GetCommitModel()
+	return Text(ctx, &commit, CommitPrompt(req).Default, c.GetCommitParams())
+}
@@ -0,0 +1,148 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/burritocatai/gitcat/config"
+)
+
+func TestCommitPrompt(t *testing.T) {
+	feat := config.CommitType{Name: "feat", Description: "A new feature"}
+	types := []config.CommitType{feat, {Name: "fix", Description: "A bug fix"}}
+	tests := []struct {
+		name    string
+		req     Request
+		format  string
+		limit   int
+		want    []string // In the built-in prompt
+		notWant []string
+	}{
+		{
+			name:   "type and scope",
+			req:    Request{Diff: "+added", Type: feat, Scope: "api"},
+			format: "feat(api): <description>",
+			limit:  72,
+			want:   []string{"The commit type is: feat (A new feature)", "The scope is: api", "Git diff:\n+added", "you can add a body"},
+		},
+		{
+			name:    "model picks the type",
+			req:     Request{Types: types},
+			format:  "<type>: <description>",
+			limit:   72,
+			want:    []string{"- feat: A new feature\n- fix: A bug fix", "There is no scope"},
+			notWant: []string{"The commit type is"},
+		},
+		{
+			name:    "breaking, terse",
+			req:     Request{Type: feat, Breaking: true, Verbosity: VerbosityTerse},
+			format:  "feat!: <description>",
+			limit:   72,
+			want:    []string{"BREAKING CHANGE: <one-sentence summary of the breakage and how users should migrate>", "Write ONLY the subject line and the BREAKING CHANGE footer"},
+			notWant: []string{"explain in the body"},
+		},
+		{
+			name:   "strict, detailed",
+			req:    Request{Type: feat, Strict: true, Verbosity: VerbosityDetailed},
+			format: "feat: <description>",
+			limit:  StrictSubjectLimit,
+			want:   []string{"max 50 characters", "Wrap body lines at 72 characters.", "always add a body of bullet points"},
+		},
+		{
+			name:   "style and glossary",
+			req:    Request{Type: feat, Glossary: map[string]string{"zeta": "last", "alpha": "first"}, Examples: []string{"feat: one"}, Exemplars: []string{"fix: gold"}},
+			format: "feat: <description>",
+			limit:  72,
+			want:   []string{"- alpha: first\n- zeta: last\n", "maintainers as the standard to meet", "---\nfix: gold\n---", "newest first", "---\nfeat: one\n---"},
+		},
+		{
+			name:   "squash, revert, retry",
+			req:    Request{Type: feat, Squashed: "feat: a\n\nfeat: b", Reverted: "feat: broken", Intent: "it crashes", Previous: "feat: too long", Violations: []string{"subject too long"}},
+			format: "feat: <description>",
+			limit:  72,
+			want:   []string{"being squashed into one", "---\nfeat: a\n\nfeat: b\n---", "Its message was:\n---\nfeat: broken\n---\nThe reason for reverting it: it crashes", "Your previous attempt was:\nfeat: too long", "- subject too long\n"},
+		},
+		{
+			name:   "empty commit",
+			req:    Request{Type: config.CommitType{Name: "ci"}, Empty: true},
+			format: "ci: <description>",
+			limit:  72,
+			want:   []string{"This is an empty commit", "usually re-runs CI"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := CommitPrompt(tt.req)
+			if data.Format != tt.format || data.SubjectLimit != tt.limit {
+				t.Errorf("CommitPrompt() format %q, limit %d, want %q, %d", data.Format, data.SubjectLimit, tt.format, tt.limit)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(data.Default, s) {
+					t.Errorf("prompt is missing %q:\n%s", s, data.Default)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(data.Default, s) {
+					t.Errorf("prompt has %q:\n%s", s, data.Default)
+				}
+			}
+			if !strings.Contains(data.Default, data.Instructions) {
+				t.Error("Instructions are not part of the built-in prompt")
+			}
+		})
+	}
+}
+
+func TestCommitPromptMerge(t *testing.T) {
+	data := CommitPrompt(Request{
+		Diff:  "+merged",
+		Type:  config.CommitType{Name: "feat"},
+		Merge: &Merge{Subject: "Merge branch 'topic'", Commits: []string{"feat: b", "feat: a"}, Conflicts: []string{"go.mod"}},
+	})
+	for _, s := range []string{"Use this first line exactly:\nMerge branch 'topic'", "- feat: b\n- feat: a", "These files had conflicts, resolved by hand: go.mod.", "Git diff of the merge:\n+merged"} {
+		if !strings.Contains(data.Default, s) {
+			t.Errorf("merge prompt is missing %q:\n%s", s, data.Default)
+		}
+	}
+	if data.Format != "" || strings.Contains(data.Default, "conventional commits") {
+		t.Errorf("merge prompt has the conventional commit format: %q", data.Format)
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	var got struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"message": {"content": "fix: handle nil\r\n\r\nBody."}}`))
+	}))
+	defer server.Close()
+
+	c := &config.Config{Provider: "ollama", Model: "llama3.2", CommitModel: "qwen2.5-coder", OllamaURL: server.URL}
+	message, err := CommitMessage(context.Background(), c, Request{Diff: "+if x == nil {", Type: config.CommitType{Name: "fix"}})
+	if err != nil {
+		t.Fatalf("CommitMessage() error = %v", err)
+	}
+	if message != "fix: handle nil\n\nBody." {
+		t.Errorf("CommitMessage() = %q, want the response with LF line endings", message)
+	}
+	if got.Model != "qwen2.5-coder" {
+		t.Errorf("sent to model %q, want the commit model", got.Model)
+	}
+	if len(got.Messages) != 1 || !strings.Contains(got.Messages[0].Content, "Git diff:\n+if x == nil {") {
+		t.Errorf("sent messages %+v, want the commit prompt", got.Messages)
+	}
+	if c.Model != "llama3.2" {
+		t.Errorf("CommitMessage() changed the config's model to %q", c.Model)
+	}
+}
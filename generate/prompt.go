@@ -0,0 +1,279 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/burritocatai/gitcat/config"
+)
+
+// Limits of the 50/72 rule: a short subject, and a body wrapped so it reads
+// well in git log and email patches
+const (
+	StrictSubjectLimit = 50
+	StrictBodyWidth    = 72
+)
+
+// Message verbosities (Config.Verbosity): how much body the model writes
+const (
+	VerbosityTerse    = "terse"    // The subject line alone
+	VerbosityStandard = "standard" // A body when the changes warrant one (default)
+	VerbosityDetailed = "detailed" // Always a body of bullet points
+)
+
+// Verbosities lists the valid verbosities
+var Verbosities = []string{VerbosityTerse, VerbosityStandard, VerbosityDetailed}
+
+// Request is everything the commit message prompt is built from. Only Diff
+// and either Type or Types are needed; the rest add to the prompt when set.
+type Request struct {
+	Diff      string
+	Type      config.CommitType // Empty to let the model choose from Types
+	Types     []config.CommitType
+	Scope     string
+	Breaking  bool     // Ask for "type!:" and a BREAKING CHANGE footer
+	Strict    bool     // Ask for a 50-character subject and a body wrapped at 72
+	Verbosity string   // How much body to ask for, "" for standard
+	Examples  []string // Recent commit messages to match the style of
+	Exemplars []string // Commit messages chosen as the standard to meet
+	Glossary  map[string]string
+	Squashed  string // Messages of the commits being squashed into this one
+
+	Reverted string // Message of the commit being reverted
+	Merge    *Merge // The merge being concluded, which gets its own prompt
+	Empty    bool   // The commit changes no files
+	Intent   string // The user's reason for a revert or purpose of an empty commit
+
+	// A previous attempt and the commitlint rules it broke, for a retry
+	Previous   string
+	Violations []string
+}
+
+// Merge describes a merge waiting to be committed
+type Merge struct {
+	Subject   string   // First line of git's prepared message, e.g. "Merge branch 'x'"
+	Commits   []string // Subjects of the commits being merged in, newest first
+	Conflicts []string // Files that had conflicts, as git listed them
+}
+
+// PromptData is what a commit.tmpl prompt template can use
+type PromptData struct {
+	Diff         string              // The staged diff, filtered and fitted to the model
+	Type         config.CommitType   // The chosen commit type, empty when the model picks one of Types
+	Types        []config.CommitType // The types to choose from
+	Scope        string
+	Breaking     bool
+	Verbosity    string // "terse", "standard", or "detailed"
+	Format       string // The subject format, e.g. "feat(api): <description>"
+	SubjectLimit int    // Characters allowed on the subject line
+	Instructions string // gitcat's additions: breaking change, glossary, style examples, squash, revert, retry
+	Default      string // The built-in prompt, for templates that only add to it
+}
+
+// CommitPrompt gathers the parts of the commit message prompt, along
+// with the built-in prompt made of them
+func CommitPrompt(req Request) PromptData {
+	data := PromptData{Diff: req.Diff, Type: req.Type, Types: req.Types, Scope: req.Scope, Breaking: req.Breaking, Verbosity: req.Verbosity}
+	if req.Merge != nil {
+		data.Default = mergePrompt(req.Merge, req.Diff)
+		return data
+	}
+	typeLine := "The commit type is: " + req.Type.Name
+	if req.Type.Description != "" {
+		typeLine += fmt.Sprintf(" (%s)", req.Type.Description)
+	}
+	formatType := req.Type.Name
+	if req.Type.Name == "" {
+		var list []string
+		for _, t := range req.Types {
+			list = append(list, fmt.Sprintf("- %s: %s", t.Name, t.Description))
+		}
+		typeLine = "Choose the commit type that best fits the changes from this list:\n" + strings.Join(list, "\n")
+		formatType = "<type>"
+	}
+
+	prefix, scopeLine := fmt.Sprintf("%s(%s)", formatType, req.Scope), "The scope is: "+req.Scope
+	if req.Scope == "" {
+		prefix, scopeLine = formatType, "There is no scope: leave out the parentheses."
+	}
+	format := prefix + ": <description>"
+	var extra string
+	if req.Breaking {
+		format = prefix + "!: <description>"
+		extra = `
+This is a BREAKING CHANGE. Keep the "!" before the colon, explain in the body what breaks and how users should migrate, and end the message with a footer line:
+BREAKING CHANGE: <one-sentence summary of the breakage>
+`
+		if req.Verbosity == VerbosityTerse {
+			extra = `
+This is a BREAKING CHANGE. Keep the "!" before the colon, and end the message with a footer line:
+BREAKING CHANGE: <one-sentence summary of the breakage and how users should migrate>
+`
+		}
+	}
+
+	subjectLimit := 72
+	if req.Strict {
+		subjectLimit = StrictSubjectLimit
+		extra += fmt.Sprintf("\nWrap body lines at %d characters.\n", StrictBodyWidth)
+	}
+	extra += GlossaryPrompt(req.Glossary)
+	extra += exemplarsPrompt(req.Exemplars)
+	extra += styleExamplesPrompt(req.Examples)
+	if req.Squashed != "" {
+		extra += fmt.Sprintf(`
+The diff combines several commits that are being squashed into one. Their messages, oldest first:
+---
+%s
+---
+Write one message that covers the combined change as a whole, summarizing the main points in the body rather than listing every commit.
+`, req.Squashed)
+	}
+	if req.Reverted != "" {
+		extra += revertPrompt(req.Reverted, req.Intent)
+	}
+	if req.Empty {
+		extra += emptyCommitPrompt(req.Intent)
+	}
+	if len(req.Violations) > 0 {
+		extra += fmt.Sprintf(`
+Your previous attempt was:
+%s
+
+It was rejected by the repository's commit message rules:
+- %s
+Write a new message that satisfies every rule.
+`, req.Previous, strings.Join(req.Violations, "\n- "))
+	}
+
+	data.Format, data.SubjectLimit, data.Instructions = format, subjectLimit, extra
+	data.Default = fmt.Sprintf(`You are a commit message generator. Based on the following git diff, generate a concise commit message using conventional commits format.
+
+%s
+%s
+
+Format: %s
+
+The description should be:
+- Clear and concise (max %d characters for the first line)
+- In imperative mood (e.g., "add" not "added")
+- Explain WHAT and WHY, not HOW
+
+%s
+%s
+Git diff:
+%s
+
+Respond with ONLY the commit message, no explanations or markdown formatting.`, typeLine, scopeLine, format, subjectLimit, bodyInstructions(req.Verbosity, req.Breaking), extra, req.Diff)
+	return data
+}
+
+// emptyCommitPrompt explains a commit with no changes, described by its
+// purpose alone
+func emptyCommitPrompt(intent string) string {
+	if intent == "" {
+		intent = "(not given; a commit like this usually re-runs CI or other automation)"
+	}
+	return fmt.Sprintf(`
+This is an empty commit: it changes no files, so the diff below is empty. Its purpose: %s
+Describe that purpose in the message and don't invent code changes.
+`, intent)
+}
+
+// bodyInstructions tells the model how much body to write
+func bodyInstructions(verbosity string, breaking bool) string {
+	switch verbosity {
+	case VerbosityTerse:
+		if breaking {
+			return "Write ONLY the subject line and the BREAKING CHANGE footer, with no body."
+		}
+		return "Write ONLY the subject line, with no body."
+	case VerbosityDetailed:
+		return `After a blank line, always add a body of bullet points ("- ") covering each notable change and why it was made.`
+	}
+	return "If the changes warrant it, you can add a body after a blank line with more details."
+}
+
+// GlossaryPrompt lists the configured project terms for the model, sorted
+// so the prompt is stable between runs
+func GlossaryPrompt(glossary map[string]string) string {
+	if len(glossary) == 0 {
+		return ""
+	}
+	terms := make([]string, 0, len(glossary))
+	for term := range glossary {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	var b strings.Builder
+	b.WriteString("\nProject terminology. Use these terms as defined rather than guessing from identifiers:\n")
+	for _, term := range terms {
+		fmt.Fprintf(&b, "- %s: %s\n", term, glossary[term])
+	}
+	return b.String()
+}
+
+// exemplarsPrompt presents the exemplar messages to the model as the
+// standard to write to
+func exemplarsPrompt(exemplars []string) string {
+	if len(exemplars) == 0 {
+		return ""
+	}
+	return "\nExemplary commit messages from this repository, chosen by its maintainers as the standard to meet. Write the new message the way these are written, in structure, level of detail, and wording, while keeping the format above:\n---\n" +
+		strings.Join(exemplars, "\n---\n") + "\n---\n"
+}
+
+// styleExamplesPrompt presents the examples to the model
+func styleExamplesPrompt(examples []string) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	return "\nRecent commit messages from this repository, newest first. Match their tone, tense, capitalization, level of detail, and how they name scopes, while keeping the format above:\n---\n" +
+		strings.Join(examples, "\n---\n") + "\n---\n"
+}
+
+// revertPrompt tells the model what is being reverted and why, for the body
+// of the revert's message
+func revertPrompt(reverted, reason string) string {
+	if reason == "" {
+		reason = "(not given; infer it from the changes if you can, otherwise don't speculate)"
+	}
+	return fmt.Sprintf(`
+This commit reverts an earlier commit. The diff undoes its changes. Its message was:
+---
+%s
+---
+The reason for reverting it: %s
+Use "revert: <subject of the reverted commit>" as the first line. In the body, explain what is being reverted and why.
+`, reverted, reason)
+}
+
+// mergePrompt asks for the body of a merge commit's message. The subject is
+// git's own, which conventional commit tooling ignores for merges.
+func mergePrompt(info *Merge, diff string) string {
+	commits := "(none listed)"
+	if len(info.Commits) > 0 {
+		commits = "- " + strings.Join(info.Commits, "\n- ")
+	}
+	conflicts := "There were no conflicts."
+	if len(info.Conflicts) > 0 {
+		conflicts = fmt.Sprintf("These files had conflicts, resolved by hand: %s. Briefly say how they were resolved, judging from the diff.", strings.Join(info.Conflicts, ", "))
+	}
+	return fmt.Sprintf(`You are a commit message generator. This commit concludes a git merge.
+
+Use this first line exactly:
+%s
+
+After a blank line, write a short body summarizing what the merge brings in, based on the merged commits below (newest first). Group related commits and describe the overall change rather than listing every commit.
+%s
+
+Merged commits:
+%s
+
+Git diff of the merge:
+%s
+
+Respond with ONLY the commit message, no explanations or markdown formatting.`, info.Subject, conflicts, commits, diff)
+}
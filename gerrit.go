@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gerritReview is where changes are pushed for review in a Gerrit project
+type gerritReview struct {
+	remote string // Remote hosting the Gerrit project
+	branch string // Branch the changes are for, pushed as refs/for/<branch>
+}
+
+// loadGerritReview returns how to push changes for review, or nil when the
+// repository isn't a Gerrit project. Projects are recognized by a
+// .gitreview file (as used by git-review) or git config gitcat.gerrit true.
+// The remote and branch come from .gitreview's defaultremote and
+// defaultbranch, falling back to a remote named "gerrit" or the push remote,
+// and to the remote's default branch.
+func loadGerritReview(currentBranch string) *gerritReview {
+	settings := readGitReview()
+	if settings == nil && !gitConfigBool("gitcat.gerrit") {
+		return nil
+	}
+	review := &gerritReview{remote: settings["defaultremote"], branch: settings["defaultbranch"]}
+	remotes := listRemotes()
+	if !slices.Contains(remotes, review.remote) {
+		review.remote = "gerrit"
+		if !slices.Contains(remotes, review.remote) {
+			review.remote = pushRemote(currentBranch)
+		}
+	}
+	if review.branch == "" {
+		review.branch = "master"
+		if output, err := runCommand(exec.Command("git", "symbolic-ref", "--short", "refs/remotes/"+review.remote+"/HEAD")); err == nil {
+			review.branch = strings.TrimPrefix(strings.TrimSpace(string(output)), review.remote+"/")
+		}
+	}
+	return review
+}
+
+// readGitReview reads the [gerrit] settings of the repository's .gitreview,
+// or returns nil when there is none
+func readGitReview() map[string]string {
+	root, err := getRepoRoot()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(root, ".gitreview"))
+	if err != nil {
+		return nil
+	}
+	settings := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			settings[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	return settings
+}
+
+// gerritChangeURL matches the change links Gerrit prints on push
+var gerritChangeURL = regexp.MustCompile(`remote:\s+(https?://\S+)`)
+
+// push sends HEAD for review and returns the links to the changes Gerrit
+// created or updated
+func (g *gerritReview) push(noVerify bool) ([]string, error) {
+	args := []string{"push", g.remote, "HEAD:refs/for/" + g.branch}
+	if noVerify {
+		args = append(args, "--no-verify")
+	}
+	output, err := runCommand(exec.Command("git", args...))
+	if err != nil {
+		return nil, fmt.Errorf("git push failed: %w\n%s", err, string(output))
+	}
+	var urls []string
+	for _, match := range gerritChangeURL.FindAllStringSubmatch(string(output), -1) {
+		urls = append(urls, match[1])
+	}
+	return urls, nil
+}
+
+// gerritChangesView lists the changes pushed for review
+func (m model) gerritChangesView() string {
+	if len(m.gerritChanges) == 0 {
+		return ""
+	}
+	s := ""
+	for _, url := range m.gerritChanges {
+		s += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Render(url) + "\n"
+	}
+	return s
+}
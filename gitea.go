@@ -0,0 +1,388 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultGiteaHosts are public Gitea and Forgejo instances recognized
+// without configuration
+var defaultGiteaHosts = []string{"codeberg.org"}
+
+// giteaHost is a Gitea or Forgejo instance that PRs can be opened on
+type giteaHost struct {
+	name    string // Host name as it appears in remote URLs
+	baseURL string // Web address, e.g. https://codeberg.org
+}
+
+// giteaHosts returns the known Gitea and Forgejo instances: gitea_hosts in
+// the config, the repository's git config gitcat.giteaHost (repeatable),
+// and the defaults. Entries are host names or base URLs, the latter for
+// instances served over http or under a path.
+func giteaHosts() []giteaHost {
+	var hosts []giteaHost
+	for _, entry := range mergeValues(getEffectiveConfig().GiteaHosts, gitConfigAll("gitcat.giteaHost"), defaultGiteaHosts) {
+		if !strings.Contains(entry, "://") {
+			entry = "https://" + entry
+		}
+		u, err := url.Parse(entry)
+		if err != nil || u.Hostname() == "" {
+			debugf("ignoring Gitea host %q", entry)
+			continue
+		}
+		hosts = append(hosts, giteaHost{name: u.Hostname(), baseURL: strings.TrimSuffix(u.String(), "/")})
+	}
+	return hosts
+}
+
+// giteaRepo returns the Gitea host and "owner/name" of a remote URL on one
+// of the known instances, or nil when it isn't on one
+func giteaRepo(remote string) (*giteaHost, string) {
+	host, path := remoteHostPath(remote)
+	if host == "" {
+		return nil, ""
+	}
+	for _, h := range giteaHosts() {
+		if h.name != host {
+			continue
+		}
+		// Instances under a path (https://example.com/git) have it in
+		// https URLs but not in ssh ones
+		if u, err := url.Parse(h.baseURL); err == nil {
+			path = strings.TrimPrefix(path, strings.Trim(u.Path, "/")+"/")
+		}
+		if strings.Count(path, "/") != 1 {
+			return nil, ""
+		}
+		return &h, path
+	}
+	return nil, ""
+}
+
+// token returns the access token for the instance: GITEA_TOKEN or
+// FORGEJO_TOKEN, else the one the tea CLI was logged in with
+func (h *giteaHost) token() string {
+	for _, name := range []string{"GITEA_TOKEN", "FORGEJO_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return h.teaToken()
+}
+
+// teaToken reads the token of the tea login for the instance from tea's
+// config.yml
+func (h *giteaHost) teaToken() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" && onWindows {
+		// tea keeps its config in %LocalAppData% on Windows
+		dir = os.Getenv("LocalAppData")
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tea", "config.yml"))
+	if err != nil {
+		return ""
+	}
+	// Logins are a list of maps; collect each one's url and token
+	var loginURL, token string
+	match := func() bool {
+		host, _ := remoteHostPath(loginURL)
+		return token != "" && host == h.name
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "- ") {
+			if match() {
+				return token
+			}
+			loginURL, token = "", ""
+			trimmed = strings.TrimSpace(trimmed[2:])
+		}
+		key, value, ok := cutYAMLKey(trimmed)
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `'"`)
+		switch key {
+		case "url":
+			loginURL = value
+		case "token":
+			token = value
+		}
+	}
+	if match() {
+		return token
+	}
+	return ""
+}
+
+// request calls the instance's API (/api/v1, which mirrors GitHub's REST API
+// closely)
+func (h *giteaHost) request(method, path string, payload, result any) error {
+	token := h.token()
+	if token == "" {
+		return fmt.Errorf("no token for %s (set GITEA_TOKEN or log in with tea)", h.name)
+	}
+	header := http.Header{
+		"Accept":        {"application/json"},
+		"Authorization": {"token " + token},
+	}
+	return apiRequest(h.baseURL+"/api/v1", header, method, path, payload, result)
+}
+
+// giteaPull is the part of the Gitea pull request resource gitcat uses
+type giteaPull struct {
+	Number    int    `json:"number"`
+	HTMLURL   string `json:"html_url"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+	Head struct {
+		Ref  string `json:"ref"`
+		Repo *struct {
+			Owner struct {
+				Login string `json:"login"`
+			} `json:"owner"`
+		} `json:"repo"`
+	} `json:"head"`
+}
+
+// resolveGiteaTarget works out where the PR for branch goes on a Gitea
+// instance, as resolvePRTarget does for GitHub: to the upstream remote's
+// repository or the fork's parent, or else to pushRepo itself
+func resolveGiteaTarget(host *giteaHost, pushRepo, branch string) prTarget {
+	owner, _, _ := strings.Cut(pushRepo, "/")
+	if remote := upstreamRemote(); remote != "" {
+		if url, err := remoteURL(remote); err == nil {
+			if upstreamHost, repo := giteaRepo(url); upstreamHost != nil && upstreamHost.name == host.name && !strings.EqualFold(repo, pushRepo) {
+				return prTarget{gitea: host, repo: repo, head: owner + ":" + branch}
+			}
+		}
+	}
+	var repo apiRepo
+	if err := host.request("GET", "/repos/"+pushRepo, nil, &repo); err == nil && repo.Fork && repo.Parent != nil {
+		return prTarget{gitea: host, repo: repo.Parent.FullName, head: owner + ":" + branch}
+	}
+	return prTarget{gitea: host, repo: pushRepo, head: branch}
+}
+
+// giteaFindExistingPR returns the open PR into target's repository from
+// branch, or nil when there is none
+func giteaFindExistingPR(target prTarget, branch string) *existingPR {
+	owner := target.forkOwner()
+	if owner == "" {
+		owner, _, _ = strings.Cut(target.repo, "/")
+	}
+	var pulls []giteaPull
+	if err := target.gitea.request("GET", "/repos/"+target.repo+"/pulls?state=open&limit=50", nil, &pulls); err != nil {
+		debugf("listing PRs: %v", err)
+		return nil
+	}
+	for _, pull := range pulls {
+		if pull.Head.Ref == branch && pull.Head.Repo != nil && strings.EqualFold(pull.Head.Repo.Owner.Login, owner) {
+			pr := &existingPR{Number: pull.Number, URL: pull.HTMLURL}
+			pr.Owner.Login = pull.Head.Repo.Owner.Login
+			return pr
+		}
+	}
+	return nil
+}
+
+// giteaCreatePR opens a PR from target.head into the default branch of
+// target.repo, tags it with meta, and returns its URL
+func giteaCreatePR(target prTarget, title, body string, meta *prMeta) (string, error) {
+	h := target.gitea
+	var repo apiRepo
+	if err := h.request("GET", "/repos/"+target.repo, nil, &repo); err != nil {
+		return "", err
+	}
+	payload := map[string]any{"title": title, "body": body, "head": target.head, "base": repo.DefaultBranch}
+	if meta != nil {
+		labels, err := h.labelIDs(target.repo, meta.labels)
+		if err != nil {
+			return "", err
+		}
+		payload["labels"] = labels
+		payload["assignees"] = h.assignees(meta.assignees)
+	}
+	var pull giteaPull
+	if err := h.request("POST", "/repos/"+target.repo+"/pulls", payload, &pull); err != nil {
+		return "", err
+	}
+	if err := h.requestReviews(target.repo, pull.Number, meta); err != nil {
+		return pull.HTMLURL, fmt.Errorf("created %s, but %w", pull.HTMLURL, err)
+	}
+	return pull.HTMLURL, nil
+}
+
+// giteaUpdatePR replaces the title and body of a PR and adds meta to it
+func giteaUpdatePR(h *giteaHost, repo string, number int, title, body string, meta *prMeta) error {
+	path := fmt.Sprintf("/repos/%s/pulls/%d", repo, number)
+	payload := map[string]any{"title": title, "body": body}
+	if meta != nil && len(meta.assignees) > 0 {
+		// Assignees are replaced on edit, so keep the current ones
+		var pull giteaPull
+		if err := h.request("GET", path, nil, &pull); err != nil {
+			return err
+		}
+		var assignees []string
+		for _, a := range pull.Assignees {
+			assignees = append(assignees, a.Login)
+		}
+		payload["assignees"] = mergeValues(assignees, h.assignees(meta.assignees))
+	}
+	if err := h.request("PATCH", path, payload, nil); err != nil {
+		return err
+	}
+	if meta != nil && len(meta.labels) > 0 {
+		labels, err := h.labelIDs(repo, meta.labels)
+		if err != nil {
+			return err
+		}
+		if err := h.request("POST", fmt.Sprintf("/repos/%s/issues/%d/labels", repo, number), map[string][]int64{"labels": labels}, nil); err != nil {
+			return err
+		}
+	}
+	return h.requestReviews(repo, number, meta)
+}
+
+// labelIDs maps label names to the IDs Gitea takes, looking in the
+// repository's labels and its organization's
+func (h *giteaHost) labelIDs(repo string, names []string) ([]int64, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	type label struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	var labels, orgLabels []label
+	if err := h.request("GET", "/repos/"+repo+"/labels?limit=100", nil, &labels); err != nil {
+		return nil, err
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	if err := h.request("GET", "/orgs/"+owner+"/labels?limit=100", nil, &orgLabels); err == nil {
+		labels = append(labels, orgLabels...)
+	}
+	var ids []int64
+	for _, name := range names {
+		i := slices.IndexFunc(labels, func(l label) bool { return strings.EqualFold(l.Name, name) })
+		if i < 0 {
+			return nil, fmt.Errorf("label %q not found in %s", name, repo)
+		}
+		ids = append(ids, labels[i].ID)
+	}
+	return ids, nil
+}
+
+// assignees resolves "@me" to the token's user, as gh does on GitHub
+func (h *giteaHost) assignees(names []string) []string {
+	var resolved []string
+	for _, name := range names {
+		if name == "@me" {
+			var user struct {
+				Login string `json:"login"`
+			}
+			if err := h.request("GET", "/user", nil, &user); err != nil {
+				debugf("reading Gitea user: %v", err)
+				continue
+			}
+			name = user.Login
+		}
+		resolved = append(resolved, name)
+	}
+	return resolved
+}
+
+// requestReviews asks the reviewers in meta for a review; "org/team"
+// reviewers are requested as teams
+func (h *giteaHost) requestReviews(repo string, number int, meta *prMeta) error {
+	if meta == nil {
+		return nil
+	}
+	reviewers := meta.reviewers
+	if meta.useSuggested {
+		reviewers = mergeValues(reviewers, meta.suggested)
+	}
+	if len(reviewers) == 0 {
+		return nil
+	}
+	payload := map[string][]string{"reviewers": {}, "team_reviewers": {}}
+	for _, reviewer := range reviewers {
+		if _, team, ok := strings.Cut(reviewer, "/"); ok {
+			payload["team_reviewers"] = append(payload["team_reviewers"], team)
+		} else {
+			payload["reviewers"] = append(payload["reviewers"], reviewer)
+		}
+	}
+	return h.request("POST", fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", repo, number), payload, nil)
+}
+
+// giteaEnableAutoMerge schedules the PR to be merged with strategy once its
+// checks succeed
+func giteaEnableAutoMerge(h *giteaHost, repo string, number int, strategy string) error {
+	payload := map[string]any{"Do": strategy, "merge_when_checks_succeed": true}
+	return h.request("POST", fmt.Sprintf("/repos/%s/pulls/%d/merge", repo, number), payload, nil)
+}
+
+// giteaFetchIssue reads an issue; pull requests are rejected like gh issue
+// view does
+func giteaFetchIssue(h *giteaHost, repo string, number int) (*githubIssue, error) {
+	var raw struct {
+		Number      int       `json:"number"`
+		Title       string    `json:"title"`
+		Body        string    `json:"body"`
+		State       string    `json:"state"`
+		PullRequest *struct{} `json:"pull_request"`
+	}
+	if err := h.request("GET", fmt.Sprintf("/repos/%s/issues/%d", repo, number), nil, &raw); err != nil {
+		return nil, err
+	}
+	if raw.PullRequest != nil {
+		return nil, fmt.Errorf("#%d is a pull request", number)
+	}
+	return &githubIssue{Number: raw.Number, Title: raw.Title, Body: raw.Body, State: strings.ToUpper(raw.State)}, nil
+}
+
+// giteaPRURLPattern matches the repository and number in a Gitea PR's web
+// URL
+var giteaPRURLPattern = regexp.MustCompile(`^(.*)/([^/]+/[^/]+)/pulls/([0-9]+)$`)
+
+// parseGiteaPRURL finds the instance, repository, and number of a PR URL on
+// a known Gitea instance
+func parseGiteaPRURL(prURL string) (*giteaHost, string, int, bool) {
+	match := giteaPRURLPattern.FindStringSubmatch(prURL)
+	if match == nil {
+		return nil, "", 0, false
+	}
+	for _, h := range giteaHosts() {
+		if strings.EqualFold(h.baseURL, match[1]) {
+			number, err := strconv.Atoi(match[3])
+			return &h, match[2], number, err == nil
+		}
+	}
+	return nil, "", 0, false
+}
+
+// baseGiteaRepo returns the Gitea instance and "owner/name" of the base
+// remote's repository, or nil when it isn't on a known instance
+func baseGiteaRepo() (*giteaHost, string) {
+	url, err := remoteURL(baseRemote())
+	if err != nil {
+		return nil, ""
+	}
+	return giteaRepo(url)
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// githubAPIURL is the GitHub REST API, used when the gh CLI isn't installed
+const githubAPIURL = "https://api.github.com"
+
+// useGitHubAPI reports whether to call the GitHub API directly rather than
+// through gh: when gh isn't installed, or when github_api is set (per repo:
+// git config gitcat.githubAPI true). Otherwise gh is used, so that its host
+// settings keep applying.
+func useGitHubAPI() bool {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return true
+	}
+	return getEffectiveConfig().GitHubAPI || gitConfigBool("gitcat.githubAPI")
+}
+
+// githubToken returns the token for direct API calls: GH_TOKEN or
+// GITHUB_TOKEN, else the one gh is logged in with, so no second credential
+// has to be set up
+func githubToken() string {
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	if token := ghAuthToken(); token != "" {
+		return token
+	}
+	return ghHostsToken()
+}
+
+// ghAuthToken asks gh for its github.com token, which it may keep in the
+// system keyring. It returns "" when gh isn't installed or logged in.
+func ghAuthToken() string {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return ""
+	}
+	output, err := runCommand(exec.Command("gh", "auth", "token", "--hostname", "github.com"))
+	if err != nil {
+		debugf("gh auth token: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// ghHostsToken reads the github.com oauth_token from gh's hosts.yml, left
+// behind when gh was set up but is no longer installed. Newer gh versions
+// keep the token in the system keyring instead, which only gh itself can
+// read.
+func ghHostsToken() string {
+	dir := os.Getenv("GH_CONFIG_DIR")
+	if dir == "" {
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			dir = filepath.Join(xdg, "gh")
+		} else if appData := os.Getenv("AppData"); onWindows && appData != "" {
+			dir = filepath.Join(appData, "GitHub CLI")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, ".config", "gh")
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "hosts.yml"))
+	if err != nil {
+		return ""
+	}
+	host := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && line[0] != ' ' {
+			host = strings.TrimSuffix(strings.TrimSpace(line), ":")
+			continue
+		}
+		if token, ok := strings.CutPrefix(strings.TrimSpace(line), "oauth_token:"); ok && host == "github.com" {
+			return strings.Trim(strings.TrimSpace(token), `'"`)
+		}
+	}
+	return ""
+}
+
+// githubRequest calls the GitHub REST API, sending payload as JSON when it
+// isn't nil and decoding the response into result when that isn't nil
+func githubRequest(method, path string, payload, result any) error {
+	token := githubToken()
+	if token == "" {
+		return fmt.Errorf("no GitHub token: set GH_TOKEN or GITHUB_TOKEN, or log in with gh auth login")
+	}
+	header := http.Header{
+		"Accept":               {"application/vnd.github+json"},
+		"Authorization":        {"Bearer " + token},
+		"X-Github-Api-Version": {"2022-11-28"},
+	}
+	return apiRequest(githubAPIURL, header, method, path, payload, result)
+}
+
+// apiRequest calls a forge's REST API at baseURL, which GitHub and Gitea
+// shape alike: JSON in and out, and errors with a message
+func apiRequest(baseURL string, header http.Header, method, path string, payload, result any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header = header.Clone()
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := doRequest(&http.Client{}, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.Unmarshal(data, &apiErr)
+		message := apiErr.Message
+		for _, e := range apiErr.Errors {
+			if e.Message != "" {
+				message += ": " + e.Message
+			}
+		}
+		return fmt.Errorf("%s %s: %s: %s", method, baseURL+path, resp.Status, message)
+	}
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("failed to parse API response: %w", err)
+		}
+	}
+	return nil
+}
+
+// apiRepo is the part of the GitHub repository resource gitcat uses
+type apiRepo struct {
+	DefaultBranch string `json:"default_branch"`
+	Fork          bool   `json:"fork"`
+	Parent        *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+}
+
+// apiGetRepo reads a repository ("owner/name")
+func apiGetRepo(repo string) (*apiRepo, error) {
+	var r apiRepo
+	if err := githubRequest("GET", "/repos/"+repo, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// apiPull is the part of the GitHub pull request resource gitcat uses
+type apiPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	NodeID  string `json:"node_id"`
+	Head    struct {
+		Repo *struct {
+			Owner struct {
+				Login string `json:"login"`
+			} `json:"owner"`
+		} `json:"repo"`
+	} `json:"head"`
+}
+
+// apiFindExistingPR returns the open PR into repo from head ("owner:branch")
+func apiFindExistingPR(repo, head string) *existingPR {
+	var pulls []apiPull
+	query := url.Values{"state": {"open"}, "head": {head}}
+	if err := githubRequest("GET", "/repos/"+repo+"/pulls?"+query.Encode(), nil, &pulls); err != nil {
+		debugf("listing PRs: %v", err)
+		return nil
+	}
+	if len(pulls) == 0 {
+		return nil
+	}
+	pr := &existingPR{Number: pulls[0].Number, URL: pulls[0].HTMLURL}
+	if pulls[0].Head.Repo != nil {
+		pr.Owner.Login = pulls[0].Head.Repo.Owner.Login
+	}
+	return pr
+}
+
+// apiCreatePR opens a PR from target.head into the default branch of
+// target.repo, tags it with meta, and returns its URL
+func apiCreatePR(target prTarget, title, body string, meta *prMeta) (string, error) {
+	repo, err := apiGetRepo(target.repo)
+	if err != nil {
+		return "", err
+	}
+	var pull apiPull
+	payload := map[string]string{"title": title, "body": body, "head": target.head, "base": repo.DefaultBranch}
+	if err := githubRequest("POST", "/repos/"+target.repo+"/pulls", payload, &pull); err != nil {
+		return "", err
+	}
+	if err := apiApplyPRMeta(target.repo, pull.Number, meta); err != nil {
+		return pull.HTMLURL, fmt.Errorf("created %s, but %w", pull.HTMLURL, err)
+	}
+	return pull.HTMLURL, nil
+}
+
+// apiUpdatePR replaces the title and body of pr and adds meta to it
+func apiUpdatePR(pr *existingPR, title, body string, meta *prMeta) error {
+	repo, number, ok := parsePRURL(pr.URL)
+	if !ok {
+		return fmt.Errorf("unexpected PR URL %q", pr.URL)
+	}
+	payload := map[string]string{"title": title, "body": body}
+	if err := githubRequest("PATCH", fmt.Sprintf("/repos/%s/pulls/%d", repo, number), payload, nil); err != nil {
+		return err
+	}
+	return apiApplyPRMeta(repo, number, meta)
+}
+
+// apiApplyPRMeta adds the reviewers, assignees, and labels in meta to a PR.
+// Reviewers named "org/team" are requested as teams, and the assignee
+// "@me" is the token's user, as with gh.
+func apiApplyPRMeta(repo string, number int, meta *prMeta) error {
+	if meta == nil {
+		return nil
+	}
+	if len(meta.labels) > 0 {
+		if err := githubRequest("POST", fmt.Sprintf("/repos/%s/issues/%d/labels", repo, number), map[string][]string{"labels": meta.labels}, nil); err != nil {
+			return err
+		}
+	}
+	if len(meta.assignees) > 0 {
+		var assignees []string
+		for _, assignee := range meta.assignees {
+			if assignee == "@me" {
+				assignee = githubLogin()
+			}
+			assignees = append(assignees, assignee)
+		}
+		if err := githubRequest("POST", fmt.Sprintf("/repos/%s/issues/%d/assignees", repo, number), map[string][]string{"assignees": assignees}, nil); err != nil {
+			return err
+		}
+	}
+	reviewers := meta.reviewers
+	if meta.useSuggested {
+		reviewers = mergeValues(reviewers, meta.suggested)
+	}
+	if len(reviewers) > 0 {
+		payload := map[string][]string{"reviewers": {}, "team_reviewers": {}}
+		for _, reviewer := range reviewers {
+			if _, team, ok := strings.Cut(reviewer, "/"); ok {
+				payload["team_reviewers"] = append(payload["team_reviewers"], team)
+			} else {
+				payload["reviewers"] = append(payload["reviewers"], reviewer)
+			}
+		}
+		if err := githubRequest("POST", fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", repo, number), payload, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apiEnableAutoMerge turns on auto-merge for the PR at prURL. The REST API
+// has no endpoint for it, so this goes through GraphQL.
+func apiEnableAutoMerge(prURL, strategy string) error {
+	repo, number, ok := parsePRURL(prURL)
+	if !ok {
+		return fmt.Errorf("unexpected PR URL %q", prURL)
+	}
+	var pull apiPull
+	if err := githubRequest("GET", fmt.Sprintf("/repos/%s/pulls/%d", repo, number), nil, &pull); err != nil {
+		return err
+	}
+	payload := map[string]any{
+		"query": `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { clientMutationId }
+}`,
+		"variables": map[string]string{"id": pull.NodeID, "method": strings.ToUpper(strategy)},
+	}
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := githubRequest("POST", "/graphql", payload, &result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("enabling auto-merge failed: %s", result.Errors[0].Message)
+	}
+	return nil
+}
+
+// apiFetchIssue reads an issue; pull requests are rejected like gh issue
+// view does
+func apiFetchIssue(repo string, number int) (*githubIssue, error) {
+	var raw struct {
+		Number      int             `json:"number"`
+		Title       string          `json:"title"`
+		Body        string          `json:"body"`
+		State       string          `json:"state"`
+		PullRequest json.RawMessage `json:"pull_request"`
+	}
+	if err := githubRequest("GET", fmt.Sprintf("/repos/%s/issues/%d", repo, number), nil, &raw); err != nil {
+		return nil, err
+	}
+	if raw.PullRequest != nil {
+		return nil, fmt.Errorf("#%d is a pull request", number)
+	}
+	// gh reports states in upper case
+	return &githubIssue{Number: raw.Number, Title: raw.Title, Body: raw.Body, State: strings.ToUpper(raw.State)}, nil
+}
+
+// githubLogin returns the login of the authenticated user, or "" if unknown
+func githubLogin() string {
+	if !useGitHubAPI() {
+		output, err := runCommand(exec.Command("gh", "api", "user", "--jq", ".login"))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := githubRequest("GET", "/user", nil, &user); err != nil {
+		debugf("reading GitHub user: %v", err)
+	}
+	return user.Login
+}
+
+// baseGitHubRepo returns "owner/name" of the base remote's repository, or
+// "" when it isn't on GitHub
+func baseGitHubRepo() string {
+	url, err := remoteURL(baseRemote())
+	if err != nil {
+		return ""
+	}
+	return githubRepo(url)
+}
+
+// prURLPattern matches the repository and number in a PR's web URL
+var prURLPattern = regexp.MustCompile(`github\.com/([^/]+/[^/]+)/pull/([0-9]+)`)
+
+// parsePRURL splits a PR URL into its repository and number
+func parsePRURL(prURL string) (repo string, number int, ok bool) {
+	match := prURLPattern.FindStringSubmatch(prURL)
+	if match == nil {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(match[2])
+	return match[1], number, err == nil
+}
@@ -0,0 +1,179 @@
+// Package gitops runs the git commands a commit message is generated from
+// and committed with: reading the staged diff and files, committing, and
+// pushing. It calls the git executable, as the gitcat command does, so
+// hooks, config, and credentials apply as they would on the command line.
+package gitops
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Repo runs git in a working tree
+type Repo struct {
+	Dir string // Working tree to run in, "" for the current directory
+
+	// Run runs each command and returns its combined output. Nil runs it
+	// with exec.Cmd.CombinedOutput; gitcat sets it to log the commands.
+	Run func(cmd *exec.Cmd) ([]byte, error)
+}
+
+// git runs a git command in the working tree
+func (r Repo) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	if r.Run != nil {
+		return r.Run(cmd)
+	}
+	return cmd.CombinedOutput()
+}
+
+// File is a changed file as git lists it
+type File struct {
+	Status   string // Status letters, e.g. "M" from git diff or " M" and "??" from git status
+	Path     string
+	OrigPath string // Source path for renames and copies
+}
+
+// StagedDiff returns the diff of the staged changes, with CRLF line
+// endings normalized
+func (r Repo) StagedDiff() (string, error) {
+	output, err := r.git("diff", "--staged")
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return normalizeNewlines(string(output)), nil
+}
+
+// StagedDiffStat returns the --stat summary of the staged changes
+func (r Repo) StagedDiffStat() (string, error) {
+	output, err := r.git("diff", "--staged", "--stat")
+	if err != nil {
+		return "", fmt.Errorf("git diff --stat failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// StagedFiles returns the files in the index that differ from HEAD
+func (r Repo) StagedFiles() ([]File, error) {
+	output, err := r.git("diff", "--staged", "--name-status", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+
+	var files []File
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		file := File{Status: status[:1], Path: fields[i+1]}
+		// Renames and copies list the source path before the destination
+		if (status[0] == 'R' || status[0] == 'C') && i+2 < len(fields) {
+			file.OrigPath = fields[i+1]
+			file.Path = fields[i+2]
+			i++
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// CommitOptions are extra git commit switches
+type CommitOptions struct {
+	Signoff    bool     // -s: add a Signed-off-by trailer
+	NoVerify   bool     // --no-verify: skip hooks on commit and push
+	Amend      bool     // --amend --only: replace HEAD's message, leaving staged changes out
+	AllowEmpty bool     // --allow-empty: commit even though nothing is staged
+	Trailers   []string // "Key: value" lines added with --trailer
+}
+
+// Args returns the git commit switches for o
+func (o CommitOptions) Args() []string {
+	var args []string
+	if o.Signoff {
+		args = append(args, "--signoff")
+	}
+	if o.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if o.Amend {
+		args = append(args, "--amend", "--only")
+	}
+	if o.AllowEmpty {
+		args = append(args, "--allow-empty")
+	}
+	for _, trailer := range o.Trailers {
+		args = append(args, "--trailer", trailer)
+	}
+	return args
+}
+
+// CommitError is a failed git commit along with everything it printed,
+// which includes the output of any hooks that ran
+type CommitError struct {
+	Err    error
+	Output string
+}
+
+func (e *CommitError) Error() string {
+	return fmt.Sprintf("git commit failed: %v\n%s", e.Err, e.Output)
+}
+
+func (e *CommitError) Unwrap() error {
+	return e.Err
+}
+
+// normalizeNewlines converts CRLF line endings to LF
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// Commit commits the staged changes with message. A failure is a
+// *CommitError.
+func (r Repo) Commit(message string, opts CommitOptions) error {
+	args := append([]string{"commit", "-m", normalizeNewlines(message)}, opts.Args()...)
+	output, err := r.git(args...)
+	if err != nil {
+		return &CommitError{Err: err, Output: string(output)}
+	}
+	return nil
+}
+
+// PushOptions are extra git push switches
+type PushOptions struct {
+	SetUpstream    bool // --set-upstream: track the remote branch
+	ForceWithLease bool // --force-with-lease: replace the remote branch if it is where it was last fetched
+	NoVerify       bool // --no-verify: skip the pre-push hook
+}
+
+// Push pushes branch to remote. With remote "", the current branch goes to
+// its upstream, as with a plain git push.
+func (r Repo) Push(remote, branch string, opts PushOptions) error {
+	args := []string{"push"}
+	if opts.ForceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	if opts.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	if remote != "" {
+		args = append(args, remote, branch)
+	}
+	if opts.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	output, err := r.git(args...)
+	if err != nil {
+		command := "git push"
+		if opts.ForceWithLease {
+			command += " --force-with-lease"
+		} else if opts.SetUpstream {
+			command += " --set-upstream"
+		}
+		return fmt.Errorf("%s failed: %w\n%s", command, err, string(output))
+	}
+	return nil
+}
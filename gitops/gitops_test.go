@@ -0,0 +1,152 @@
+package gitops
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRepo makes a repository with one commit in a temporary directory
+func testRepo(t *testing.T) Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	r := Repo{Dir: t.TempDir()}
+	run(t, r, "init", "-q")
+	write(t, r, "a.txt", "one\n")
+	write(t, r, "b.txt", "two\n")
+	run(t, r, "add", ".")
+	run(t, r, "commit", "-q", "-m", "initial")
+	return r
+}
+
+func run(t *testing.T, r Repo, args ...string) string {
+	t.Helper()
+	output, err := r.git(args...)
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+func write(t *testing.T, r Repo, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(r.Dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStagedFiles(t *testing.T) {
+	r := testRepo(t)
+	write(t, r, "a.txt", "one\r\nmore\r\n")
+	write(t, r, "c.txt", "new\n")
+	run(t, r, "mv", "b.txt", "renamed.txt")
+	run(t, r, "add", "a.txt", "c.txt")
+
+	files, err := r.StagedFiles()
+	if err != nil {
+		t.Fatalf("StagedFiles() error = %v", err)
+	}
+	want := []File{
+		{Status: "M", Path: "a.txt"},
+		{Status: "A", Path: "c.txt"},
+		{Status: "R", Path: "renamed.txt", OrigPath: "b.txt"},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("StagedFiles() = %+v, want %+v", files, want)
+	}
+
+	diff, err := r.StagedDiff()
+	if err != nil {
+		t.Fatalf("StagedDiff() error = %v", err)
+	}
+	if !strings.Contains(diff, "+more\n") || strings.Contains(diff, "\r") {
+		t.Errorf("StagedDiff() = %q, want the change with LF line endings", diff)
+	}
+	stat, err := r.StagedDiffStat()
+	if err != nil || !strings.Contains(stat, "3 files changed") {
+		t.Errorf("StagedDiffStat() = %q, %v, want 3 files changed", stat, err)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	r := testRepo(t)
+	write(t, r, "a.txt", "changed\n")
+	run(t, r, "add", "a.txt")
+
+	opts := CommitOptions{Signoff: true, Trailers: []string{"Refs: JIRA-12"}}
+	if err := r.Commit("fix: change a\r\n\r\nBody.", opts); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	message := run(t, r, "log", "-1", "--format=%B")
+	want := "fix: change a\n\nBody.\n\nSigned-off-by: Test <test@example.com>\nRefs: JIRA-12"
+	if strings.TrimRight(message, "\n") != strings.TrimRight(want, "\n") {
+		t.Errorf("committed message = %q, want %q", message, want)
+	}
+
+	// Nothing is staged any more
+	err := r.Commit("chore: nothing", CommitOptions{})
+	var commitErr *CommitError
+	if !errors.As(err, &commitErr) || !strings.Contains(commitErr.Output, "nothing") {
+		t.Errorf("Commit() with nothing staged = %v, want a CommitError with git's output", err)
+	}
+	if err := r.Commit("chore: trigger CI", CommitOptions{AllowEmpty: true}); err != nil {
+		t.Errorf("Commit() with AllowEmpty error = %v", err)
+	}
+}
+
+func TestPush(t *testing.T) {
+	r := testRepo(t)
+	remote := t.TempDir()
+	run(t, Repo{Dir: remote}, "init", "-q", "--bare")
+	run(t, r, "remote", "add", "origin", remote)
+	branch := strings.TrimSpace(run(t, r, "branch", "--show-current"))
+
+	if err := r.Push("", branch, PushOptions{}); err == nil {
+		t.Error("Push() without an upstream succeeded, want git's error")
+	}
+	if err := r.Push("origin", branch, PushOptions{SetUpstream: true}); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if upstream := strings.TrimSpace(run(t, r, "rev-parse", "--abbrev-ref", "@{upstream}")); upstream != "origin/"+branch {
+		t.Errorf("upstream = %q, want origin/%s", upstream, branch)
+	}
+
+	run(t, r, "commit", "-q", "--amend", "-m", "rewritten")
+	if err := r.Push("", branch, PushOptions{}); err == nil {
+		t.Error("Push() of a rewritten branch succeeded without forcing")
+	}
+	if err := r.Push("origin", branch, PushOptions{ForceWithLease: true}); err != nil {
+		t.Errorf("Push() with ForceWithLease error = %v", err)
+	}
+}
+
+func TestCommitOptionsArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CommitOptions
+		want []string
+	}{
+		{"none", CommitOptions{}, nil},
+		{"all", CommitOptions{Signoff: true, NoVerify: true, Amend: true, AllowEmpty: true, Trailers: []string{"A: 1", "B: 2"}},
+			[]string{"--signoff", "--no-verify", "--amend", "--only", "--allow-empty", "--trailer", "A: 1", "--trailer", "B: 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Args(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
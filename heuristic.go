@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// maxHeuristicBodyFiles caps the per-file bullet list in offline messages
+const maxHeuristicBodyFiles = 20
+
+// fileChange summarizes what a diff does to one file
+type fileChange struct {
+	action  string // "add", "remove", "rename", or "update"
+	path    string
+	oldPath string // Set for renames
+	added   int
+	removed int
+}
+
+// summarizeChanges classifies each file in a diff from its header lines
+func summarizeChanges(diff string) []fileChange {
+	var changes []fileChange
+	for _, f := range splitDiff(diff) {
+		c := fileChange{action: "update", path: f.path}
+		c.added, c.removed = f.lineStats()
+		for _, line := range strings.Split(f.header, "\n") {
+			switch {
+			case strings.HasPrefix(line, "new file mode"):
+				c.action = "add"
+			case strings.HasPrefix(line, "deleted file mode"):
+				c.action = "remove"
+			case strings.HasPrefix(line, "rename from "):
+				c.action = "rename"
+				c.oldPath = unquoteDiffPath(strings.TrimPrefix(line, "rename from "), "")
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// heuristicCommitMsg builds a conventional commit message from the diff
+// alone, for use when no AI provider is reachable. An empty commitType is
+// guessed from the changed paths.
+func heuristicCommitMsg(diff, commitType, scope string) string {
+	changes := summarizeChanges(diff)
+	if commitType == "" {
+		commitType = heuristicCommitType(changes)
+	}
+
+	prefix := commitType
+	if scope != "" {
+		prefix = fmt.Sprintf("%s(%s)", commitType, scope)
+	}
+	subject := fmt.Sprintf("%s: %s", prefix, heuristicSubject(changes))
+	if len(changes) <= 1 {
+		return subject
+	}
+
+	var body []string
+	for i, c := range changes {
+		if i == maxHeuristicBodyFiles {
+			body = append(body, fmt.Sprintf("- and %d more files", len(changes)-i))
+			break
+		}
+		body = append(body, "- "+describeChange(c))
+	}
+	return subject + "\n\n" + strings.Join(body, "\n")
+}
+
+// buildFiles are manifests and build scripts whose changes make a "build" commit
+var buildFiles = parseIgnorePatterns([]string{
+	"go.mod", "go.sum", "package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+	"Cargo.toml", "Cargo.lock", "pyproject.toml", "requirements*.txt", "poetry.lock", "uv.lock",
+	"Gemfile", "Gemfile.lock", "Makefile", "Dockerfile", "*.gradle", "pom.xml",
+})
+
+// heuristicCommitType guesses the commit type from the changed paths: docs,
+// test, ci, or build when every file is of that kind, otherwise chore
+func heuristicCommitType(changes []fileChange) string {
+	kind := ""
+	for _, c := range changes {
+		k := pathKind(c.path)
+		if kind != "" && k != kind {
+			return "chore"
+		}
+		kind = k
+	}
+	if kind == "" || kind == "other" {
+		return "chore"
+	}
+	return kind
+}
+
+// pathKind classifies a path as docs, test, ci, build, or other
+func pathKind(p string) string {
+	base := path.Base(p)
+	switch {
+	case strings.HasPrefix(p, ".github/workflows/") || strings.HasPrefix(p, ".circleci/") ||
+		base == ".gitlab-ci.yml" || base == ".travis.yml" || base == "Jenkinsfile":
+		return "ci"
+	case strings.Contains(base, "_test.") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(p, "test/") || strings.HasPrefix(p, "tests/") || strings.Contains(p, "/__tests__/"):
+		return "test"
+	case strings.HasPrefix(p, "docs/") || strings.HasPrefix(strings.ToUpper(base), "README") ||
+		strings.HasSuffix(base, ".md") || strings.HasSuffix(base, ".rst") || strings.HasSuffix(base, ".adoc"):
+		return "docs"
+	case buildFiles.match(p):
+		return "build"
+	}
+	return "other"
+}
+
+// heuristicSubject describes the overall change in a few words
+func heuristicSubject(changes []fileChange) string {
+	if len(changes) == 0 {
+		return "update files"
+	}
+	if len(changes) == 1 {
+		if changes[0].action == "update" {
+			return "update " + changes[0].path
+		}
+		return describeChange(changes[0])
+	}
+
+	action := changes[0].action
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if c.action != action {
+			action = "update"
+		}
+		paths = append(paths, c.path)
+	}
+
+	subject := fmt.Sprintf("%s %d files", action, len(changes))
+	if dir := commonDir(paths); dir != "" {
+		subject += " in " + dir
+	}
+	return subject
+}
+
+// describeChange renders a single file change, e.g. "rename a.go to b.go"
+func describeChange(c fileChange) string {
+	switch c.action {
+	case "rename":
+		return fmt.Sprintf("rename %s to %s", c.oldPath, c.path)
+	case "add", "remove":
+		return fmt.Sprintf("%s %s", c.action, c.path)
+	}
+	return fmt.Sprintf("update %s (+%d -%d)", c.path, c.added, c.removed)
+}
+
+// commonDir returns the deepest directory shared by all paths, or "" if
+// they only share the repository root
+func commonDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	common := strings.Split(path.Dir(paths[0]), "/")
+	for _, p := range paths[1:] {
+		parts := strings.Split(path.Dir(p), "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	dir := strings.Join(common, "/")
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/burritocatai/gitcat/gitops"
+)
+
+// maxHookOutputLines caps the hook output shown in the hook_failed phase;
+// the end of the output is kept since that is where tools report failures
+const maxHookOutputLines = 40
+
+// commitHooks are the hooks that run during git commit and can reject it
+var commitHooks = []string{"pre-commit", "prepare-commit-msg", "commit-msg"}
+
+// getHooksDir returns the directory git runs hooks from, honoring
+// core.hooksPath
+func getHooksDir() (string, error) {
+	output, err := runCommand(exec.Command("git", "rev-parse", "--git-path", "hooks"))
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	return filepath.Abs(strings.TrimSpace(string(output)))
+}
+
+// hasCommitHooks reports whether any executable hook runs during git commit.
+// Windows has no executable bit, and git runs any hook file there.
+func hasCommitHooks() bool {
+	dir, err := getHooksDir()
+	if err != nil {
+		return false
+	}
+	for _, hook := range commitHooks {
+		if info, err := os.Stat(filepath.Join(dir, hook)); err == nil && !info.IsDir() && (onWindows || info.Mode()&0111 != 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// hookFailure returns the output of a commit that failed with hooks
+// installed, or false for other failures. git exits with 128 for its own
+// fatal errors (no identity, locked index), which hooks can't cause.
+func hookFailure(err error) (string, bool) {
+	var commitErr *gitops.CommitError
+	if !errors.As(err, &commitErr) {
+		return "", false
+	}
+	var exitErr *exec.ExitError
+	if errors.As(commitErr.Err, &exitErr) && exitErr.ExitCode() == 128 {
+		return "", false
+	}
+	if !hasCommitHooks() {
+		return "", false
+	}
+	return commitErr.Output, true
+}
+
+// tailLines returns the last n lines of text, noting how many were dropped
+func tailLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) <= n {
+		return strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("... %d earlier lines hidden\n%s", len(lines)-n, strings.Join(lines[len(lines)-n:], "\n"))
+}
+
+// enterHookFailedPhase shows the output of the hooks that rejected a commit
+func (m model) enterHookFailedPhase(output string) model {
+	m.phase = "hook_failed"
+	m.hookOutput = output
+	m.cursor = 0
+	m.choices = []string{tr("Fix & retry"), tr("Commit with --no-verify"), tr("Edit message"), tr("Quit (message kept as a draft)")}
+	return m
+}
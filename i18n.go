@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/burritocatai/gitcat/config"
+)
+
+// TUI strings are looked up by their English text, gettext style, so the
+// English source doubles as the fallback for anything a catalog lacks.
+// Built-in catalogs live in locale_*.go; users can add or override one with
+// locales/<lang>.json beside config.json, a JSON object mapping the English
+// strings to translations.
+
+// builtinCatalogs are the translations shipped with gitcat, by language
+var builtinCatalogs = map[string]map[string]string{
+	"es": catalogES,
+}
+
+// activeCatalog holds the translations for the selected language, nil for
+// English
+var activeCatalog map[string]string
+
+// tr translates a TUI string and formats it with args
+func tr(format string, args ...any) string {
+	if translated, ok := activeCatalog[format]; ok && translated != "" {
+		format = translated
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// resolveLanguage picks the UI language: GITCAT_LANG, then the configured
+// language, then the standard locale variables. It returns a language code
+// such as "es", or "en" for English and the C/POSIX locale.
+func resolveLanguage(configured string) string {
+	candidates := []string{os.Getenv("GITCAT_LANG"), configured, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, value := range candidates {
+		if value == "" {
+			continue
+		}
+		// "es_MX.UTF-8" -> "es"
+		lang := strings.ToLower(value)
+		if i := strings.IndexAny(lang, "_.@-"); i >= 0 {
+			lang = lang[:i]
+		}
+		if lang == "c" || lang == "posix" {
+			return "en"
+		}
+		return lang
+	}
+	return "en"
+}
+
+// setLanguage activates the catalog for lang, layering the user's catalog
+// file over the built-in one. Unknown languages fall back to English.
+func setLanguage(lang string) {
+	activeCatalog = nil
+	if lang == "en" {
+		return
+	}
+	catalog := make(map[string]string)
+	for k, v := range builtinCatalogs[lang] {
+		catalog[k] = v
+	}
+	if path, err := getLocalePath(lang); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			var user map[string]string
+			if err := json.Unmarshal(data, &user); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: ignoring %s: %v\n", path, err)
+			}
+			for k, v := range user {
+				catalog[k] = v
+			}
+		}
+	}
+	if len(catalog) == 0 {
+		debugf("no translations for language %q, using English", lang)
+		return
+	}
+	activeCatalog = catalog
+}
+
+// getLocalePath returns the user's catalog file for lang
+func getLocalePath(lang string) (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "locales", lang+".json"), nil
+}
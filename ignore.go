@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const gitcatIgnoreFile = ".gitcatignore"
+
+// defaultPromptExcludes are lockfiles, minified bundles, and vendored code
+// whose diffs are large and carry no signal for a commit message
+var defaultPromptExcludes = []string{
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"bun.lockb",
+	"go.sum",
+	"Cargo.lock",
+	"Gemfile.lock",
+	"composer.lock",
+	"poetry.lock",
+	"Pipfile.lock",
+	"uv.lock",
+	"*.min.js",
+	"*.min.css",
+	"*.map",
+	"vendor/",
+	"node_modules/",
+	"dist/",
+}
+
+// ignorePattern is a single gitignore-style pattern
+type ignorePattern struct {
+	pattern  string
+	negate   bool // Leading "!" re-includes previously matched paths
+	dirOnly  bool // Trailing "/" matches directories only
+	anchored bool // Pattern contains a slash, so it matches from the repo root
+}
+
+// ignoreMatcher applies gitignore-style patterns in order; the last matching
+// pattern wins
+type ignoreMatcher []ignorePattern
+
+// parseIgnorePatterns parses gitignore syntax, skipping blanks and comments
+func parseIgnorePatterns(lines []string) ignoreMatcher {
+	var patterns ignoreMatcher
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p ignorePattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // Escaped leading "!" or "#"
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		p.pattern = line
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// loadIgnoreFile reads gitignore-style patterns from a file. A missing file
+// yields an empty matcher.
+func loadIgnoreFile(filename string) (ignoreMatcher, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return parseIgnorePatterns(lines), nil
+}
+
+// loadGitcatIgnore loads .gitcatignore from the repository root
+func loadGitcatIgnore() ignoreMatcher {
+	root, err := getRepoRoot()
+	if err != nil {
+		return nil
+	}
+	matcher, err := loadIgnoreFile(filepath.Join(root, gitcatIgnoreFile))
+	if err != nil {
+		debugf("failed to read %s: %v", gitcatIgnoreFile, err)
+		return nil
+	}
+	return matcher
+}
+
+// autoExcludeMatcher combines the built-in prompt excludes with the
+// configured ones, so config patterns can re-include defaults with "!"
+func autoExcludeMatcher(config *Config) ignoreMatcher {
+	patterns := append([]string{}, defaultPromptExcludes...)
+	if config != nil {
+		patterns = append(patterns, config.PromptExclude...)
+	}
+	return parseIgnorePatterns(patterns)
+}
+
+// match reports whether the repo-relative path p is matched
+func (im ignoreMatcher) match(p string) bool {
+	segments := strings.Split(p, "/")
+	matched := false
+	for _, pat := range im {
+		if pat.matches(segments) {
+			matched = !pat.negate
+		}
+	}
+	return matched
+}
+
+// matches checks the pattern against the path and each of its parent
+// directories, since ignoring a directory covers everything inside it
+func (p ignorePattern) matches(segments []string) bool {
+	for k := 1; k <= len(segments); k++ {
+		isDir := k < len(segments)
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.anchored {
+			if matchSegments(strings.Split(p.pattern, "/"), segments[:k]) {
+				return true
+			}
+		} else if ok, _ := path.Match(p.pattern, segments[k-1]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches glob segments against path segments, with "**"
+// matching zero or more directories
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestParseIgnorePatterns(t *testing.T) {
+	got := parseIgnorePatterns([]string{
+		"# comment",
+		"",
+		"*.log  ",
+		"!keep.log",
+		`\#literal`,
+		"build/",
+		"/docs/api",
+		"/",
+	})
+	want := []ignorePattern{
+		{pattern: "*.log"},
+		{pattern: "keep.log", negate: true},
+		{pattern: "#literal"},
+		{pattern: "build", dirOnly: true},
+		{pattern: "docs/api", anchored: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseIgnorePatterns() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pattern %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestIgnoreMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{"basename anywhere", []string{"*.log"}, "logs/app/debug.log", true},
+		{"basename no match", []string{"*.log"}, "main.go", false},
+		{"directory contents", []string{"vendor/"}, "vendor/github.com/x/y.go", true},
+		{"nested directory", []string{"node_modules/"}, "web/node_modules/react/index.js", true},
+		{"dir-only skips files", []string{"dist/"}, "dist", false},
+		{"anchored from root", []string{"/docs/api"}, "docs/api/index.md", true},
+		{"anchored not nested", []string{"/docs/api"}, "site/docs/api/index.md", false},
+		{"double star any depth", []string{"**/generated.go"}, "a/b/generated.go", true},
+		{"double star at root", []string{"**/generated.go"}, "generated.go", true},
+		{"double star middle", []string{"api/**/*.pb.go"}, "api/v1/users/user.pb.go", true},
+		{"negation re-includes", []string{"*.lock", "!Cargo.lock"}, "Cargo.lock", false},
+		{"last pattern wins", []string{"!Cargo.lock", "*.lock"}, "Cargo.lock", true},
+		{"no patterns", nil, "main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseIgnorePatterns(tt.patterns).match(tt.path); got != tt.want {
+				t.Errorf("match(%q) with %q = %v, want %v", tt.path, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoExcludeMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude []string
+		path    string
+		want    bool
+	}{
+		{"lockfile", nil, "go.sum", true},
+		{"minified bundle", nil, "static/app.min.js", true},
+		{"source file", nil, "main.go", false},
+		{"configured pattern", []string{"*.snap"}, "ui/__snapshots__/x.snap", true},
+		{"configured re-include", []string{"!go.sum"}, "go.sum", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := autoExcludeMatcher(&Config{PromptExclude: tt.exclude})
+			if got := m.match(tt.path); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
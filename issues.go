@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxLinkedIssues    = 5    // Issues fetched for one PR
+	maxIssueBodyLength = 2000 // Characters of each issue body included in the prompt
+)
+
+// issueRef matches GitHub issue references like #123 and org/repo#123
+var issueRef = regexp.MustCompile(`(?:\b([A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9._-]+))?#([0-9]+)\b`)
+
+// branchIssueRef matches issue numbers in branch names without a "#", as
+// in "fix/123-login" or "issue-123"; dates like "2024-05-01" don't match
+var branchIssueRef = regexp.MustCompile(`(?:^|/)(?:(?:issues?|gh)-)?([0-9]+)(?:-[A-Za-z]|$)`)
+
+// closingRef matches the keywords GitHub uses to close issues on merge,
+// capturing the keyword and the reference
+var closingRef = regexp.MustCompile(`(?i)\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+((?:[A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9._-]+)?#[0-9]+)\b`)
+
+// issueLink is a reference to an issue, in the PR's repository when repo
+// is empty
+type issueLink struct {
+	repo   string // "owner/name" for issues in other repositories
+	number int
+}
+
+// String renders the link as GitHub writes it: #123 or owner/name#123
+func (l issueLink) String() string {
+	return l.repo + "#" + strconv.Itoa(l.number)
+}
+
+// githubIssue is the issue context fetched with gh
+type githubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+
+	link issueLink
+}
+
+// referencedIssues returns the issues mentioned in the branch name and
+// commit log, in order of first appearance
+func referencedIssues(branch, gitLog string) []issueLink {
+	var links []issueLink
+	add := func(repo, number string) bool {
+		n, err := strconv.Atoi(number)
+		link := issueLink{repo: repo, number: n}
+		if err == nil && !slices.Contains(links, link) {
+			links = append(links, link)
+		}
+		return len(links) < maxLinkedIssues
+	}
+	for _, match := range branchIssueRef.FindAllStringSubmatch(branch, -1) {
+		if !add("", match[1]) {
+			return links
+		}
+	}
+	for _, match := range issueRef.FindAllStringSubmatch(branch+"\n"+gitLog, -1) {
+		if !add(match[1], match[2]) {
+			return links
+		}
+	}
+	return links
+}
+
+// fetchIssue reads an issue's title, body, and state with gh, or from the
+// GitHub API without it
+func fetchIssue(link issueLink) (*githubIssue, error) {
+	if host, repo := baseGiteaRepo(); host != nil {
+		if link.repo != "" {
+			repo = link.repo
+		}
+		issue, err := giteaFetchIssue(host, repo, link.number)
+		if err != nil {
+			return nil, err
+		}
+		issue.link = link
+		return issue, nil
+	}
+	if useGitHubAPI() {
+		repo := link.repo
+		if repo == "" {
+			repo = baseGitHubRepo()
+		}
+		issue, err := apiFetchIssue(repo, link.number)
+		if err != nil {
+			return nil, err
+		}
+		issue.link = link
+		return issue, nil
+	}
+	args := []string{"issue", "view", strconv.Itoa(link.number), "--json", "number,title,body,state"}
+	if link.repo != "" {
+		args = append(args, "--repo", link.repo)
+	}
+	output, err := runCommand(exec.Command("gh", args...))
+	if err != nil {
+		return nil, fmt.Errorf("gh issue view failed: %w", err)
+	}
+	issue := githubIssue{link: link}
+	if err := json.Unmarshal(output, &issue); err != nil {
+		return nil, fmt.Errorf("failed to parse gh output: %w", err)
+	}
+	return &issue, nil
+}
+
+// fetchLinkedIssues fetches the referenced issues, skipping references that
+// aren't issues (e.g. pull requests) or can't be read
+func fetchLinkedIssues(links []issueLink) []*githubIssue {
+	var issues []*githubIssue
+	for _, link := range links {
+		issue, err := fetchIssue(link)
+		if err != nil {
+			debugf("skipping %s: %v", link, err)
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// issuePromptContext describes the linked issues for the PR prompt
+func issuePromptContext(issues []*githubIssue) string {
+	if len(issues) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nThe changes reference these GitHub issues:\n")
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "\n%s: %s\n%s\n", issue.link, issue.Title, truncateRunes(strings.TrimSpace(issue.Body), maxIssueBodyLength))
+	}
+	b.WriteString("\nIn the PR body, explain how the changes address these issues, using only what the issues and the git log state.\n")
+	return b.String()
+}
+
+// closingKeywords returns a closing line ("Closes #N", "Fixes org/repo#N")
+// for each open linked issue and for each issue the commits say they close,
+// skipping those the body already closes. The keyword follows the one used
+// in the commits, defaulting to Closes.
+func closingKeywords(body, gitLog string, links []issueLink, issues []*githubIssue) string {
+	closed := make(map[string]bool)
+	for _, match := range closingRef.FindAllStringSubmatch(body, -1) {
+		closed[strings.ToLower(match[2])] = true
+	}
+	keywords := make(map[string]string)
+	for _, match := range closingRef.FindAllStringSubmatch(gitLog, -1) {
+		keyword := "Closes"
+		switch strings.ToLower(match[1][:3]) {
+		case "fix":
+			keyword = "Fixes"
+		case "res":
+			keyword = "Resolves"
+		}
+		if ref := strings.ToLower(match[2]); keywords[ref] == "" {
+			keywords[ref] = keyword
+		}
+	}
+
+	var lines []string
+	for _, link := range links {
+		ref := strings.ToLower(link.String())
+		if closed[ref] {
+			continue
+		}
+		// Issues gh can read must still be open; references gh can't check
+		// are closed only when a commit said so
+		keyword, declared := keywords[ref]
+		if i := slices.IndexFunc(issues, func(issue *githubIssue) bool { return issue.link == link }); i >= 0 {
+			if issues[i].State != "OPEN" {
+				continue
+			}
+		} else if !declared {
+			continue
+		}
+		if keyword == "" {
+			keyword = "Closes"
+		}
+		lines = append(lines, keyword+" "+link.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lintIgnoredSubject matches the messages commitlint skips by default:
+// fixups and squashes for autosquash, and git's own revert and merge
+// subjects
+var lintIgnoredSubject = regexp.MustCompile(`^((fixup|squash|amend)! |Revert "|Merge )`)
+
+// lintRules returns the rules gitcat lint checks: the repository's
+// commitlint config, or the config-conventional rules with this
+// repository's commit types
+func lintRules() *commitlintConfig {
+	if config := loadCommitlintConfig(); config != nil {
+		return config
+	}
+	rules := make(map[string]lintRule, len(conventionalRules))
+	for name, rule := range conventionalRules {
+		rules[name] = rule
+	}
+	var types []string
+	for _, t := range commitTypesFor(appConfig) {
+		types = append(types, t.Name)
+	}
+	value, _ := json.Marshal(types)
+	rules["type-enum"] = lintRule{lintError, false, value}
+	return &commitlintConfig{source: "conventional commits", rules: rules}
+}
+
+// lintRange expands gitcat lint's argument into a revision range: the
+// commits since the default branch by default, and X alone is X..HEAD
+func lintRange(spec string) string {
+	if spec == "" {
+		return defaultBaseRef() + "..HEAD"
+	}
+	if !strings.Contains(spec, "..") {
+		return spec + "..HEAD"
+	}
+	return spec
+}
+
+// lintResult is a commit whose message breaks the rules
+type lintResult struct {
+	sha        string
+	message    string
+	violations []string
+}
+
+// lintCommits checks the message of each commit in rev, oldest first, and
+// returns those that break the rules along with how many were checked.
+// Merge commits are skipped, as are the subjects commitlint ignores.
+func lintCommits(rev string, rules *commitlintConfig, strict bool) ([]lintResult, int, error) {
+	output, err := runCommand(exec.Command("git", "log", "--reverse", "--no-merges", "--format=%H%x00%B%x1e", rev))
+	if err != nil {
+		return nil, 0, fmt.Errorf("git log %s failed: %w\n%s", rev, err, string(output))
+	}
+	var results []lintResult
+	checked := 0
+	for _, record := range strings.Split(string(output), "\x1e") {
+		sha, message, ok := strings.Cut(strings.TrimSpace(record), "\x00")
+		if !ok || lintIgnoredSubject.MatchString(message) {
+			continue
+		}
+		message = strings.TrimSpace(message)
+		checked++
+		violations := rules.validate(message)
+		if strict {
+			violations = append(violations, subjectViolations(message)...)
+		}
+		if len(violations) > 0 {
+			results = append(results, lintResult{sha: sha, message: message, violations: violations})
+		}
+	}
+	return results, checked, nil
+}
+
+// suggestRewrite asks the commit model for a message for the commit's
+// changes that keeps what the old one says and follows the rules it broke
+func suggestRewrite(r lintResult) (string, error) {
+	config := getEffectiveConfig()
+	config.Model = config.GetCommitModel()
+
+	diff, err := promptFilters(config).commitPromptDiff(r.sha, diffDepthFull, diffTokenBudget(config, config.GetCommitParams().MaxTokens), config)
+	// A diff with secrets is left out of the suggestion unless secret_scan
+	// blocks it
+	var secrets diffSecretsError
+	if err != nil && (!errors.As(err, &secrets) || config.SecretScan == secretScanBlock) {
+		return "", err
+	}
+	subject, _, _ := strings.Cut(r.message, "\n")
+	req := commitRequest{
+		Diff:       diff,
+		Types:      commitTypesFor(appConfig),
+		Scope:      messageScope(subject),
+		Breaking:   releaseCommit{message: r.message}.breaking(),
+		Strict:     strictFormatEnabled(),
+		Verbosity:  messageVerbosity(),
+		Glossary:   config.Glossary,
+		Previous:   r.message,
+		Violations: r.violations,
+	}
+	for _, t := range req.Types {
+		if t.Name == messageType(r.message) {
+			req.Type = t
+		}
+	}
+
+	switch msg := generateCommitMsg(context.Background(), req)().(type) {
+	case commitMsgMsg:
+		return strings.TrimSpace(string(msg)), nil
+	case commitMsgErrMsg:
+		return "", fmt.Errorf("%s", msg)
+	default:
+		return "", fmt.Errorf("unexpected response %v", msg)
+	}
+}
+
+// runLint handles gitcat lint [<range>]: it reports the commits in the
+// range whose messages break the rules, with a suggested rewrite for each,
+// and exits with exitLintFailure if there are any
+func runLint(args []string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat lint [OPTIONS] [<range>]")
+		os.Exit(exitError)
+	}
+	spec := ""
+	if len(args) == 1 {
+		spec = args[0]
+	}
+	rev := lintRange(spec)
+	rules := lintRules()
+	results, checked, err := lintCommits(rev, rules, strictFormatEnabled())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	if checked == 0 {
+		fmt.Printf("No commits to check in %s.\n", rev)
+		return
+	}
+
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	for _, r := range results {
+		subject, _, _ := strings.Cut(r.message, "\n")
+		fmt.Printf("%s %.7s %s\n", failStyle.Render("✗"), r.sha, subject)
+		for _, v := range r.violations {
+			fmt.Printf("    - %s\n", v)
+		}
+		if *offlineFlag {
+			continue
+		}
+		suggestion, err := suggestRewrite(r)
+		if err != nil {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("    (no suggestion: %v)", err)))
+			continue
+		}
+		fmt.Println("    Suggested:")
+		fmt.Println(dimStyle.Render("      " + strings.ReplaceAll(suggestion, "\n", "\n      ")))
+	}
+
+	if len(results) == 0 {
+		fmt.Println(okStyle.Render(fmt.Sprintf("✓ All %d commits in %s follow the %s rules.", checked, rev, rules.source)))
+		return
+	}
+	fmt.Printf("\n%d of %d commits in %s break the %s rules.\n", len(results), checked, rev, rules.source)
+	if strings.HasSuffix(rev, "..HEAD") {
+		fmt.Printf("Rewrite them with: gitcat reword %s\n", rev)
+	}
+	os.Exit(exitLintFailure)
+}
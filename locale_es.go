@@ -0,0 +1,330 @@
+package main
+
+// catalogES is the Spanish translation of the TUI
+var catalogES = map[string]string{
+	// Branch protection
+	"⚠️  Warning: You are on a protected branch!":                     "⚠️  Atención: estás en una rama protegida",
+	"Current branch: %s":                                              "Rama actual: %s",
+	"Committing directly to main/master branches is not recommended.": "No se recomienda hacer commit directamente en main/master.",
+	"Would you like to create a new branch instead?":                  "¿Quieres crear una rama nueva?",
+	"Yes, create a new branch":                                        "Sí, crear una rama nueva",
+	"Yes, and move my %d unpushed commits to it (reset %s to %s)":     "Sí, y mover a ella mis %d commits sin subir (restablecer %s a %s)",
+	"%s already has %d commits that aren't on %s.":                    "%s ya tiene %d commits que no están en %s.",
+	"No, continue on %s":                                              "No, continuar en %s",
+	"Enter new branch name:":                                          "Nombre de la rama nueva:",
+	"Suggested: %s":                                                   "Sugerencia: %s",
+	"Tip: Use format like 'feature/description' or 'fix/issue-123'":   "Consejo: usa un formato como 'feature/descripcion' o 'fix/issue-123'",
+	"(type branch name, enter to create, q to quit)":                  "(escribe el nombre, enter para crear, q para salir)",
+	"Creating and switching to branch '%s'...":                        "Creando la rama '%s' y cambiando a ella...",
+	"Can't create this branch":                                        "No se puede crear esta rama",
+	"A branch named '%s' already exists":                              "Ya existe una rama llamada '%s'",
+	"A branch named '%s' already exists on %s":                        "Ya existe una rama llamada '%s' en %s",
+	"'%s' clashes with the existing branch '%s'":                      "'%s' choca con la rama existente '%s'",
+	"'%s' is not a valid branch name: %v":                             "'%s' no es un nombre de rama válido: %v",
+	"Use '%s' instead":                                                "Usar '%s' en su lugar",
+	"Enter a different name":                                          "Escribir otro nombre",
+
+	// File selection
+	"No staged changes found. Select files to stage:":      "No hay cambios preparados. Elige los archivos a preparar:",
+	"%d of %d files selected":                              "%d de %d archivos seleccionados",
+	" (showing ignored files marked !!)":                   " (se muestran los archivos ignorados marcados con !!)",
+	"Select files whose content may be sent to the AI:":    "Elige los archivos cuyo contenido se puede enviar a la IA:",
+	"%d of %d files withheld (path only, still committed)": "%d de %d archivos retenidos (solo la ruta; se incluyen en el commit)",
+	"Select staged files to unstage:":                      "Elige los archivos preparados que quieres quitar:",
+	"%d of %d files will be unstaged":                      "Se quitarán %d de %d archivos",
+	"Select co-authors:":                                   "Elige los coautores:",
+	"(space to toggle, a to toggle all, t for tracked only, i to show/hide ignored files,\n enter to stage selected, q to quit)": "(espacio para marcar, a para marcar todos, t solo seguidos, i para mostrar/ocultar ignorados,\n enter para preparar la selección, q para salir)",
+	"(space to toggle, a to toggle all, enter to confirm, esc to go back)":                                                       "(espacio para marcar, a para marcar todos, enter para confirmar, esc para volver)",
+	"(space to toggle, a to toggle all, enter to unstage selected, esc to go back)":                                              "(espacio para marcar, a para marcar todos, enter para quitar la selección, esc para volver)",
+	"(space to toggle, enter when done, esc to go back)":                                                                         "(espacio para marcar, enter al terminar, esc para volver)",
+
+	// Drafts
+	"Unfinished commit message from a previous run (saved %s):": "Mensaje de commit sin terminar de una ejecución anterior (guardado %s):",
+	"just now":                  "hace un momento",
+	"%dm ago":                   "hace %d min",
+	"%dh ago":                   "hace %d h",
+	"%dd ago":                   "hace %d d",
+	"Resume saved message":      "Retomar el mensaje guardado",
+	"Discard it and start over": "Descartarlo y empezar de nuevo",
+
+	// Type and scope
+	"Select commit type:":                      "Elige el tipo de commit:",
+	"Let the AI choose the type from the diff": "Dejar que la IA elija el tipo a partir del diff",
+	"AI-chosen type":                           "tipo elegido por la IA",
+	"⚠️  Breaking change: the message gets \"!\" and a BREAKING CHANGE footer": "⚠️  Cambio incompatible: el mensaje lleva \"!\" y un pie BREAKING CHANGE",
+	"Co-authors: %d selected": "Coautores: %d seleccionados",
+	"c to pick co-authors, ":  "c para elegir coautores, ",
+	"(use arrow keys to select, enter to confirm, b to toggle breaking change, %su to unstage files, x to exclude files from AI, s to split into several commits, q to quit)": "(flechas para elegir, enter para confirmar, b para marcar cambio incompatible, %su para quitar archivos, x para excluir archivos de la IA, s para dividir en varios commits, q para salir)",
+	"Enter scope for %s (press enter when done):":                                         "Ámbito para %s (pulsa enter al terminar):",
+	"⚠️  Changes span %d packages: %s":                                                    "⚠️  Los cambios abarcan %d paquetes: %s",
+	"Consider splitting them into one commit per package (esc, then u to unstage files).": "Considera separarlos en un commit por paquete (esc y luego u para quitar archivos).",
+	"Suggestions:": "Sugerencias:",
+	"(type a scope or use ↑/↓ to pick a suggestion, enter when done)": "(escribe un ámbito o usa ↑/↓ para elegir una sugerencia, enter al terminar)",
+
+	// Split
+	"Planning commits...":       "Planificando los commits...",
+	"Proposed commits:":         "Commits propuestos:",
+	"Files the plan left out":   "Archivos que el plan dejó fuera",
+	"Failed to plan the split:": "No se pudo planificar la división:",
+	"Commit in %d commits":      "Hacer %d commits",
+	"Regenerate":                "Generar de nuevo",
+	"Regenerate plan":           "Generar otro plan",
+	"Cancel":                    "Cancelar",
+	"Commit %d of %d: %s":       "Commit %d de %d: %s",
+	"in %d commits":             "en %d commits",
+
+	// Secrets and verification
+	"⚠️  Possible secrets in staged changes":                             "⚠️  Posibles secretos en los cambios preparados",
+	"Secret scanning is set to block. Remove the secrets and try again.": "El escaneo de secretos está en modo bloqueo. Elimina los secretos y vuelve a intentarlo.",
+	"These changes would be sent to the AI provider and committed.":      "Estos cambios se enviarían al proveedor de IA y se incluirían en el commit.",
+	"Continue anyway":                          "Continuar de todos modos",
+	"Don't send to AI, enter message manually": "No enviar a la IA, escribir el mensaje a mano",
+	"Abort":                               "Cancelar",
+	"Running verify command...":           "Ejecutando el comando de verificación...",
+	"⚠️  Verify command failed":           "⚠️  Falló el comando de verificación",
+	"Fix the problems above, then retry.": "Corrige los problemas anteriores y vuelve a intentarlo.",
+
+	// Commit message
+	"Generating commit message...":                     "Generando el mensaje de commit...",
+	"Generated commit message:":                        "Mensaje de commit generado:",
+	"Type chosen automatically: %s (%s)":               "Tipo elegido automáticamente: %s (%s)",
+	"The message does not use the type(scope): format": "El mensaje no usa el formato tipo(ámbito):",
+	"The chosen type %q is not in your commit types":   "El tipo elegido %q no está entre tus tipos de commit",
+	"Added on commit:":                                 "Se añade al hacer commit:",
+	"Git hooks will be skipped (--no-verify)":          "Se omitirán los hooks de git (--no-verify)",
+	"Breaks the repository's message rules:":           "Incumple las reglas de mensajes del repositorio:",
+	"Editor failed: %s":                                "Falló el editor: %s",
+	"Use this message?":                                "¿Usar este mensaje?",
+	"Yes, commit":                                      "Sí, hacer commit",
+	"No, let me edit":                                  "No, quiero editarlo",
+	"Edit in $EDITOR":                                  "Editar en $EDITOR",
+	"Edit inline":                                      "Editar aquí",
+	"Edit commit message (press enter when done):":     "Edita el mensaje de commit (pulsa enter al terminar):",
+	"(ctrl+e to open in $EDITOR)":                      "(ctrl+e para abrir en $EDITOR)",
+	"⚠️  Large diff detected":                          "⚠️  Diff demasiado grande",
+	"The diff is too large to fit the model's context, even when truncated.": "El diff no cabe en el contexto del modelo, ni siquiera recortado.",
+	"Please enter your commit message manually:":                             "Escribe el mensaje de commit a mano:",
+	"Tip: Follow conventional commits format":                                "Consejo: sigue el formato de Conventional Commits",
+	"(type your message, press enter when done, ctrl+e to open in $EDITOR)":  "(escribe el mensaje, pulsa enter al terminar, ctrl+e para abrir en $EDITOR)",
+
+	// Errors and hooks
+	"⚠️  API Error":                      "⚠️  Error de la API",
+	"Failed to generate commit message:": "No se pudo generar el mensaje de commit:",
+	"Failed to generate PR content:":     "No se pudo generar el contenido del PR:",
+	"What would you like to do?":         "¿Qué quieres hacer?",
+	"Retry":                              "Reintentar",
+	"Generate offline (no AI)":           "Generar sin conexión (sin IA)",
+	"Enter commit message manually":      "Escribir el mensaje de commit a mano",
+	"Enter PR details manually":          "Escribir los datos del PR a mano",
+	"Skip PR creation":                   "No crear el PR",
+	"⚠️  Commit rejected by hooks":       "⚠️  Los hooks rechazaron el commit",
+	"Fix the problems above (restage any files the hooks changed), then retry.": "Corrige los problemas anteriores (vuelve a preparar los archivos que cambiaron los hooks) y reintenta.",
+	"Fix & retry":                    "Corregir y reintentar",
+	"Commit with --no-verify":        "Hacer commit con --no-verify",
+	"Edit message":                   "Editar el mensaje",
+	"Quit (message kept as a draft)": "Salir (el mensaje se guarda como borrador)",
+	"Quit":                           "Salir",
+	"Error: %s":                      "Error: %s",
+
+	// Reword
+	"Current message:":                       "Mensaje actual:",
+	"Keep current message":                   "Mantener el mensaje actual",
+	"Reworded %d of %d commits on branch %s": "Mensaje cambiado en %d de %d commits en la rama %s",
+	"Yes, reword":                            "Sí, cambiar el mensaje",
+	"Reworded the last commit on branch %s":  "Mensaje del último commit cambiado en la rama %s",
+	"⚠️  This commit is already pushed; rewording it rewrites published history":             "⚠️  Este commit ya está publicado; cambiar su mensaje reescribe el historial publicado",
+	"(use arrow keys to select, enter to confirm, b to toggle breaking change, %sq to quit)": "(flechas para elegir, enter para confirmar, b para marcar cambio incompatible, %sq para salir)",
+
+	// Revert
+	"Reverting:": "Revirtiendo:",
+	"Why is this commit being reverted? (press enter when done):": "¿Por qué se revierte este commit? (pulsa enter al terminar):",
+	"Leave it empty to let the AI work it out from the changes.":  "Déjalo vacío para que la IA lo deduzca de los cambios.",
+	"Reverted %s on branch %s":                                    "%s revertido en la rama %s",
+
+	// Empty commits
+	"What is this empty commit for? (press enter when done):":    "¿Para qué es este commit vacío? (pulsa enter al terminar):",
+	"For example: trigger a CI rebuild after the runner upgrade": "Por ejemplo: relanzar la CI tras actualizar el runner",
+	"Made an empty commit": "Commit vacío creado",
+
+	// Unstaged changes
+	"You also have unstaged changes.": "También tienes cambios sin preparar.",
+	"Stash them while committing so the verify command and git hooks see exactly what will be committed? They are restored afterwards.": "¿Guardarlos en el stash durante el commit para que el comando de verificación y los hooks de git vean exactamente lo que se va a confirmar? Se restauran después.",
+	"Yes, stash them until the commit is made": "Sí, guardarlos hasta hacer el commit",
+	"No, leave them in place":                  "No, dejarlos como están",
+
+	// Merges
+	"Committed the merge on branch %s": "Merge confirmado en la rama %s",
+
+	// Stage scope
+	"Showing changes under %s only (stage_scope is \"cwd\")": "Solo se muestran los cambios en %s (stage_scope es \"cwd\")",
+
+	// Squash
+	"Squashing %d commits:": "Combinando %d commits:",
+	"Yes, squash":           "Sí, combinar",
+	"Squashed %d commits into one on branch %s": "%d commits combinados en uno en la rama %s",
+	"Squash into one commit, then create PR":    "Combinar en un solo commit y crear el PR",
+	"Yes, update PR #%d":                        "Sí, actualizar el PR #%d",
+	"Squash into one commit, then update PR":    "Combinar en un solo commit y actualizar el PR",
+	"PR #%d is already open for this branch. Update its title and description from the latest commits?": "El PR #%d ya está abierto para esta rama. ¿Actualizar su título y descripción a partir de los últimos commits?",
+	"⚠️  These commits are already pushed; squashing them rewrites published history":                   "⚠️  Estos commits ya están publicados; combinarlos reescribe el historial publicado",
+
+	// Push and PR
+	"Uncommitted changes remain.":           "Quedan cambios sin commit.",
+	"Stage more changes for another commit": "Preparar más cambios para otro commit",
+	"Done, continue":                        "Listo, continuar",
+	"✓ Commit created successfully!":        "✓ Commit creado correctamente",
+	"Push to remote?":                       "¿Hacer push al remoto?",
+	"Yes, push":                             "Sí, hacer push",
+	"No, skip":                              "No, omitir",
+	"No upstream branch configured.":        "No hay rama upstream configurada.",
+	"Set upstream to '%s/%s' and push?":     "¿Configurar el upstream como '%s/%s' y hacer push?",
+	"Push %s to which remote?":              "¿A qué remoto hacer push de %s?",
+	"Yes, push to %s":                       "Sí, hacer push a %s",
+	"Yes, set upstream and push":            "Sí, configurar el upstream y hacer push",
+	"Create a pull request?":                "¿Crear un pull request?",
+	"Yes, create PR":                        "Sí, crear el PR",
+	"Generating PR title and body...":       "Generando el título y la descripción del PR...",
+	"Enter PR title:":                       "Título del PR:",
+	"(%d/%d characters)":                    "(%d/%d caracteres)",
+	"⚠️  Not in type(scope): subject format, which this repository's PR checks expect":  "⚠️  No sigue el formato tipo(ámbito): asunto que esperan las comprobaciones de PR de este repositorio",
+	"(edit the title, press enter to return to the preview, ctrl+e to open in $EDITOR)": "(edita el título, enter para volver a la vista previa, ctrl+e para abrirlo en $EDITOR)",
+	"(type your title, press enter to continue to body, ctrl+e to open in $EDITOR)":     "(escribe el título, pulsa enter para pasar a la descripción, ctrl+e para abrir en $EDITOR)",
+	"Enter PR body:": "Descripción del PR:",
+	"Title: %s":      "Título: %s",
+	"Tip: Describe your changes, press enter for newlines":                       "Consejo: describe tus cambios, pulsa enter para saltos de línea",
+	"(type your body, press enter twice to continue, ctrl+e to open in $EDITOR)": "(escribe la descripción, pulsa enter dos veces para continuar, ctrl+e para abrir en $EDITOR)",
+	"PR Preview":      "Vista previa del PR",
+	"Title: ":         "Título: ",
+	"Body:":           "Descripción:",
+	"Create this PR?": "¿Crear este PR?",
+	"Replace the title and description of PR #%d?": "¿Reemplazar el título y la descripción del PR #%d?",
+	"Reviewers:":                    "Revisores:",
+	"Assignees:":                    "Asignados:",
+	"Labels:":                       "Etiquetas:",
+	"Auto-merge:":                   "Fusión automática:",
+	"%s, once required checks pass": "%s, cuando pasen las comprobaciones requeridas",
+	"⚠️  Could not enable auto-merge: %s":                                "⚠️  No se pudo activar la fusión automática: %s",
+	"Auto-merge (%s) is on; the PR merges once its required checks pass": "Fusión automática (%s) activada; el PR se fusionará cuando pasen sus comprobaciones requeridas",
+	"(suggested from CODEOWNERS)":                                        "(sugeridos por CODEOWNERS)",
+	"%s (suggested from CODEOWNERS, not requested)":                      "%s (sugeridos por CODEOWNERS, sin solicitar)",
+	"(use arrow keys to select, enter to confirm, b to pick the commits, r to toggle suggested reviewers, q to quit)": "(flechas para elegir, enter para confirmar, b para elegir los commits, r para activar o quitar los revisores sugeridos, q para salir)",
+	"(use arrow keys to select, enter to confirm, b to pick the commits, q to quit)":                                  "(flechas para elegir, enter para confirmar, b para elegir los commits, q para salir)",
+	"Generate the PR from which commits?": "¿A partir de qué commits generar el PR?",
+	"All commits since %s":                "Todos los commits desde %s",
+	"From %s":                             "Desde %s",
+	"Edit title":                          "Editar el título",
+	"Edit body":                           "Editar la descripción",
+	"Skip":                                "Omitir",
+
+	// Summary
+	"Created PR on branch %s":                    "PR creado en la rama %s",
+	"Updated PR #%d on branch %s":                "PR #%d actualizado en la rama %s",
+	"Committed %d file":                          "%d archivo en el commit",
+	"Committed %d files":                         "%d archivos en el commit",
+	"to new branch %s":                           "en la rama nueva %s",
+	"(moving %d earlier commits off %s)":         "(moviendo %d commits anteriores fuera de %s)",
+	"to branch %s":                               "en la rama %s",
+	"and pushed":                                 "y push hecho",
+	"and pushed for review":                      "y enviado a revisión",
+	"Yes, push for review (%s HEAD:refs/for/%s)": "Sí, enviar a revisión (%s HEAD:refs/for/%s)",
+	"and created PR":                             "y PR creado",
+	"and updated PR #%d":                         "y PR #%d actualizado",
+	"⚠️  Git hooks were skipped (--no-verify)":   "⚠️  Se omitieron los hooks de git (--no-verify)",
+
+	// Navigation hints
+	"(use arrow keys to select, enter to confirm, q to quit)":                 "(flechas para elegir, enter para confirmar, q para salir)",
+	"(use arrow keys to select, enter to confirm, esc to go back)":            "(flechas para elegir, enter para confirmar, esc para volver)",
+	"(use arrow keys to select, enter to confirm, esc to go back, q to quit)": "(flechas para elegir, enter para confirmar, esc para volver, q para salir)",
+	"(esc to go back, q to quit)":                                             "(esc para volver, q para salir)",
+
+	// Config TUI
+	"Error saving configuration":          "Error al guardar la configuración",
+	"Press enter to exit or esc to quit":  "Pulsa enter para salir o esc para abandonar",
+	"✓ Configuration saved successfully!": "✓ Configuración guardada correctamente",
+	"Provider:":                           "Proveedor:",
+	"Commit model:":                       "Modelo para commits:",
+	"PR model:":                           "Modelo para PRs:",
+	"Ollama URL:":                         "URL de Ollama:",
+	"OpenAI URL:":                         "URL de OpenAI:",
+	"OpenAI API Key:":                     "Clave de API de OpenAI:",
+	"configured":                          "configurada",
+	"(from OPENAI_API_KEY env var)":       "(de la variable OPENAI_API_KEY)",
+	"Config file:":                        "Archivo de configuración:",
+	"Press enter to exit":                 "Pulsa enter para salir",
+	"Select LLM Provider":                 "Elige el proveedor de LLM",
+	"(press 1 for anthropic, 2 for ollama, 3 for openai, enter to continue)": "(pulsa 1 para anthropic, 2 para ollama, 3 para openai, enter para continuar)",
+	"Configure Commit Model": "Modelo para commits",
+	"Enter model for commit message generation (fast model recommended):": "Modelo para generar mensajes de commit (se recomienda uno rápido):",
+	"Default: %s":             "Predeterminado: %s",
+	"(press enter when done)": "(pulsa enter al terminar)",
+	"Configure PR Model":      "Modelo para PRs",
+	"Enter model for PR description generation (smarter model recommended):": "Modelo para generar descripciones de PR (se recomienda uno más capaz):",
+	"Configure Ollama Server URL":                                            "URL del servidor de Ollama",
+	"Enter Ollama server URL:":                                               "URL del servidor de Ollama:",
+	"Configure OpenAI-compatible Endpoint URL":                               "URL del endpoint compatible con OpenAI",
+	"Enter endpoint base URL (e.g. http://localhost:4000):":                  "URL base del endpoint (p. ej. http://localhost:4000):",
+	"Configure OpenAI-compatible API Key":                                    "Clave de API compatible con OpenAI",
+	"Endpoint URL:":                                                          "URL del endpoint:",
+	"Enter API key (or leave empty to use OPENAI_API_KEY env var):":          "Clave de API (déjala vacía para usar la variable OPENAI_API_KEY):",
+	"Confirm Configuration":                                                  "Confirmar la configuración",
+	"Save this configuration?":                                               "¿Guardar esta configuración?",
+	"  [y] Yes, save":                                                        "  [y] Sí, guardar",
+	"  [n] No, cancel":                                                       "  [n] No, cancelar",
+	"(press y to save, n to cancel, esc to quit)":                            "(pulsa y para guardar, n para cancelar, esc para salir)",
+
+	// gitcat release
+	"Generating release notes for %s...":                          "Generando las notas de la versión %s...",
+	"Failed to generate release notes:":                           "No se pudieron generar las notas de la versión:",
+	"Use the commit subjects instead":                             "Usar los asuntos de los commits",
+	"Release notes for %s":                                        "Notas de la versión %s",
+	"%d commits since %s":                                         "%d commits desde %s",
+	"Create tag %s and a GitHub release on %s":                    "Crear la etiqueta %s y una versión de GitHub en %s",
+	"Create tag %s only":                                          "Crear solo la etiqueta %s",
+	"Print the notes and exit":                                    "Mostrar las notas y salir",
+	"Creating tag %s...":                                          "Creando la etiqueta %s...",
+	"✓ Tagged %s":                                                 "✓ Etiqueta %s creada",
+	"✓ Tagged %s, pushed it to %s, and published the release: %s": "✓ Etiqueta %s creada, enviada a %s y versión publicada: %s",
+
+	// gitcat review
+	"Reviewing the staged changes...":      "Revisando los cambios preparados...",
+	"Review of the staged changes":         "Revisión de los cambios preparados",
+	"Failed to review the staged changes:": "No se pudieron revisar los cambios preparados:",
+	"Continue to commit":                   "Continuar con el commit",
+	"Quit to address the review":           "Salir para atender la revisión",
+	"Continue to commit without a review":  "Continuar con el commit sin revisión",
+
+	// First-run setup
+	"Welcome to gitcat": "Bienvenido a gitcat",
+	"There is no config file yet, so let's set up the AI provider gitcat writes messages with. Nothing is saved until the end.": "Todavía no hay archivo de configuración, así que configuremos el proveedor de IA con el que gitcat escribe los mensajes. No se guarda nada hasta el final.",
+	"Claude models, with ANTHROPIC_API_KEY":                     "Modelos Claude, con ANTHROPIC_API_KEY",
+	"Open-source models on a local Ollama server":               "Modelos de código abierto en un servidor Ollama local",
+	"An OpenAI-compatible API, e.g. OpenAI or a LiteLLM proxy":  "Una API compatible con OpenAI, p. ej. OpenAI o un proxy LiteLLM",
+	"(use arrow keys to select, enter to confirm, esc to quit)": "(usa las flechas para elegir, enter para confirmar, esc para salir)",
+	"Connecting to %s...":                                       "Conectando con %s...",
+	"⚠️  Could not connect to %s":                               "⚠️  No se pudo conectar con %s",
+	"Change provider settings":                                  "Cambiar la configuración del proveedor",
+	"Type model names instead":                                  "Escribir los nombres de los modelos",
+	"Quit without saving":                                       "Salir sin guardar",
+	"%d of %d models":                                           "%d de %d modelos",
+	"Testing a sample commit message...":                        "Probando un mensaje de commit de ejemplo...",
+	"✓ %s is working. A sample commit message:":                 "✓ %s funciona. Un mensaje de commit de ejemplo:",
+	"The sample generation failed:":                             "Falló la generación de ejemplo:",
+	"Save and continue":                                         "Guardar y continuar",
+	"Pick other models":                                         "Elegir otros modelos",
+	"Save anyway":                                               "Guardar de todos modos",
+
+	// Commit message candidates
+	"Pick a commit message:":  "Elige un mensaje de commit:",
+	"  %d more generating...": "  %d más generándose...",
+	"  %d failed: %s":         "  %d fallaron: %s",
+
+	// Request progress
+	"%s via %s · %s · esc to cancel": "%s vía %s · %s · esc para cancelar",
+	"Cancelled.":                     "Cancelado.",
+
+	// Notes on what the prompt carries
+	"Regenerating: the message broke %d rule(s)":                                "Regenerando: el mensaje incumplía %d regla(s)",
+	"Large diff: sending file stats and the first lines of each file's changes": "Diff grande: se envían las estadísticas de los archivos y las primeras líneas de los cambios de cada uno",
+	"The diff is too large; planning from file names only":                      "El diff es demasiado grande; se planifica solo a partir de los nombres de archivo",
+	"Possible secrets in the diff; planning from file names only":               "Posibles secretos en el diff; se planifica solo a partir de los nombres de archivo",
+}
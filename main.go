@@ -1,61 +1,42 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-)
 
-const (
-	defaultAnthropicModel = "claude-sonnet-4-5-20250929"
-	defaultOllamaModel    = "llama3.2"
-	defaultOpenAIModel    = "gpt-4o"
-	defaultOllamaURL      = "http://localhost:11434"
-	anthropicURL          = "https://api.anthropic.com/v1/messages"
-	diffLineSizeLimit     = 1000 // Skip AI generation for diffs larger than this
-	prTitleMaxLen         = 256  // Maximum PR title length allowed by GitHub
+	"github.com/burritocatai/gitcat/config"
+	"github.com/burritocatai/gitcat/generate"
+	"github.com/burritocatai/gitcat/gitops"
 )
 
-// Config represents the application configuration
-type Config struct {
-	Provider    string `json:"provider"`               // "anthropic", "ollama", or "openai"
-	Model       string `json:"model"`                  // Default model name (fallback)
-	CommitModel string `json:"commit_model,omitempty"` // Model for commit message generation
-	PRModel     string `json:"pr_model,omitempty"`     // Model for PR description generation
-	OllamaURL   string `json:"ollama_url"`             // Ollama server URL
-	OpenAIURL   string `json:"openai_url,omitempty"`   // OpenAI-compatible endpoint URL
-	OpenAIAPIKey string `json:"openai_api_key,omitempty"` // OpenAI-compatible API key
-}
-
-// GetCommitModel returns the model to use for commit message generation.
-// Falls back to the default Model if CommitModel is not set.
-func (c *Config) GetCommitModel() string {
-	if c.CommitModel != "" {
-		return c.CommitModel
-	}
-	return c.Model
-}
+const prTitleMaxLen = 256 // Maximum PR title length allowed by GitHub
 
-// GetPRModel returns the model to use for PR description generation.
-// Falls back to the default Model if PRModel is not set.
-func (c *Config) GetPRModel() string {
-	if c.PRModel != "" {
-		return c.PRModel
-	}
-	return c.Model
-}
+// Exit codes let wrappers and CI branch on the outcome of a run
+const (
+	exitOK              = 0 // Completed normally
+	exitError           = 1 // Usage error or unexpected failure (flags, branch name, config, TUI)
+	exitNothingToCommit = 2 // No changes found in the working tree
+	exitUserAborted     = 3 // User quit before the flow completed
+	exitAPIFailure      = 4 // LLM provider request failed
+	exitGitFailure      = 5 // A git command failed
+	exitGHFailure       = 6 // PR creation or GitHub checks failed
+	exitVerifyFailure   = 7 // The configured verify command failed
+	exitLintFailure     = 8 // gitcat lint found messages that break the rules
+)
 
 var (
 	modelFlag       = flag.String("model", "", "Model to use for both commit and PR (overrides config)")
@@ -66,273 +47,689 @@ var (
 	pFlag           = flag.String("p", "", "LLM provider (shorthand, overrides config)")
 	ollamaURLFlag   = flag.String("ollama-url", "", "Ollama server URL (overrides config)")
 	openaiURLFlag   = flag.String("openai-url", "", "OpenAI-compatible endpoint URL (overrides config)")
-	openaiAPIKeyFlag = flag.String("openai-api-key", "", "OpenAI-compatible API key (overrides config)")
-	prFlag          = flag.Bool("pr", false, "Generate a PR from existing commits without committing")
+	openaiAPIKeyFlag  = flag.String("openai-api-key", "", "OpenAI-compatible API key (overrides config)")
+	prFlag            = flag.Bool("pr", false, "Generate a PR from existing commits without committing (same as gitcat pr)")
+	squashFlag        = flag.Bool("squash", false, "With gitcat pr, squash the branch into one commit before creating the PR")
+	baseFlag          = flag.String("base", "", "With gitcat pr, generate the PR from the commits since this ref instead of the default branch")
+	prDiffFlag        = flag.String("pr-diff", "", "Send the branch diff with the PR prompt: full, hunks, stat, or none (overrides config)")
+	debugFlag         = flag.Bool("debug", false, "Write debug logs to the state directory (also GITCAT_DEBUG=1)")
+	maxDiffLinesFlag  = flag.Int("max-diff-lines", 0, "Line limit for the diff sent to the model, -1 for none (overrides config)")
+	offlineFlag       = flag.Bool("offline", false, "Build the commit message from the diff without contacting an AI provider")
+	reuseLastFlag     = flag.Bool("reuse-last", false, "Skip generation and reuse the last unfinished message for this branch")
+	autoTypeFlag      = flag.Bool("auto-type", false, "Skip the type picker and let the AI choose the commit type")
+	typeFlag          = flag.String("type", "", "Commit type, skipping the type picker")
+	scopeFlag         = flag.String("scope", "", "Commit scope, skipping the scope input")
+	addAllFlag        = flag.Bool("add-all", false, "When nothing is staged, stage every changed file without asking")
+	branchFlag        = flag.String("branch", "", "Commit on a new branch with this name, created from the current one")
+	pushFlag          = flag.Bool("push", false, "Push the branch after committing without asking")
+	createPRFlag      = flag.Bool("create-pr", false, "Push the branch and create the PR after committing without asking")
+	yesFlag           = flag.Bool("yes", false, "Accept the default answer to every prompt, for unattended runs")
+	breakingFlag      = flag.Bool("breaking", false, "Mark the commit as a breaking change (type!: and a BREAKING CHANGE footer)")
+	signoffFlag       = flag.Bool("signoff", false, "Add a Signed-off-by trailer (git commit -s)")
+	noVerifyFlag      = flag.Bool("no-verify", false, "Skip pre-commit, commit-msg, and pre-push hooks (git --no-verify)")
+	allowEmptyFlag    = flag.Bool("allow-empty", false, "Make an empty commit when nothing is staged (e.g. to trigger CI), asking what it is for")
+	loopFlag          = flag.Bool("loop", false, "After each commit, go back to staging while uncommitted changes remain")
+	notesFlag         = flag.Bool("notes", false, "Attach an AI explanation of each new commit as a git note (refs/notes/gitcat)")
+	candidatesFlag    = flag.Int("candidates", 0, "Generate this many commit messages at once and pick one (overrides config)")
+	verbosityFlag     = flag.String("verbosity", "", "How much body generated messages get: terse, standard, or detailed (overrides config)")
+	ticketFlag        = flag.String("ticket", "", "Ticket ID for the commit footer and PR, in place of the one in the branch name")
+	coAuthorFlags     = stringListVar("co-author", "Add a Co-authored-by trailer: \"Name <email>\" or part of a configured co_authors entry (repeatable)")
+	reviewerFlags     = stringListVar("reviewer", "Request a review on the created PR from a user or org/team (repeatable)")
+	assigneeFlags     = stringListVar("assignee", "Assign the created PR to a user, or @me (repeatable)")
+	labelFlags        = stringListVar("label", "Add a label to the created PR (repeatable)")
+	autoMergeFlag     = flag.Bool("auto-merge", false, "Enable auto-merge on the created PR so it merges once required checks pass")
+	mergeStrategyFlag = flag.String("merge-strategy", "", "Enable auto-merge with this strategy: merge, squash, or rebase (overrides config)")
+	pushRemoteFlag    = flag.String("push-remote", "", "Remote to push the branch to (overrides config)")
+	prRemoteFlag      = flag.String("pr-remote", "", "Remote whose repository receives the PR, when it isn't the push remote (overrides config)")
+	sinceFlag         = flag.String("since", "yesterday", "With standup, summarize the commits made since this date (git log --since)")
+	authorFlag        = flag.String("author", "me", "With standup, summarize the commits of this author (git log --author), me for your git user.email")
 	appConfig       *Config
-)
-
-type AnthropicRequest struct {
-	Model     string    `json:"model"`
-	MaxTokens int       `json:"max_tokens"`
-	Messages  []Message `json:"messages"`
-}
 
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
+	// The directory gitcat was started in, relative to the repository root
+	// ("" at the root, otherwise ending in "/")
+	startDir string
+)
 
-type AnthropicResponse struct {
-	Content []ContentBlock `json:"content"`
-}
+type model struct {
+	choices           []string
+	cursor            int
+	selected          map[int]struct{}    // Files chosen for staging in the add phase
+	files             []changedFile       // Changed files listed in the add phase
+	showIgnored       bool                // Add phase also lists ignored files for review
+	promptExcluded    map[string]struct{} // Paths whose content is withheld from the LLM
+	promptIgnore      ignoreMatcher       // Patterns from .gitcatignore
+	promptAutoExclude ignoreMatcher       // Lockfile/generated defaults plus config patterns
+	promptPathsOnly   ignoreMatcher       // Sensitive paths sent as filename and stats only
+	promptRedactions  []redaction         // Configured regex masks for the prompt diff
+
+	// Shown while generating when the prompt carries a reduced diff
+	promptNote string
+
+	// Secret scan results for the staged diff
+	secretFindings      []secretFinding
+	secretsAcknowledged bool // User chose to continue despite findings
+	commitTypes         []CommitType
+	typeSelected        int
+	scopeInput          string
+	phase               string
+	diff                string
+	needsAdd            bool
+	generatedMsg        string
+	errorMsg            string
+	currentBranch       string
+	prTitle             string
+	prBody              string
+	prMeta              *prMeta     // Reviewers, assignees, and labels for the PR, loaded on first preview
+	prTarget            *prTarget   // Upstream repository for PRs from a fork, resolved before checking for an existing PR
+	upstreamRemotes     []string    // Remotes offered on upstream_prompt, one per choice before "No, skip"
+	existingPR          *existingPR // Open PR for the branch, updated rather than created
+	prBase              string      // Ref the PR is generated from the commits since, "" for the default branch
+	prPrefetch          *prPrefetch // PR content generated in the background after the last commit
+	prEditReturn        bool        // The title or body input was opened from the preview and returns to it
+	prBaseRefs          []string    // Refs offered on pr_base, one per choice
+	conventionalPRTitle bool        // The PR title must be type(scope): subject
+	autoMerge           string      // Auto-merge strategy for the created PR, "" when off
+	autoMergeErr        string      // Why auto-merge couldn't be enabled
+	isProtectedBranch   bool        // Track if on main/master
+	branchInput         string      // User input for branch name
+	suggestedBranch     string      // Branch name from branch_template, the initial input
+	branchProblem       string      // Why the entered branch name can't be created
+	branchAlternative   string      // A name that can be created instead, if any
+
+	// Commits on the protected branch that its upstream lacks, which can be
+	// moved to the new branch; movedFrom is the branch they were moved off
+	protectedUpstream string
+	unpushedCommits   int
+	moveCommits       bool
+	movedFrom         string
 
-type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
+	// Tracking completed actions for exit summary
+	filesCommitted  int
+	didCommit       bool
+	didPush         bool
+	didCreatePR     bool
+	didUpdatePR    bool
+	createdBranch   string // Non-empty if a new branch was created
 
-// OpenAI-compatible API types (for LiteLLM and similar proxies)
-type OpenAIRequest struct {
-	Model     string          `json:"model"`
-	Messages  []OpenAIMessage `json:"messages"`
-	MaxTokens int             `json:"max_tokens"`
-}
+	// API error context for retry capability
+	apiErrorMsg string // Stores the API error message to display
 
-type OpenAIMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
+	// PR-only mode (gitcat pr)
+	prOnly bool
 
-type OpenAIResponse struct {
-	Choices []OpenAIChoice `json:"choices"`
+	// Process exit code reported once the TUI quits
+	exitCode int
+
+	// Terminal width from the last tea.WindowSizeMsg (0 until known)
+	width int
+
+	// Unfinished message from an earlier run, offered in the resume_draft phase
+	savedDraft *draft
+	drafts     draftStore
+
+	// Error from the last $EDITOR session, shown until the next attempt
+	editorErr string
+
+	// Commit is a breaking change (toggled with b on the type screen)
+	breaking bool
+
+	// Extra git commit switches, the identity a sign-off will name, and the
+	// Change-Id offered to trailer templates
+	commitOpts     commitOptions
+	committerIdent string
+	changeID       string
+
+	// Where commits are pushed for review in a Gerrit project (nil
+	// otherwise), and the changes Gerrit reported for the push
+	gerrit        *gerritReview
+	gerritChanges []string
+
+	// Output of the hooks that rejected the last commit attempt, and whether
+	// the user then committed with --no-verify
+	hookOutput   string
+	skippedHooks bool
+
+	// Check run before generation (see getVerifyCommand) and its progress
+	verifyCommand string
+	verifyOutput  string
+	verified      bool
+
+	// The API request the waiting screens show a spinner for and esc
+	// cancels, the count of requests shown so far, and the spinner frame
+	pending      *pendingRequest
+	requestSeq   int
+	spinnerFrame int
+
+	// Repository commitlint rules, nil if there are none, and how many times
+	// the current message was regenerated to satisfy them
+	commitlint  *commitlintConfig
+	lintRetries int
+	strict5072  bool   // Enforce the 50/72 rule, checked along with commitlint
+	verbosity   string // How much body generated messages get
+
+	// Recent commit messages the prompt asks the model to imitate
+	styleExamples []string
+	exemplars     []string // Messages of the commits chosen as the repository's standard
+
+	// Messages generated concurrently to pick from: those that arrived, why
+	// others failed, how many are still running, and the current round so
+	// late results of an earlier one are dropped
+	candidates        []candidate
+	candidateErrs     []string
+	candidatesPending int
+	candidateRound    int
+
+	// Co-authors offered in the coauthors phase and those chosen
+	coAuthors         []string
+	coAuthorsSelected map[string]bool
+
+	// Scope suggestions cycled into the scope input with the arrow keys
+	scopeSuggestions []string
+	// Monorepo packages touched by the staged files, warned about when
+	// there is more than one
+	scopePackages []string
+
+	// Split mode: the groups the model proposed, the staged tree saved when
+	// the split started ("" outside a split), and the group being committed
+	splitGroups []splitGroup
+	splitHunks  map[string][]splitHunk // Hunks of staged files with several, by path
+	splitTree   string
+	splitIndex  int
+
+	// Commits made this session, and whether to offer another while
+	// uncommitted changes remain (--loop)
+	commits int
+	loop    bool
+
+	// Commits made this session that get an explanation as a git note on
+	// exit (--notes)
+	noteCommits []string
+
+	// Commit being reworded (gitcat reword), nil when making a new one. A
+	// range rewords rewordQueue in turn, collecting the approved messages in
+	// rewordEdits until the branch is rebased onto rewordBase.
+	reword      *rewordTarget
+	rewordQueue []*rewordTarget
+	rewordIndex int
+	rewordEdits []rewordEdit
+	rewordBase  string
+
+	// Squash (gitcat squash, or before a PR): the merge base the branch is
+	// squashed onto, how many commits it had, whether a PR follows, and
+	// whether the branch has been reset but not yet committed
+	squashBase  string
+	squashCount int
+	squashPR    bool
+	squashReset bool
+
+	// Commit being reverted (gitcat revert), nil otherwise
+	revert *rewordTarget
+
+	// Review of the staged changes (gitcat review), and the phase, choices,
+	// and cursor of the screen the commit flow continues from
+	reviewText    string
+	reviewPhase   string
+	reviewChoices []string
+	reviewCursor  int
+
+	// Merge being concluded (MERGE_HEAD exists), nil otherwise
+	merge *mergeInfo
+
+	// An empty commit (--allow-empty with nothing staged), and what the user
+	// says the commit is for: the purpose of an empty commit or the reason
+	// for a revert
+	emptyCommit bool
+	intent      string
+
+	// Unstaged changes set aside so verify and hooks see only what is staged:
+	// whether the user has been asked, and the stash commit holding them
+	stashAsked    bool
+	unstagedStash string
 }
 
-type OpenAIChoice struct {
-	Message OpenAIMessage `json:"message"`
-}
+func initialModel(diff string, needsAdd bool, currentBranch string, isProtectedBranch bool, prOnly bool) model {
+	// Gerrit changes are committed on the target branch and pushed for
+	// review, so it needs no feature branch
+	gerrit := loadGerritReview(currentBranch)
+	if gerrit != nil {
+		isProtectedBranch = false
+	}
 
-// Ollama API types
-type OllamaRequest struct {
-	Model    string          `json:"model"`
-	Messages []OllamaMessage `json:"messages"`
-	Stream   bool            `json:"stream"`
-}
+	// Determine initial phase based on conditions
+	phase := "type"
+	if prOnly {
+		phase = "pr_generating"
+	} else if *branchFlag != "" && *branchFlag != currentBranch {
+		// Checked in main; the name only needs confirming
+		phase = "branch_input"
+	} else if isProtectedBranch {
+		phase = "branch_warning"
+	}
 
-type OllamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	m := model{
+		commitTypes:       commitTypesFor(appConfig),
+		typeSelected:      0,
+		phase:             phase,
+		diff:              diff,
+		needsAdd:          needsAdd,
+		currentBranch:     currentBranch,
+		isProtectedBranch: isProtectedBranch,
+		prOnly:            prOnly,
+		breaking:          *breakingFlag,
+		commitOpts:        currentCommitOptions(),
+		promptIgnore:      loadGitcatIgnore(),
+		promptAutoExclude: autoExcludeMatcher(appConfig),
+		gerrit:            gerrit,
+	}
+	// With --allow-empty and nothing staged (checked in main), the commit is
+	// empty from the start, so the type and scope phases below ask for its
+	// purpose rather than going straight to generation
+	if diff == "" && !needsAdd && !prOnly && *allowEmptyFlag {
+		m.emptyCommit = true
+		m.commitOpts.AllowEmpty = true
+	}
+	if appConfig != nil {
+		// Patterns were validated when the config was loaded
+		m.promptRedactions, _ = compileRedactions(appConfig.Redact)
+		m.promptPathsOnly = parseIgnorePatterns(appConfig.PromptPathsOnly)
+	}
+	if m.commitOpts.Signoff || (appConfig != nil && len(appConfig.Trailers) > 0) || gerrit != nil {
+		m.committerIdent = getCommitterIdent()
+		m.changeID = newChangeID()
+	}
+	if phase == "branch_warning" {
+		m.suggestedBranch = generateDefaultBranchName(diff, currentBranch)
+		m.branchInput = m.suggestedBranch
+		if m.protectedUpstream = branchUpstream(currentBranch); m.protectedUpstream != "" {
+			m.unpushedCommits = unpushedCommitCount(m.protectedUpstream)
+		}
+		m = m.enterBranchWarningPhase()
+	}
+	if phase == "branch_input" {
+		m.branchInput = *branchFlag
+	}
+	m.loop = *loopFlag || getEffectiveConfig().Loop
+	m.commitlint = loadCommitlintConfig()
+	m.strict5072 = strictFormatEnabled()
+	m.verbosity = messageVerbosity()
+	m.styleExamples = getStyleExamples(getEffectiveConfig().StyleExamples)
+	m.exemplars = getExemplars()
+	if !m.commitOpts.NoVerify {
+		// --no-verify skips the verify command along with git's hooks
+		m.verifyCommand = getVerifyCommand()
+	}
+	m.coAuthorsSelected = make(map[string]bool)
+	if appConfig != nil {
+		m.coAuthors = append(m.coAuthors, appConfig.CoAuthors...)
+	}
+	for _, value := range *coAuthorFlags {
+		// Validated in main
+		if author, err := resolveCoAuthor(value, m.coAuthors); err == nil {
+			if !slices.Contains(m.coAuthors, author) {
+				m.coAuthors = append(m.coAuthors, author)
+			}
+			m.coAuthorsSelected[author] = true
+		}
+	}
+	if drafts, err := newDraftStore(); err != nil {
+		debugf("drafts disabled: %v", err)
+	} else {
+		m.drafts = drafts
+	}
+	if *reuseLastFlag {
+		// Checked in main; confirmed in place of generation once files are staged
+		m.savedDraft = m.drafts.load(currentBranch)
+	}
+	if phase == "type" && needsAdd {
+		m = m.enterAddPhase()
+	} else if phase == "type" && *reuseLastFlag {
+		m = m.enterTypePhase()
+	} else if phase == "type" {
+		if d := m.drafts.load(currentBranch); d != nil {
+			m.savedDraft = d
+			m.phase = "resume_draft"
+			m.choices = []string{tr("Resume saved message"), tr("Discard it and start over")}
+		} else {
+			m = m.enterTypePhase()
+		}
+	}
+	return m
 }
 
-type OllamaResponse struct {
-	Model   string        `json:"model"`
-	Message OllamaMessage `json:"message"`
+// enterUnstagePhase switches to a picker of staged files, none selected,
+// so the user can drop files from the commit before generation
+func (m model) enterUnstagePhase() model {
+	files, err := worktree.StagedFiles()
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Error listing staged files: %v", err)
+		m.exitCode = exitGitFailure
+		return m
+	}
+	m.phase = "unstage"
+	m.cursor = 0
+	m.files = files
+	m.selected = make(map[int]struct{}, len(files))
+	return m
 }
 
-// getConfigPath returns the path to the config file
-func getConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+// enterExcludePhase switches to a picker of staged files where checked files
+// have their content sent to the LLM; unchecked files are still committed
+// but only their paths appear in the prompt
+func (m model) enterExcludePhase() model {
+	files, err := worktree.StagedFiles()
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		m.errorMsg = fmt.Sprintf("Error listing staged files: %v", err)
+		m.exitCode = exitGitFailure
+		return m
+	}
+	m.phase = "exclude"
+	m.cursor = 0
+	m.files = files
+	m.selected = make(map[int]struct{}, len(files))
+	for i, f := range files {
+		if _, ok := m.promptExcluded[f.Path]; !ok {
+			m.selected[i] = struct{}{}
+		}
 	}
-	return filepath.Join(homeDir, ".config", "gitcat", "config.json"), nil
+	return m
 }
 
-// loadConfig loads the configuration from the config file
-func loadConfig() (*Config, error) {
-	configPath, err := getConfigPath()
+// enterAddPhase switches to the file picker, listing every changed file with
+// all of them selected so enter keeps the old "add everything" behavior
+func (m model) enterAddPhase() model {
+	files, err := listChangedFiles(false)
 	if err != nil {
-		return nil, err
-	}
+		m.errorMsg = fmt.Sprintf("Error listing changed files: %v", err)
+		m.exitCode = exitGitFailure
+		return m
+	}
+	m.phase = "add"
+	m.cursor = 0
+	m.showIgnored = false
+	m.files = files
+	m.selected = make(map[int]struct{}, len(files))
+	for i := range files {
+		m.selected[i] = struct{}{}
+	}
+	return m
+}
 
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Return default config if file doesn't exist
-			return &Config{
-				Provider:  "anthropic",
-				Model:     defaultAnthropicModel,
-				OllamaURL: defaultOllamaURL,
-			}, nil
+// enterTypePhase moves on to commit type selection, with --reuse-last
+// straight to confirming the saved message, or with --type or --auto-type to
+// the scope input with the type given or chosen by the AI
+func (m model) enterTypePhase() model {
+	if *reuseLastFlag && m.savedDraft != nil {
+		return m.useSavedDraft()
+	}
+	if *typeFlag != "" {
+		if i := slices.IndexFunc(m.commitTypes, func(t CommitType) bool { return t.Name == *typeFlag }); i >= 0 {
+			m.typeSelected = i
+			return m.enterScopePhase()
 		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
-
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if *autoTypeFlag {
+		m.typeSelected = len(m.commitTypes)
+		return m.enterScopePhase()
 	}
+	m.phase = "type"
+	return m
+}
 
-	// Set defaults for missing values
-	if config.Provider == "" {
-		config.Provider = "anthropic"
+// enterScopePhase moves to the scope input with suggestions derived from
+// the staged paths. With --scope or skip_scope it moves past it: to the
+// intent of an empty commit, or to "generating", which continueToGeneration
+// starts.
+func (m model) enterScopePhase() model {
+	if *scopeFlag != "" {
+		m.scopeInput = *scopeFlag
 	}
-	if config.Model == "" {
-		switch config.Provider {
-		case "ollama":
-			config.Model = defaultOllamaModel
-		case "openai":
-			config.Model = defaultOpenAIModel
-		default:
-			config.Model = defaultAnthropicModel
+	if *scopeFlag != "" || skipScopeEnabled() {
+		m.phase = "generating"
+		if m.emptyCommit {
+			m.phase = "intent"
 		}
+		return m
 	}
-	if config.OllamaURL == "" {
-		config.OllamaURL = defaultOllamaURL
+	var paths []string
+	for _, f := range splitDiff(m.diff) {
+		paths = append(paths, f.path)
 	}
+	m.scopeSuggestions = suggestScopes(paths)
 
-	return &config, nil
+	// In a monorepo the package is the natural scope: prefill it when every
+	// file is in the same one
+	packages, allInPackages := changedPackages(paths)
+	m.scopePackages = packages
+	if len(packages) == 1 {
+		scope := path.Base(packages[0])
+		m.scopeSuggestions = append([]string{scope}, slices.DeleteFunc(m.scopeSuggestions, func(s string) bool { return s == scope })...)
+		if allInPackages && m.scopeInput == "" {
+			m.scopeInput = scope
+		}
+	}
+	m.phase = "scope"
+	return m
 }
 
-// saveConfig saves the configuration to the config file
-func saveConfig(config *Config) error {
-	configPath, err := getConfigPath()
-	if err != nil {
-		return err
+// scopePhase returns the phase going back to the scope input leads to:
+// the type picker when the scope input is skipped
+func (m model) scopePhase() string {
+	if *scopeFlag != "" || skipScopeEnabled() {
+		return "type"
 	}
+	return "scope"
+}
 
-	// Ensure directory exists
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// continueToGeneration starts generating once the type and scope inputs
+// moved straight on to it
+func (m model) continueToGeneration() (model, tea.Cmd) {
+	if m.phase == "generating" && m.pending == nil {
+		return m.startGeneration()
 	}
+	return m, nil
+}
 
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+// cycleScope replaces the scope input with the next (delta 1) or previous
+// (delta -1) suggestion, wrapping around the list
+func (m model) cycleScope(delta int) model {
+	n := len(m.scopeSuggestions)
+	if n == 0 {
+		return m
 	}
-
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	current := -1
+	for i, s := range m.scopeSuggestions {
+		if s == m.scopeInput {
+			current = i
+		}
 	}
-
-	return nil
+	next := (current + delta + n) % n
+	if current < 0 && delta < 0 {
+		next = n - 1
+	}
+	m.scopeInput = m.scopeSuggestions[next]
+	return m
 }
 
-// getEffectiveConfig returns the config with CLI flag overrides applied
-func getEffectiveConfig() *Config {
-	config := *appConfig // Copy the config
-
-	// Apply provider override
-	provider := *providerFlag
-	if *pFlag != "" {
-		provider = *pFlag
-	}
-	if provider != "" {
-		config.Provider = provider
-	}
+// autoType reports whether the "let AI decide" entry is selected, shown
+// after the configured types in the picker
+func (m model) autoType() bool {
+	return m.typeSelected == len(m.commitTypes)
+}
 
-	// Apply model override: -m/--model overrides the default model for both
-	model := *modelFlag
-	if *mFlag != "" {
-		model = *mFlag
-	}
-	if model != "" {
-		config.Model = model
+// selectedType returns the chosen commit type, or an empty CommitType when
+// the AI is to decide
+func (m model) selectedType() CommitType {
+	if m.autoType() {
+		return CommitType{}
 	}
+	return m.commitTypes[m.typeSelected]
+}
 
-	// Apply specific model overrides (take precedence over -m/--model)
-	if *commitModelFlag != "" {
-		config.CommitModel = *commitModelFlag
+// autoTypeNote reports the type picked for the user (by the AI, or by path
+// heuristics offline), flagging one that isn't in the configured list
+func (m model) autoTypeNote() string {
+	noteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	chosen := messageType(m.generatedMsg)
+	if chosen == "" {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(tr("The message does not use the type(scope): format")) + "\n\n"
 	}
-	if *prModelFlag != "" {
-		config.PRModel = *prModelFlag
+	for _, t := range m.commitTypes {
+		if t.Name == chosen {
+			return noteStyle.Render(tr("Type chosen automatically: %s (%s)", t.Name, t.Description)) + "\n\n"
+		}
 	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(tr("The chosen type %q is not in your commit types", chosen)) + "\n\n"
+}
 
-	// Apply Ollama URL override
-	if *ollamaURLFlag != "" {
-		config.OllamaURL = *ollamaURLFlag
+// typeLabel names the chosen commit type for display
+func (m model) typeLabel() string {
+	if m.autoType() {
+		return tr("AI-chosen type")
 	}
+	return m.commitTypes[m.typeSelected].Name
+}
 
-	// Apply OpenAI overrides
-	if *openaiURLFlag != "" {
-		config.OpenAIURL = *openaiURLFlag
+// enterConfirmPhase shows generatedMsg for confirmation. The second choice
+// edits the message in the preferred way (config use_editor), the third in
+// the other.
+func (m model) enterConfirmPhase() model {
+	m.phase = "confirm"
+	m.cursor = 0
+	accept := tr("Yes, commit")
+	if m.squashBase != "" {
+		accept = tr("Yes, squash")
+	} else if m.reword != nil {
+		accept = tr("Yes, reword")
+	}
+	if getEffectiveConfig().UseEditor {
+		m.choices = []string{accept, tr("Edit in $EDITOR"), tr("Edit inline")}
+	} else {
+		m.choices = []string{accept, tr("No, let me edit"), tr("Edit in $EDITOR")}
 	}
-	if *openaiAPIKeyFlag != "" {
-		config.OpenAIAPIKey = *openaiAPIKeyFlag
+	if m.rewordQueue != nil {
+		m.choices = append(m.choices, tr("Keep current message"))
 	}
-
-	return &config
+	return m
 }
 
-type model struct {
-	choices           []string
-	cursor            int
-	selected          map[int]struct{}
-	commitTypes       []string
-	typeSelected      int
-	scopeInput        string
-	phase             string
-	diff              string
-	needsAdd          bool
-	generatedMsg      string
-	errorMsg          string
-	currentBranch     string
-	prTitle           string
-	prBody            string
-	isProtectedBranch bool   // Track if on main/master
-	branchInput       string // User input for branch name
-
-	// Tracking completed actions for exit summary
-	filesCommitted  int
-	didCommit       bool
-	didPush         bool
-	didCreatePR     bool
-	createdBranch   string // Non-empty if a new branch was created
-
-	// API error context for retry capability
-	apiErrorMsg string // Stores the API error message to display
-
-	// PR-only mode (--pr flag)
-	prOnly bool
+// finishPRTitle leaves the title input: back to the preview when the title
+// was being edited from there, else on to the body
+func (m model) finishPRTitle() model {
+	if m.prEditReturn {
+		return m.enterPRConfirmPhase()
+	}
+	m.phase = "pr_manual_body"
+	return m
 }
 
-func initialModel(diff string, needsAdd bool, currentBranch string, isProtectedBranch bool, prOnly bool) model {
-	commitTypes := []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore"}
+// enterPRConfirmPhase shows the PR preview, where nothing has been sent yet,
+// with options to accept, edit, or regenerate it
+func (m model) enterPRConfirmPhase() model {
+	m.phase = "pr_confirm"
+	if m.prMeta == nil {
+		m.prMeta = loadPRMeta(m.prBaseRef())
+		m.autoMerge = autoMergeStrategy()
+		m.conventionalPRTitle = conventionalPRTitles()
+	}
+	m.cursor = 0
+	m.choices = []string{tr("Yes, create PR"), tr("Edit title"), tr("Edit body"), tr("Edit in $EDITOR"), tr("Regenerate"), tr("Skip")}
+	if m.existingPR != nil {
+		m.choices[0] = tr("Yes, update PR #%d", m.existingPR.Number)
+	}
+	return m
+}
 
-	// Determine initial phase based on conditions
-	phase := "type"
-	choices := []string{"Yes, add all changes", "No, exit"}
+// useSavedDraft offers the saved draft in the confirm phase
+func (m model) useSavedDraft() model {
+	m.generatedMsg = m.savedDraft.Message
+	m.savedDraft = nil
+	m = m.enterConfirmPhase()
+	return m
+}
 
-	if prOnly {
-		phase = "pr_generating"
-	} else if isProtectedBranch {
-		phase = "branch_warning"
-		choices = []string{"Yes, create a new branch", fmt.Sprintf("No, continue on %s", currentBranch)}
-	} else if needsAdd {
-		phase = "add"
+// toggleIgnoredFiles reloads the picker with ignored files shown or hidden,
+// keeping the current selection. Ignored files are never preselected.
+func (m model) toggleIgnoredFiles() model {
+	files, err := listChangedFiles(!m.showIgnored)
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Error listing changed files: %v", err)
+		m.exitCode = exitGitFailure
+		return m
 	}
-
-	return model{
-		choices:           choices,
-		commitTypes:       commitTypes,
-		typeSelected:      0,
-		phase:             phase,
-		diff:              diff,
-		needsAdd:          needsAdd,
-		currentBranch:     currentBranch,
-		isProtectedBranch: isProtectedBranch,
-		branchInput:       generateDefaultBranchName(),
-		prOnly:            prOnly,
+	chosen := make(map[string]bool, len(m.selected))
+	for i := range m.selected {
+		chosen[m.files[i].Path] = true
+	}
+	m.showIgnored = !m.showIgnored
+	m.files = files
+	m.selected = make(map[int]struct{}, len(files))
+	for i, f := range files {
+		if chosen[f.Path] {
+			m.selected[i] = struct{}{}
+		}
+	}
+	if m.cursor >= len(files) {
+		m.cursor = max(len(files)-1, 0)
 	}
+	return m
 }
 
 func (m model) Init() tea.Cmd {
-	if m.prOnly {
-		return generatePRContent(m.currentBranch)
+	if m.errorMsg != "" {
+		return tea.Quit
+	}
+	if (m.prOnly && m.phase == "pr_generating") || m.phase == "review_generating" {
+		return func() tea.Msg { return startRequestMsg{} }
+	}
+	if m.rewordQueue != nil || m.merge != nil || m.phase == "generating" {
+		return func() tea.Msg { return startGenerationMsg{} }
+	}
+	if scripted() {
+		return func() tea.Msg { return scriptStepMsg{} }
 	}
 	return nil
 }
 
+// Update handles msg, then answers the prompts the scripting flags decide
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if n := next.(model); m.typingMessage() && !n.typingMessage() {
+		// Save the typed message once its screen is left
+		n.keepDraft()
+		next = n
+	}
+	return next.(model).runScript(cmd)
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
+			if m.typingMessage() {
+				m.keepDraft()
+			}
+			m.cancelRequests()
+			m.exitCode = m.quitExitCode()
 			return m, tea.Quit
 
+		case "ctrl+e":
+			if m.phase == "edit" || m.phase == "manual_input" {
+				m.editorErr = ""
+				return m, openEditor(editTargetCommit, m.generatedMsg)
+			} else if m.phase == "pr_manual_title" {
+				m.editorErr = ""
+				return m, openEditor(editTargetPRTitle, m.prTitle)
+			} else if m.phase == "pr_manual_body" {
+				m.editorErr = ""
+				return m, openEditor(editTargetPRBody, m.prBody)
+			}
+
 		case "q":
 			// Only quit if not in an input phase where 'q' should be typed (e.g. model names like "qwen")
-			if m.phase != "branch_input" && m.phase != "scope" && m.phase != "edit" && m.phase != "manual_input" && m.phase != "pr_manual_title" && m.phase != "pr_manual_body" {
+			if m.phase != "branch_input" && m.phase != "scope" && m.phase != "intent" && m.phase != "edit" && m.phase != "manual_input" && m.phase != "pr_manual_title" && m.phase != "pr_manual_body" {
+				m.cancelRequests()
+				m.exitCode = m.quitExitCode()
 				return m, tea.Quit
 			}
 			// Fall through to default handler for text input
@@ -340,6 +737,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.branchInput += msg.String()
 			} else if m.phase == "scope" {
 				m.scopeInput += msg.String()
+			} else if m.phase == "intent" {
+				m.intent += msg.String()
 			} else if m.phase == "edit" || m.phase == "manual_input" {
 				m.generatedMsg += msg.String()
 			} else if m.phase == "pr_manual_title" {
@@ -348,24 +747,37 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.prBody += msg.String()
 			}
 
+		case "esc":
+			if m.waiting() {
+				return m.cancelRequest()
+			}
+			m = m.goBack()
+			if m.errorMsg != "" {
+				return m, tea.Quit
+			}
+
 		case "up", "k":
 			// Only handle as navigation if not in input phase
-			if m.phase != "branch_input" && m.phase != "scope" && m.phase != "edit" && m.phase != "manual_input" && m.phase != "pr_manual_title" && m.phase != "pr_manual_body" {
+			if m.phase != "branch_input" && m.phase != "scope" && m.phase != "intent" && m.phase != "edit" && m.phase != "manual_input" && m.phase != "pr_manual_title" && m.phase != "pr_manual_body" {
 				if m.phase == "branch_warning" && m.cursor > 0 {
 					m.cursor--
-				} else if m.phase == "add" && m.cursor > 0 {
+				} else if (m.phase == "add" || m.phase == "unstage" || m.phase == "exclude" || m.phase == "coauthors") && m.cursor > 0 {
 					m.cursor--
 				} else if m.phase == "type" && m.typeSelected > 0 {
 					m.typeSelected--
-				} else if (m.phase == "push_prompt" || m.phase == "upstream_prompt" || m.phase == "pr_prompt" || m.phase == "pr_confirm" || m.phase == "confirm" || m.phase == "commit_error" || m.phase == "pr_error") && m.cursor > 0 {
+				} else if (m.phase == "push_prompt" || m.phase == "upstream_prompt" || m.phase == "pr_prompt" || m.phase == "pr_confirm" || m.phase == "confirm" || m.phase == "commit_error" || m.phase == "pr_error" || m.phase == "hook_failed" || m.phase == "verify_failed" || m.phase == "split_plan" || m.phase == "loop_prompt" || m.phase == "secrets_warning" || m.phase == "resume_draft" || m.phase == "stash_prompt" || m.phase == "branch_conflict" || m.phase == "pr_base" || m.phase == "review" || m.phase == "review_error" || m.phase == "candidates") && m.cursor > 0 {
 					m.cursor--
 				}
+			} else if m.phase == "scope" && msg.String() == "up" {
+				m = m.cycleScope(-1)
 			} else if msg.String() == "k" && len(msg.String()) == 1 {
 				// Allow typing 'k' in input phases
 				if m.phase == "branch_input" {
 					m.branchInput += msg.String()
 				} else if m.phase == "scope" {
 					m.scopeInput += msg.String()
+				} else if m.phase == "intent" {
+					m.intent += msg.String()
 				} else if m.phase == "edit" || m.phase == "manual_input" {
 					m.generatedMsg += msg.String()
 				} else if m.phase == "pr_manual_title" {
@@ -377,22 +789,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "down", "j":
 			// Only handle as navigation if not in input phase
-			if m.phase != "branch_input" && m.phase != "scope" && m.phase != "edit" && m.phase != "manual_input" && m.phase != "pr_manual_title" && m.phase != "pr_manual_body" {
+			if m.phase != "branch_input" && m.phase != "scope" && m.phase != "intent" && m.phase != "edit" && m.phase != "manual_input" && m.phase != "pr_manual_title" && m.phase != "pr_manual_body" {
 				if m.phase == "branch_warning" && m.cursor < len(m.choices)-1 {
 					m.cursor++
-				} else if m.phase == "add" && m.cursor < len(m.choices)-1 {
+				} else if (m.phase == "add" || m.phase == "unstage" || m.phase == "exclude") && m.cursor < len(m.files)-1 {
 					m.cursor++
-				} else if m.phase == "type" && m.typeSelected < len(m.commitTypes)-1 {
+				} else if m.phase == "coauthors" && m.cursor < len(m.coAuthors)-1 {
+					m.cursor++
+				} else if m.phase == "type" && m.typeSelected < len(m.commitTypes) {
 					m.typeSelected++
-				} else if (m.phase == "push_prompt" || m.phase == "upstream_prompt" || m.phase == "pr_prompt" || m.phase == "pr_confirm" || m.phase == "confirm" || m.phase == "commit_error" || m.phase == "pr_error") && m.cursor < len(m.choices)-1 {
+				} else if (m.phase == "push_prompt" || m.phase == "upstream_prompt" || m.phase == "pr_prompt" || m.phase == "pr_confirm" || m.phase == "confirm" || m.phase == "commit_error" || m.phase == "pr_error" || m.phase == "hook_failed" || m.phase == "verify_failed" || m.phase == "split_plan" || m.phase == "loop_prompt" || m.phase == "secrets_warning" || m.phase == "resume_draft" || m.phase == "stash_prompt" || m.phase == "branch_conflict" || m.phase == "pr_base" || m.phase == "review" || m.phase == "review_error" || m.phase == "candidates") && m.cursor < len(m.choices)-1 {
 					m.cursor++
 				}
+			} else if m.phase == "scope" && msg.String() == "down" {
+				m = m.cycleScope(1)
 			} else if msg.String() == "j" && len(msg.String()) == 1 {
 				// Allow typing 'j' in input phases
 				if m.phase == "branch_input" {
 					m.branchInput += msg.String()
 				} else if m.phase == "scope" {
 					m.scopeInput += msg.String()
+				} else if m.phase == "intent" {
+					m.intent += msg.String()
 				} else if m.phase == "edit" || m.phase == "manual_input" {
 					m.generatedMsg += msg.String()
 				} else if m.phase == "pr_manual_title" {
@@ -404,143 +822,289 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "enter":
 			if m.phase == "branch_warning" {
-				if m.cursor == 0 {
-					// User wants to create new branch
+				if m.cursor == 0 || (m.cursor == 1 && m.unpushedCommits > 0) {
+					// User wants to create new branch, maybe taking the commits along
+					m.moveCommits = m.cursor == 1
 					m.phase = "branch_input"
 				} else {
 					// User wants to continue on main/master
 					// Move to next phase in normal flow
 					if m.needsAdd {
-						m.phase = "add"
-						m.cursor = 0
-						m.choices = []string{"Yes, add all changes", "No, exit"}
+						m = m.enterAddPhase()
+						if m.errorMsg != "" {
+							return m, tea.Quit
+						}
 					} else {
-						m.phase = "type"
+						m = m.enterTypePhase()
+						return m.continueToGeneration()
 					}
 				}
 			} else if m.phase == "branch_input" {
-				// Validate branch name
-				if err := validateBranchName(m.branchInput); err != nil {
-					m.errorMsg = err.Error()
-					return m, tea.Quit
+				// Check the name against git's rules and existing branches
+				if problem, alternative := checkNewBranch(m.branchInput); problem != "" {
+					return m.enterBranchConflictPhase(problem, alternative), nil
 				}
 				// User submitted branch name
-				m.phase = "branch_creating"
-				return m, createAndCheckoutBranch(m.branchInput)
+				return m.createBranch()
+			} else if m.phase == "branch_conflict" {
+				if m.branchAlternative != "" && m.cursor == 0 {
+					m.branchInput = m.branchAlternative
+					return m.createBranch()
+				}
+				m.phase = "branch_input"
 			} else if m.phase == "add" {
-				if m.cursor == 0 {
-					if err := gitAdd(); err != nil {
-						m.errorMsg = fmt.Sprintf("Error adding files: %v", err)
+				var files []changedFile
+				for i, f := range m.files {
+					if _, ok := m.selected[i]; ok {
+						files = append(files, f)
+					}
+				}
+				if len(files) == 0 {
+					// Nothing chosen yet; stay on the picker
+					return m, nil
+				}
+				if err := gitAddFiles(files); err != nil {
+					m.errorMsg = fmt.Sprintf("Error adding files: %v", err)
+					m.exitCode = exitGitFailure
+					return m, tea.Quit
+				}
+				diff, err := worktree.StagedDiff()
+				if err != nil {
+					m.errorMsg = fmt.Sprintf("Error getting diff: %v", err)
+					m.exitCode = exitGitFailure
+					return m, tea.Quit
+				}
+				m.diff = diff
+				m = m.enterTypePhase()
+				return m.continueToGeneration()
+			} else if m.phase == "unstage" {
+				var files []changedFile
+				for i, f := range m.files {
+					if _, ok := m.selected[i]; ok {
+						files = append(files, f)
+					}
+				}
+				if len(files) > 0 {
+					if err := gitRestoreStaged(files); err != nil {
+						m.errorMsg = fmt.Sprintf("Error unstaging files: %v", err)
+						m.exitCode = exitGitFailure
 						return m, tea.Quit
 					}
-					diff, err := getGitDiff()
+					diff, err := worktree.StagedDiff()
 					if err != nil {
 						m.errorMsg = fmt.Sprintf("Error getting diff: %v", err)
+						m.exitCode = exitGitFailure
 						return m, tea.Quit
 					}
 					m.diff = diff
+				}
+				if m.diff == "" {
+					// Everything was unstaged; pick files again
+					m.needsAdd = true
+					m = m.enterAddPhase()
+					if m.errorMsg != "" {
+						return m, tea.Quit
+					}
+				} else {
 					m.phase = "type"
+				}
+			} else if m.phase == "coauthors" {
+				m.phase = "type"
+			} else if m.phase == "resume_draft" {
+				if m.cursor == 0 {
+					m = m.useSavedDraft()
 				} else {
-					return m, tea.Quit
+					m.drafts.clear(m.currentBranch)
+					m.cursor = 0
+					m.savedDraft = nil
+					// --type, --auto-type, and --scope apply as they would
+					// have without a draft
+					return m.enterTypePhase().continueToGeneration()
 				}
+			} else if m.phase == "exclude" {
+				m.promptExcluded = make(map[string]struct{})
+				for i, f := range m.files {
+					if _, ok := m.selected[i]; !ok {
+						m.promptExcluded[f.Path] = struct{}{}
+					}
+				}
+				m.phase = "type"
 			} else if m.phase == "type" {
-				m.phase = "scope"
-			} else if m.phase == "scope" {
-				// Check if diff is too large
-				if isDiffTooLarge(m.diff) {
+				m = m.enterScopePhase()
+				return m.continueToGeneration()
+			} else if m.phase == "scope" && m.emptyCommit {
+				// Nothing to describe, so ask what the commit is for
+				m.phase = "intent"
+			} else if m.phase == "scope" || m.phase == "intent" {
+				return m.startGeneration()
+			} else if m.phase == "stash_prompt" {
+				if m.cursor == 0 {
+					return m.stashAndContinue()
+				}
+				m.stashAsked = true
+				return m.startGeneration()
+			} else if m.phase == "candidates" {
+				return m.pickCandidate()
+			} else if m.phase == "review" || m.phase == "review_error" {
+				if m.cursor == len(m.choices)-1 {
+					m.exitCode = exitUserAborted
+					return m, tea.Quit
+				}
+				if m.phase == "review_error" && m.cursor == 0 {
+					return m.requestReview()
+				}
+				m = m.continueAfterReview()
+			} else if m.phase == "secrets_warning" {
+				if getEffectiveConfig().SecretScan == secretScanBlock || m.cursor == len(m.choices)-1 {
+					// Abort
+					m.exitCode = exitUserAborted
+					return m, tea.Quit
+				}
+				m.secretsAcknowledged = true
+				if m.cursor == 1 {
+					// Commit with a manual message, nothing is sent to the AI
 					m.phase = "manual_input"
-					m.generatedMsg = "" // Start with empty message for manual input
-				} else {
-					m.phase = "generating"
-					return m, generateCommitMsg(m.diff, m.commitTypes[m.typeSelected], m.scopeInput)
+					m.generatedMsg = ""
+					return m, nil
 				}
+				return m.startGeneration()
 			} else if m.phase == "confirm" {
 				if m.cursor == 0 {
-					m.filesCommitted = countStagedFiles()
-					if err := gitCommit(m.generatedMsg); err != nil {
-						m.errorMsg = fmt.Sprintf("Error committing: %v", err)
-						return m, tea.Quit
-					}
-					m.didCommit = true
-					m.phase = "push_prompt"
-					m.cursor = 1
-					m.choices = []string{"Yes, push", "No, skip"}
+					return m.commit(m.commitOptions())
+				} else if m.cursor == 3 {
+					// Keep current message (batch reword)
+					return m.acceptReword("")
+				} else if (m.cursor == 1) == getEffectiveConfig().UseEditor {
+					m.editorErr = ""
+					return m, openEditor(editTargetCommit, m.generatedMsg)
 				} else {
 					m.phase = "edit"
 				}
 			} else if m.phase == "edit" || m.phase == "manual_input" {
-				m.filesCommitted = countStagedFiles()
-				if err := gitCommit(m.generatedMsg); err != nil {
-					m.errorMsg = fmt.Sprintf("Error committing: %v", err)
+				return m.commit(m.commitOptions())
+			} else if m.phase == "hook_failed" {
+				switch m.cursor {
+				case 0:
+					return m.commit(m.commitOptions())
+				case 1:
+					opts := m.commitOptions()
+					opts.NoVerify = true
+					m.skippedHooks = true
+					return m.commit(opts)
+				case 2:
+					m.phase = "edit"
+				default:
+					m.exitCode = exitGitFailure
 					return m, tea.Quit
 				}
-				m.didCommit = true
-				m.phase = "push_prompt"
-				m.cursor = 1
-				m.choices = []string{"Yes, push", "No, skip"}
+			} else if m.phase == "loop_prompt" {
+				if m.cursor == 0 {
+					m = m.nextCommit()
+					if m.errorMsg != "" {
+						return m, tea.Quit
+					}
+				} else {
+					return m.enterPushPhase().prefetchPRContent()
+				}
 			} else if m.phase == "push_prompt" {
+				if m.cursor == 0 && m.gerrit != nil {
+					changes, err := m.gerrit.push(m.commitOpts.NoVerify)
+					if err != nil {
+						m.errorMsg = fmt.Sprintf("Error pushing for review: %v", err)
+						m.exitCode = exitGitFailure
+						return m, tea.Quit
+					}
+					m.didPush = true
+					m.gerritChanges = changes
+					m.phase = "exiting"
+					return m, tea.Quit
+				}
 				if m.cursor == 0 {
-					err := gitPush()
+					err := gitPush(m.currentBranch, m.commitOpts.NoVerify)
 					if err != nil {
 						errStr := err.Error()
 						if strings.Contains(errStr, "no upstream branch") || strings.Contains(errStr, "has no upstream branch") {
-							m.phase = "upstream_prompt"
-							m.cursor = 0
-							m.choices = []string{"Yes, set upstream and push", "No, skip"}
-							return m, nil
+							return m.enterUpstreamPromptPhase(), nil
 						}
 						m.errorMsg = fmt.Sprintf("Error pushing: %v", err)
+						m.exitCode = exitGitFailure
 						return m, tea.Quit
 					}
 					m.didPush = true
-					// Check if PR already exists or if origin is not GitHub
-					if err := isGitHubOrigin(); err != nil {
-						m.phase = "exiting"
-						return m, tea.Quit
-					}
-					if hasExistingPR(m.currentBranch) {
+					// Check if PR already exists or if the PR remote is not GitHub
+					if err := checkPRRemote(prRemote(m.currentBranch)); err != nil {
 						m.phase = "exiting"
 						return m, tea.Quit
 					}
-					m.phase = "pr_prompt"
-					m.cursor = 1
-					m.choices = []string{"Yes, create PR", "No, skip"}
-					return m, nil
+					m = m.withPRTarget()
+					m.existingPR = findExistingPR(m.currentBranch, *m.prTarget)
+					return m.enterPRPromptPhase(), nil
 				}
 				m.phase = "exiting"
 				return m, tea.Quit
 			} else if m.phase == "upstream_prompt" {
-				if m.cursor == 0 {
-					if err := gitPushSetUpstream(m.currentBranch); err != nil {
+				if m.cursor < len(m.upstreamRemotes) {
+					if err := worktree.Push(m.upstreamRemotes[m.cursor], m.currentBranch, gitops.PushOptions{SetUpstream: true, NoVerify: m.commitOpts.NoVerify}); err != nil {
 						m.errorMsg = fmt.Sprintf("Error setting upstream: %v", err)
+						m.exitCode = exitGitFailure
 						return m, tea.Quit
 					}
 					m.didPush = true
-					// Check if PR already exists (GitHub origin already verified earlier)
-					if hasExistingPR(m.currentBranch) {
+					// Check if PR already exists or if the PR remote is not GitHub
+					if err := checkPRRemote(prRemote(m.currentBranch)); err != nil {
 						m.phase = "exiting"
 						return m, tea.Quit
 					}
-					m.phase = "pr_prompt"
-					m.cursor = 1
-					m.choices = []string{"Yes, create PR", "No, skip"}
-					return m, nil
+					m = m.withPRTarget()
+					m.existingPR = findExistingPR(m.currentBranch, *m.prTarget)
+					return m.enterPRPromptPhase(), nil
 				}
 				m.phase = "exiting"
 				return m, tea.Quit
 			} else if m.phase == "pr_prompt" {
 				if m.cursor == 0 {
-					m.phase = "pr_generating"
-					return m, generatePRContent(m.currentBranch)
+					if next, cmd, ok := m.usePrefetchedPR(); ok {
+						return next, cmd
+					}
+					return m.startPRGeneration()
+				}
+				if m.cursor == 2 {
+					// Squash first, then create the PR
+					return m.startSquash(defaultBaseRef(), true)
 				}
 				m.phase = "exiting"
 				return m, tea.Quit
+			} else if m.phase == "split_plan" {
+				if m.splitGroups == nil {
+					// Planning failed: retry or go back
+					if m.cursor == 0 {
+						return m.startSplitPlan()
+					}
+					m = m.goBack()
+				} else if m.cursor == 0 {
+					return m.startSplit()
+				} else if m.cursor == 1 {
+					return m.startSplitPlan()
+				} else {
+					m = m.goBack()
+				}
+			} else if m.phase == "verify_failed" {
+				if m.cursor == 0 {
+					return m.startVerify()
+				}
+				m.exitCode = exitVerifyFailure
+				return m, tea.Quit
 			} else if m.phase == "commit_error" {
 				if m.cursor == 0 {
 					// Retry
 					m.phase = "generating"
 					m.apiErrorMsg = ""
-					return m, generateCommitMsg(m.diff, m.commitTypes[m.typeSelected], m.scopeInput)
+					diff, _, _ := m.generationDiff()
+					return m.generate(m.commitRequest(diff))
+				} else if m.cursor == 1 {
+					// Build a message from the diff without the AI
+					m.apiErrorMsg = ""
+					m = m.useOfflineMessage()
 				} else {
 					// Enter commit message manually
 					m.phase = "manual_input"
@@ -550,97 +1114,273 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if m.phase == "pr_error" {
 				if m.cursor == 0 {
 					// Retry
-					m.phase = "pr_generating"
 					m.apiErrorMsg = ""
-					return m, generatePRContent(m.currentBranch)
+					return m.startPRGeneration()
 				} else if m.cursor == 1 {
 					// Enter PR details manually
 					m.phase = "pr_manual_title"
 					m.prTitle = ""
 					m.prBody = ""
+					m.prEditReturn = false
 					m.apiErrorMsg = ""
+					if getEffectiveConfig().UseEditor {
+						m.editorErr = ""
+						return m, openEditor(editTargetPR, "")
+					}
 				} else {
 					// Skip PR creation
 					m.phase = "exiting"
 					m.apiErrorMsg = ""
+					if m.prOnly && !m.didCommit {
+						// Nothing else was done in PR-only mode, so the run failed
+						m.exitCode = exitAPIFailure
+					}
 					return m, tea.Quit
 				}
 			} else if m.phase == "pr_manual_title" {
-				if len(m.prTitle) > prTitleMaxLen {
-					m.prTitle = m.prTitle[:prTitleMaxLen]
-				}
-				// Move to body input
-				m.phase = "pr_manual_body"
+				m.prTitle = truncateRunes(m.prTitle, prTitleMaxLen)
+				m = m.finishPRTitle()
 			} else if m.phase == "pr_manual_body" {
 				// Show PR preview before creating
-				m.phase = "pr_confirm"
-				m.cursor = 0
-				m.choices = []string{"Yes, create PR", "Edit title", "Edit body", "Skip"}
+				m = m.enterPRConfirmPhase()
+			} else if m.phase == "pr_base" {
+				// Regenerate from the chosen commits; labels from changed
+				// paths follow the new range
+				m.prBase = m.prBaseRefs[m.cursor]
+				m.prMeta = nil
+				return m.startPRGeneration()
 			} else if m.phase == "pr_confirm" {
-				if m.cursor == 0 {
+				if m.cursor == 0 && m.existingPR != nil {
+					if err := updatePR(m.existingPR, m.prTitle, m.prBody, m.prMeta); err != nil {
+						m.errorMsg = fmt.Sprintf("Error updating PR: %v", err)
+						m.exitCode = exitGHFailure
+						return m, tea.Quit
+					}
+					m.didUpdatePR = true
+					if m.autoMerge != "" {
+						if err := enableAutoMerge(m.existingPR.URL, m.autoMerge); err != nil {
+							m.autoMergeErr = err.Error()
+							m.exitCode = exitGHFailure
+						}
+					}
+					m.phase = "pr_creating"
+					return m, tea.Quit
+				} else if m.cursor == 0 {
 					// Create the PR
-					if err := createPR(m.prTitle, m.prBody); err != nil {
+					url, err := createPR(m.prTitle, m.prBody, m.prMeta, m.prTarget)
+					if err != nil {
 						m.errorMsg = fmt.Sprintf("Error creating PR: %v", err)
+						m.exitCode = exitGHFailure
 						return m, tea.Quit
 					}
 					m.didCreatePR = true
+					if m.autoMerge != "" {
+						if err := enableAutoMerge(url, m.autoMerge); err != nil {
+							// The PR exists; report the failure without undoing it
+							m.autoMergeErr = err.Error()
+							m.exitCode = exitGHFailure
+						}
+					}
 					m.phase = "pr_creating"
 					return m, tea.Quit
 				} else if m.cursor == 1 {
-					// Edit title
+					// Edit the generated title in place
 					m.phase = "pr_manual_title"
+					m.prEditReturn = true
 				} else if m.cursor == 2 {
-					// Edit body
+					// Edit the generated body in place
 					m.phase = "pr_manual_body"
+					m.prEditReturn = true
+				} else if m.cursor == 3 {
+					m.editorErr = ""
+					return m, openEditor(editTargetPR, m.prTitle+"\n\n"+m.prBody)
+				} else if m.cursor == 4 {
+					return m.startPRGeneration()
 				} else {
 					// Skip
 					m.phase = "exiting"
+					if m.prOnly && !m.didCommit {
+						m.exitCode = exitUserAborted
+					}
 					return m, tea.Quit
 				}
 			}
 
 		case "backspace":
-			if m.phase == "branch_input" && len(m.branchInput) > 0 {
-				m.branchInput = m.branchInput[:len(m.branchInput)-1]
-			} else if m.phase == "scope" && len(m.scopeInput) > 0 {
-				m.scopeInput = m.scopeInput[:len(m.scopeInput)-1]
-			} else if (m.phase == "edit" || m.phase == "manual_input") && len(m.generatedMsg) > 0 {
-				m.generatedMsg = m.generatedMsg[:len(m.generatedMsg)-1]
-			} else if m.phase == "pr_manual_title" && len(m.prTitle) > 0 {
-				m.prTitle = m.prTitle[:len(m.prTitle)-1]
-			} else if m.phase == "pr_manual_body" && len(m.prBody) > 0 {
-				m.prBody = m.prBody[:len(m.prBody)-1]
+			if m.phase == "branch_input" {
+				m.branchInput = trimLastRune(m.branchInput)
+			} else if m.phase == "scope" {
+				m.scopeInput = trimLastRune(m.scopeInput)
+			} else if m.phase == "intent" {
+				m.intent = trimLastRune(m.intent)
+			} else if m.phase == "edit" || m.phase == "manual_input" {
+				m.generatedMsg = trimLastRune(m.generatedMsg)
+			} else if m.phase == "pr_manual_title" {
+				m.prTitle = trimLastRune(m.prTitle)
+			} else if m.phase == "pr_manual_body" {
+				m.prBody = trimLastRune(m.prBody)
 			}
 
 		default:
-			if m.phase == "branch_input" && len(msg.String()) == 1 {
-				m.branchInput += msg.String()
-			} else if m.phase == "scope" && len(msg.String()) == 1 {
-				m.scopeInput += msg.String()
+			if m.phase == "type" && (m.reword != nil || m.emptyCommit) && (msg.String() == "u" || msg.String() == "x" || msg.String() == "s") {
+				// The commit's files are fixed; nothing to unstage, exclude, or split
+				return m, nil
+			} else if m.phase == "type" && msg.String() == "u" {
+				m = m.enterUnstagePhase()
+				if m.errorMsg != "" {
+					return m, tea.Quit
+				}
+			} else if m.phase == "type" && msg.String() == "c" && len(m.coAuthors) > 0 {
+				m = m.enterCoAuthorsPhase()
+			} else if m.phase == "coauthors" && msg.String() == " " {
+				author := m.coAuthors[m.cursor]
+				m.coAuthorsSelected[author] = !m.coAuthorsSelected[author]
+			} else if m.phase == "type" && msg.String() == "s" && m.splitTree == "" && !*offlineFlag && countStagedFiles() > 1 {
+				return m.startSplitPlan()
+			} else if m.phase == "type" && msg.String() == "b" {
+				m.breaking = !m.breaking
+			} else if m.phase == "pr_confirm" && msg.String() == "r" && m.prMeta != nil && len(m.prMeta.suggested) > 0 {
+				m.prMeta.useSuggested = !m.prMeta.useSuggested
+			} else if m.phase == "pr_confirm" && msg.String() == "b" {
+				m = m.enterPRBasePhase()
+			} else if m.phase == "type" && msg.String() == "x" {
+				m = m.enterExcludePhase()
+				if m.errorMsg != "" {
+					return m, tea.Quit
+				}
+			} else if m.phase == "add" || m.phase == "unstage" || m.phase == "exclude" {
+				switch msg.String() {
+				case " ":
+					if _, ok := m.selected[m.cursor]; ok {
+						delete(m.selected, m.cursor)
+					} else if m.cursor < len(m.files) {
+						m.selected[m.cursor] = struct{}{}
+					}
+				case "a":
+					if len(m.selected) == len(m.files) {
+						m.selected = make(map[int]struct{}, len(m.files))
+					} else {
+						for i := range m.files {
+							m.selected[i] = struct{}{}
+						}
+					}
+				case "t":
+					// Tracked changes only: drop untracked and ignored files
+					if m.phase == "add" {
+						m.selected = make(map[int]struct{}, len(m.files))
+						for i, f := range m.files {
+							if f.Status != "??" && f.Status != "!!" {
+								m.selected[i] = struct{}{}
+							}
+						}
+					}
+				case "i":
+					if m.phase == "add" {
+						m = m.toggleIgnoredFiles()
+						if m.errorMsg != "" {
+							return m, tea.Quit
+						}
+					}
+				}
+			} else if m.phase == "branch_input" {
+				if text, ok := keyInput(msg, false); ok {
+					m.branchInput += text
+				}
+			} else if m.phase == "scope" {
+				if text, ok := keyInput(msg, false); ok {
+					m.scopeInput += text
+				}
+			} else if m.phase == "intent" {
+				if text, ok := keyInput(msg, false); ok {
+					m.intent += text
+				}
 			} else if m.phase == "edit" || m.phase == "manual_input" {
 				if msg.String() == "enter" {
 					m.generatedMsg += "\n"
-				} else if len(msg.String()) == 1 {
-					m.generatedMsg += msg.String()
+				} else if text, ok := keyInput(msg, true); ok {
+					m.generatedMsg += text
 				}
 			} else if m.phase == "pr_manual_title" {
-				if len(msg.String()) == 1 && len(m.prTitle) < prTitleMaxLen {
-					m.prTitle += msg.String()
+				if text, ok := keyInput(msg, false); ok {
+					m.prTitle += text
+					m.prTitle = truncateRunes(m.prTitle, prTitleMaxLen)
 				}
 			} else if m.phase == "pr_manual_body" {
 				if msg.String() == "enter" {
 					m.prBody += "\n"
-				} else if len(msg.String()) == 1 {
-					m.prBody += msg.String()
+				} else if text, ok := keyInput(msg, true); ok {
+					m.prBody += text
 				}
 			}
 		}
 
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case editorFinishedMsg:
+		if msg.err != nil {
+			m.editorErr = msg.err.Error()
+			return m, nil
+		}
+		if msg.target == editTargetCommit {
+			if msg.content != "" {
+				m.generatedMsg = msg.content
+				m.keepDraft()
+			}
+			m = m.enterConfirmPhase()
+		} else if msg.target == editTargetPR {
+			if msg.content != "" {
+				m.prTitle, m.prBody = splitTitleBody(msg.content)
+				m.prTitle = truncateRunes(m.prTitle, prTitleMaxLen)
+			}
+			if m.prTitle == "" {
+				// Nothing usable came back; fall back to the inline inputs
+				m.phase = "pr_manual_title"
+			} else {
+				m = m.enterPRConfirmPhase()
+			}
+		} else if msg.target == editTargetPRTitle {
+			if title, _, _ := strings.Cut(msg.content, "\n"); strings.TrimSpace(title) != "" {
+				m.prTitle = truncateRunes(strings.TrimSpace(title), prTitleMaxLen)
+			}
+			m = m.finishPRTitle()
+		} else if msg.target == editTargetPRBody {
+			if msg.content != "" {
+				m.prBody = msg.content
+			}
+			m = m.enterPRConfirmPhase()
+		}
+
 	case commitMsgMsg:
-		m.generatedMsg = string(msg)
-		m.phase = "confirm"
-		m.cursor = 0
-		m.choices = []string{"Yes, commit", "No, let me edit"}
+		m.generatedMsg = m.finalizeMessage(string(msg))
+		if violations := m.messageViolations(m.generatedMsg); len(violations) > 0 && m.lintRetries < maxLintRetries {
+			// Regenerate with the broken rules spelled out
+			m.lintRetries++
+			debugf("commitlint retry %d: %s", m.lintRetries, strings.Join(violations, "; "))
+			diff, _, _ := m.generationDiff()
+			req := m.commitRequest(diff)
+			req.Previous, req.Violations = m.generatedMsg, violations
+			m.promptNote = tr("Regenerating: the message broke %d rule(s)", len(violations))
+			m, ctx, tick := m.beginRequest(getEffectiveConfig().GetCommitModel())
+			return m, tea.Batch(tick, generateCommitMsg(ctx, req))
+		}
+		m.keepDraft()
+		m = m.enterConfirmPhase()
+
+	case candidateMsg:
+		return m.receiveCandidate(msg)
+
+	case requestTickMsg:
+		return m.tickRequest(msg)
+
+	case startRequestMsg:
+		if m.phase == "review_generating" {
+			return m.requestReview()
+		}
+		return m.startPRGeneration()
+
+	case prPrefetchMsg:
+		return m.receivePrefetchedPR(msg)
 
 	case prContentMsg:
 		parts := strings.SplitN(string(msg), "\n---BODY---\n", 2)
@@ -651,100 +1391,652 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.prTitle = string(msg)
 			m.prBody = ""
 		}
-		// Truncate title if it exceeds GitHub's limit
-		if len(m.prTitle) > prTitleMaxLen {
-			m.prTitle = m.prTitle[:prTitleMaxLen]
+		config := getEffectiveConfig()
+		if conventionalPRTitles() {
+			m.prTitle = normalizePRTitle(m.prTitle, commitTypesFor(config), branchSubjects(m.prBaseRef(), m.currentBranch))
 		}
-		m.phase = "pr_confirm"
-		m.cursor = 0
-		m.choices = []string{"Yes, create PR", "Edit title", "Edit body", "Skip"}
+		m.prTitle, m.prBody = addPRTicket(config, m.prTitle, m.prBody, m.trailerContext())
+		// Truncate title if it exceeds GitHub's limit
+		m.prTitle = truncateRunes(m.prTitle, prTitleMaxLen)
+		m = m.enterPRConfirmPhase()
 
 	case branchCreatedMsg:
 		// Branch created successfully, update current branch name
+		if m.moveCommits {
+			m.movedFrom = m.currentBranch
+		}
 		m.createdBranch = string(msg)
 		m.currentBranch = string(msg)
 		// Continue to normal flow
 		if m.needsAdd {
-			m.phase = "add"
-			m.cursor = 0
-			m.choices = []string{"Yes, add all changes", "No, exit"}
+			m = m.enterAddPhase()
+			if m.errorMsg != "" {
+				return m, tea.Quit
+			}
 		} else {
-			m.phase = "type"
+			m = m.enterTypePhase()
+			return m.continueToGeneration()
+		}
+
+	case startGenerationMsg:
+		return m.startGeneration()
+
+	case verifyTickMsg:
+		if m.phase == "verifying" {
+			return m, verifyTick()
+		}
+
+	case verifyDoneMsg:
+		if m.phase != "verifying" {
+			return m, nil // The user went back while the check ran
+		}
+		m.pending = nil
+		if msg.err != nil {
+			m = m.enterVerifyFailedPhase(msg.output)
+			return m, nil
 		}
+		m.verified = true
+		return m.startGeneration()
 
 	case errMsg:
 		m.errorMsg = string(msg)
+		m.exitCode = exitError
 		return m, tea.Quit
 
+	case reviewMsg:
+		m.reviewText = string(msg)
+		m.phase = "review"
+		m.cursor = 0
+		m.choices = []string{tr("Continue to commit"), tr("Quit to address the review")}
+
+	case reviewErrMsg:
+		m.apiErrorMsg = string(msg)
+		m.phase = "review_error"
+		m.cursor = 0
+		m.choices = []string{tr("Retry"), tr("Continue to commit without a review"), tr("Quit")}
+
 	case commitMsgErrMsg:
 		m.apiErrorMsg = string(msg)
 		m.phase = "commit_error"
 		m.cursor = 0
-		m.choices = []string{"Retry", "Enter commit message manually"}
+		m.choices = []string{tr("Retry"), tr("Generate offline (no AI)"), tr("Enter commit message manually")}
+
+	case splitPlanMsg:
+		if m.phase != "split_planning" {
+			return m, nil // The user went back while the plan was generated
+		}
+		m = m.enterSplitPlanPhase(msg)
+
+	case splitPlanErrMsg:
+		if m.phase != "split_planning" {
+			return m, nil
+		}
+		m.apiErrorMsg = string(msg)
+		m.phase = "split_plan"
+		m.cursor = 0
+		m.splitGroups = nil
+		m.choices = []string{tr("Retry"), tr("Cancel")}
 
 	case prContentErrMsg:
 		m.apiErrorMsg = string(msg)
 		m.phase = "pr_error"
 		m.cursor = 0
-		m.choices = []string{"Retry", "Enter PR details manually", "Skip PR creation"}
+		m.choices = []string{tr("Retry"), tr("Enter PR details manually"), tr("Skip PR creation")}
 	}
 
 	return m, nil
 }
 
+// keyInput returns the text a key event should insert into an input field.
+// Bracketed paste events carry their full content; single-line inputs have
+// pasted newlines replaced with spaces. ok is false for non-text keys.
+func keyInput(msg tea.KeyMsg, multiline bool) (string, bool) {
+	if msg.Paste {
+		text := strings.ReplaceAll(string(msg.Runes), "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+		if !multiline {
+			text = strings.TrimSpace(strings.ReplaceAll(text, "\n", " "))
+		}
+		return text, text != ""
+	}
+	if msg.Type == tea.KeySpace {
+		return " ", true
+	}
+	if msg.Type != tea.KeyRunes || msg.Alt {
+		return "", false
+	}
+	return string(msg.Runes), true
+}
+
+// trimLastRune removes the last character from s without splitting
+// multi-byte UTF-8 sequences
+func trimLastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
+
+// truncateRunes shortens s to at most n characters
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// wrap renders text with style, wrapping it to the terminal width once known.
+// indent reserves columns already used on the first line (e.g. a label).
+func (m model) wrap(style lipgloss.Style, text string, indent ...int) string {
+	width := m.width
+	for _, n := range indent {
+		width -= n
+	}
+	if width > 0 {
+		style = style.Width(width)
+	}
+	return style.Render(text)
+}
+
+// startGeneration runs the secret scan and then either generates a commit
+// message or, for oversized diffs, falls back to manual entry
+func (m model) startGeneration() (model, tea.Cmd) {
+	if m.shouldOfferStash() {
+		return m.enterStashPromptPhase()
+	}
+	// An empty commit changes nothing the verify command could check
+	if m.verifyCommand != "" && !m.verified && !m.emptyCommit {
+		return m.startVerify()
+	}
+
+	scanMode := getEffectiveConfig().SecretScan
+	if scanMode != secretScanOff && !m.secretsAcknowledged {
+		m.secretFindings = scanDiffForSecrets(m.diff, m.promptAutoExclude)
+		if len(m.secretFindings) > 0 {
+			m.phase = "secrets_warning"
+			m.cursor = 0
+			if scanMode == secretScanBlock {
+				m.choices = []string{tr("Abort")}
+			} else {
+				m.choices = []string{tr("Continue anyway"), tr("Don't send to AI, enter message manually"), tr("Abort")}
+			}
+			return m, nil
+		}
+	}
+
+	if *offlineFlag {
+		return m.useOfflineMessage(), nil
+	}
+
+	// Check if diff is too large even after truncation and summarizing
+	diff, note, ok := m.generationDiff()
+	if !ok {
+		m.phase = "manual_input"
+		m.generatedMsg = "" // Start with empty message for manual input
+		return m, nil
+	}
+	m.promptNote = note
+	m.phase = "generating"
+	m.lintRetries = 0
+	return m.generate(m.commitRequest(diff))
+}
+
+// generationDiff returns the prompt diff trimmed to the commit model's token
+// budget, falling back to a --stat summary when trimming is not enough.
+// note describes the fallback if one was used; ok is false if nothing fits.
+func (m model) generationDiff() (diff, note string, ok bool) {
+	config := getEffectiveConfig()
+	config.Model = config.GetCommitModel()
+	budget := diffTokenBudget(config, config.GetCommitParams().MaxTokens)
+
+	promptDiff := m.promptDiff()
+	if diff, ok := fitDiffToBudget(promptDiff, budget, config.MaxDiffLines); ok {
+		return diff, "", true
+	}
+
+	stat, err := m.diffStat()
+	if err != nil {
+		debugf("diff stat fallback unavailable: %v", err)
+		return "", "", false
+	}
+	stat = applyRedactions(stat, m.promptRedactions)
+	if diff, ok := statFallbackDiff(stat, promptDiff, budget, config.MaxDiffLines); ok {
+		return diff, tr(statFallbackNote), true
+	}
+	return "", "", false
+}
+
+// diffStat returns the --stat summary of the changes being described
+func (m model) diffStat() (string, error) {
+	if m.squashBase != "" {
+		return getRangeDiffStat(m.squashBase, m.reword.sha)
+	}
+	if m.reword != nil {
+		return getCommitDiffStat(m.reword.sha)
+	}
+	return worktree.StagedDiffStat()
+}
+
+// trailerPreview lists the trailers git will add to the message on commit
+func (m model) trailerPreview() string {
+	opts := m.commitOptions()
+	var lines []string
+	for _, trailer := range opts.Trailers {
+		lines = append(lines, "  "+trailer)
+	}
+	if opts.Signoff {
+		lines = append(lines, "  Signed-off-by: "+m.committerIdent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commit runs git commit with the confirmed message. A commit rejected by
+// hooks moves to the hook_failed phase instead of exiting.
+func (m model) commit(opts commitOptions) (tea.Model, tea.Cmd) {
+	if m.rewordQueue != nil {
+		return m.acceptReword(m.generatedMsg)
+	}
+	if m.squashBase != "" && !m.squashReset {
+		if err := gitResetSoft(m.squashBase); err != nil {
+			m.errorMsg = fmt.Sprintf("Error squashing: %v", err)
+			m.exitCode = exitGitFailure
+			return m, tea.Quit
+		}
+		m.squashReset = true
+	}
+	staged := countStagedFiles()
+	if err := worktree.Commit(m.generatedMsg, opts); err != nil {
+		if output, ok := hookFailure(err); ok {
+			return m.enterHookFailedPhase(output), nil
+		}
+		m.errorMsg = fmt.Sprintf("Error committing: %v", err)
+		m.exitCode = exitGitFailure
+		return m, tea.Quit
+	}
+	m.dropDraft()
+	m.didCommit = true
+	m.filesCommitted += staged
+	m.commits++
+	m = m.recordNoteCommit()
+	var err error
+	if m, err = m.restoreUnstaged(); err != nil {
+		m.errorMsg = fmt.Sprintf("Committed, but could not restore your unstaged changes: %v", err)
+		m.exitCode = exitGitFailure
+		// Already reported; don't try again on exit
+		m.unstagedStash = ""
+		return m, tea.Quit
+	}
+	if m.squashBase != "" {
+		m.squashReset = false
+		return m.finishSquash()
+	}
+	if m.reword != nil {
+		m.phase = "done"
+		return m, tea.Quit
+	}
+	if m.splitPending() {
+		m.splitIndex++
+		return m.stageSplitGroup()
+	}
+	m.splitTree = ""
+	if m.loop {
+		if changed, err := getGitStatus(); err == nil && changed {
+			m.phase = "loop_prompt"
+			m.cursor = 0
+			m.choices = []string{tr("Stage more changes for another commit"), tr("Done, continue")}
+			return m, nil
+		}
+	}
+	return m.enterPushPhase().prefetchPRContent()
+}
+
+// enterPRPromptPhase offers to create a PR, or to regenerate the title and
+// body of the branch's open PR, and to squash the branch into one commit
+// first when it has several
+func (m model) enterPRPromptPhase() model {
+	m.phase = "pr_prompt"
+	m.cursor = 1
+	if m.existingPR != nil {
+		m.choices = []string{tr("Yes, update PR #%d", m.existingPR.Number), tr("No, skip")}
+		if branchCommitCount(defaultBaseRef()) > 1 {
+			m.choices = append(m.choices, tr("Squash into one commit, then update PR"))
+		}
+		return m
+	}
+	m.choices = []string{tr("Yes, create PR"), tr("No, skip")}
+	if branchCommitCount(defaultBaseRef()) > 1 {
+		m.choices = append(m.choices, tr("Squash into one commit, then create PR"))
+	}
+	return m
+}
+
+// enterPushPhase offers to push once the commits are made, for review in
+// a Gerrit project
+func (m model) enterPushPhase() model {
+	m.phase = "push_prompt"
+	m.cursor = 1
+	m.choices = []string{tr("Yes, push"), tr("No, skip")}
+	if m.gerrit != nil {
+		m.choices[0] = tr("Yes, push for review (%s HEAD:refs/for/%s)", m.gerrit.remote, m.gerrit.branch)
+	}
+	return m
+}
+
+// nextCommit clears the state of the commit just made and returns to the
+// file picker for the next one
+func (m model) nextCommit() model {
+	m.generatedMsg = ""
+	m.scopeInput = ""
+	m.typeSelected = 0
+	m.breaking = false
+	m.promptExcluded = nil
+	m.secretFindings = nil
+	m.secretsAcknowledged = false
+	m.verified = false
+	m.hookOutput = ""
+	m.lintRetries = 0
+	m.splitGroups = nil
+	m.emptyCommit = false
+	m.commitOpts.AllowEmpty = false
+	m.intent = ""
+	m.stashAsked = false
+	if m.changeID != "" {
+		m.changeID = newChangeID()
+	}
+	m.needsAdd = true
+	return m.enterAddPhase()
+}
+
+// finalizeMessage applies the breaking-change marker, the branch's ticket,
+// 50/72 wrapping, and git's commit.template to a generated message
+func (m model) finalizeMessage(message string) string {
+	if m.revert != nil {
+		message = revertMessage(message, m.revert)
+	}
+	if m.merge != nil {
+		message = mergeMessage(message, m.merge)
+	}
+	if m.breaking {
+		message = markBreaking(message)
+	}
+	message = dropEmptyScope(message)
+	if m.verbosity == generate.VerbosityTerse && m.revert == nil && m.merge == nil {
+		message = terseMessage(message)
+	}
+	config := getEffectiveConfig()
+	ctx := m.trailerContext()
+	ctx.Type = messageType(message)
+	message = addTicket(config, message, ctx)
+	if m.strict5072 {
+		message = wrapBody(message, generate.StrictBodyWidth)
+	}
+	return mergeCommitTemplate(message, getCommitTemplate())
+}
+
+// useOfflineMessage fills in a heuristic commit message built locally from
+// the diff and moves to the confirm phase
+func (m model) useOfflineMessage() model {
+	message := heuristicCommitMsg(m.diff, m.selectedType().Name, m.scopeInput)
+	if m.emptyCommit && m.intent != "" {
+		// There are no changes to go on, so the user's intent is the subject
+		prefix, _, _ := strings.Cut(message, ": ")
+		message = prefix + ": " + m.intent
+	}
+	m.generatedMsg = m.finalizeMessage(message)
+	m.keepDraft()
+	m = m.enterConfirmPhase()
+	return m
+}
+
+// goBack returns the model moved back one phase, resetting the choices the
+// previous phase expects. Phases after the commit (and in-flight generation)
+// cannot be undone, so esc is ignored there.
+func (m model) goBack() model {
+	switch m.phase {
+	case "branch_conflict":
+		m.phase = "branch_input"
+	case "branch_input":
+		m = m.enterBranchWarningPhase()
+	case "add":
+		if m.commits > 0 {
+			// Looping: back to the choice between another commit and pushing
+			m.phase = "loop_prompt"
+			m.cursor = 0
+			m.choices = []string{tr("Stage more changes for another commit"), tr("Done, continue")}
+		} else if m.isProtectedBranch && m.createdBranch == "" {
+			m = m.enterBranchWarningPhase()
+		}
+	case "type":
+		if m.reword != nil {
+			// Rewording and squashing start at the type screen
+			break
+		}
+		if m.needsAdd {
+			m = m.enterAddPhase()
+		} else if m.isProtectedBranch && m.createdBranch == "" {
+			m = m.enterBranchWarningPhase()
+		}
+	case "scope", "unstage", "exclude", "coauthors":
+		m.phase = "type"
+	case "split_planning", "split_plan":
+		m.phase = "type"
+		m.splitGroups = nil
+		m.apiErrorMsg = ""
+	case "intent":
+		if m.revert == nil {
+			m.phase = m.scopePhase()
+		}
+	case "confirm", "candidates", "manual_input", "commit_error", "secrets_warning", "verifying", "verify_failed", "stash_prompt":
+		if m.merge != nil {
+			// A merge starts at generation; there is nothing to go back to
+			break
+		}
+		m.phase = m.scopePhase()
+		if m.revert != nil || m.emptyCommit {
+			m.phase = "intent"
+		}
+		m.apiErrorMsg = ""
+	case "edit", "hook_failed":
+		m = m.enterConfirmPhase()
+	case "pr_manual_title", "pr_base":
+		m = m.enterPRConfirmPhase()
+	case "pr_manual_body":
+		if m.prEditReturn {
+			m = m.enterPRConfirmPhase()
+		} else {
+			m.phase = "pr_manual_title"
+		}
+	}
+	return m
+}
+
+// quitExitCode returns the exit code for an explicit quit (q or ctrl+c).
+// Quitting from an API error screen reports the API failure; quitting once a
+// commit or PR exists is a normal exit.
+func (m model) quitExitCode() int {
+	if m.phase == "commit_error" || m.phase == "pr_error" {
+		return exitAPIFailure
+	}
+	if m.phase == "hook_failed" {
+		return exitGitFailure
+	}
+	if m.phase == "branch_conflict" {
+		// The branch name git would reject was never created
+		return exitError
+	}
+	if m.phase == "verify_failed" {
+		return exitVerifyFailure
+	}
+	if m.didCommit || m.didCreatePR || m.didUpdatePR {
+		return exitOK
+	}
+	return exitUserAborted
+}
+
+// messageViolations checks message against the repository's commitlint
+// rules and, if enabled, the 50/72 subject limit
+func (m model) messageViolations(message string) []string {
+	// Merge commits keep git's subject, which commitlint ignores too
+	if m.merge != nil {
+		return nil
+	}
+	violations := m.commitlint.validate(message)
+	if m.strict5072 {
+		violations = append(violations, subjectViolations(message)...)
+	}
+	return violations
+}
+
+// lintView lists the message rules the message still breaks
+func (m model) lintView() string {
+	violations := m.messageViolations(m.generatedMsg)
+	if len(violations) == 0 {
+		return ""
+	}
+	s := tr("Breaks the repository's message rules:") + "\n  - " + strings.Join(violations, "\n  - ")
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(s) + "\n\n"
+}
+
+// editorErrView renders the last editor failure, if any
+func (m model) editorErrView() string {
+	if m.editorErr == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(tr("Editor failed: %s", m.editorErr)) + "\n\n"
+}
+
 func (m model) getSummary() string {
+	if m.squashBase != "" && m.didCommit {
+		summary := tr("Squashed %d commits into one on branch %s", m.squashCount, m.currentBranch)
+		if m.didPush {
+			summary += " " + m.pushSummary()
+		}
+		if pr := m.prSummary(); pr != "" {
+			summary += " " + pr
+		}
+		return summary
+	}
+
+	if m.merge != nil && m.didCommit {
+		summary := tr("Committed the merge on branch %s", m.currentBranch)
+		if m.didPush {
+			summary += " " + m.pushSummary()
+		}
+		if pr := m.prSummary(); pr != "" {
+			summary += " " + pr
+		}
+		return summary
+	}
+
+	if m.revert != nil && m.didCommit {
+		summary := tr("Reverted %s on branch %s", m.revert.sha[:7], m.currentBranch)
+		if m.didPush {
+			summary += " " + m.pushSummary()
+		}
+		if pr := m.prSummary(); pr != "" {
+			summary += " " + pr
+		}
+		return summary
+	}
+
 	// PR-only mode summary
 	if m.prOnly && m.didCreatePR {
-		return fmt.Sprintf("Created PR on branch %s", m.currentBranch)
+		return tr("Created PR on branch %s", m.currentBranch)
+	}
+	if m.prOnly && m.didUpdatePR {
+		return tr("Updated PR #%d on branch %s", m.existingPR.Number, m.currentBranch)
 	}
 
 	if !m.didCommit {
 		return ""
 	}
+	if m.rewordQueue != nil {
+		return tr("Reworded %d of %d commits on branch %s", len(m.rewordEdits), len(m.rewordQueue), m.currentBranch)
+	}
+	if m.reword != nil {
+		return tr("Reworded the last commit on branch %s", m.currentBranch)
+	}
 
 	var parts []string
 
 	// Files committed
-	fileWord := "file"
+	committed := tr("Committed %d file", m.filesCommitted)
 	if m.filesCommitted != 1 {
-		fileWord = "files"
+		committed = tr("Committed %d files", m.filesCommitted)
+	}
+	if m.emptyCommit {
+		committed = tr("Made an empty commit")
+	}
+	parts = append(parts, committed)
+	if m.commits > 1 {
+		parts = append(parts, tr("in %d commits", m.commits))
 	}
-	parts = append(parts, fmt.Sprintf("Committed %d %s", m.filesCommitted, fileWord))
 
 	// Branch info
 	if m.createdBranch != "" {
-		parts = append(parts, fmt.Sprintf("to new branch %s", m.createdBranch))
+		parts = append(parts, tr("to new branch %s", m.createdBranch))
+		if m.movedFrom != "" {
+			parts = append(parts, tr("(moving %d earlier commits off %s)", m.unpushedCommits, m.movedFrom))
+		}
 	} else {
-		parts = append(parts, fmt.Sprintf("to branch %s", m.currentBranch))
+		parts = append(parts, tr("to branch %s", m.currentBranch))
 	}
 
 	// Push info
 	if m.didPush {
-		parts = append(parts, "and pushed")
+		parts = append(parts, m.pushSummary())
 	}
 
 	// PR info
-	if m.didCreatePR {
-		parts = append(parts, "and created PR")
+	if pr := m.prSummary(); pr != "" {
+		parts = append(parts, pr)
 	}
 
 	return strings.Join(parts, " ")
 }
 
+// pushSummary describes the push for the exit summary
+func (m model) pushSummary() string {
+	if m.gerrit != nil {
+		return tr("and pushed for review")
+	}
+	return tr("and pushed")
+}
+
+// prSummary describes what was done to the PR for the exit summary
+func (m model) prSummary() string {
+	if m.didCreatePR {
+		return tr("and created PR")
+	}
+	if m.didUpdatePR {
+		return tr("and updated PR #%d", m.existingPR.Number)
+	}
+	return ""
+}
+
+// noVerifyWarning reminds the user that hooks were skipped for the commit
+func (m model) noVerifyWarning() string {
+	if !(m.commitOpts.NoVerify || m.skippedHooks) || !m.didCommit {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(tr("⚠️  Git hooks were skipped (--no-verify)")) + "\n"
+}
+
 func (m model) View() string {
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
 	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
 
 	if m.errorMsg != "" {
-		return fmt.Sprintf("Error: %s\n", m.errorMsg)
+		return tr("Error: %s", m.errorMsg) + "\n"
 	}
 
 	if m.phase == "branch_warning" {
 		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
-		s := titleStyle.Render("⚠️  Warning: You are on a protected branch!") + "\n\n"
-		s += warningStyle.Render(fmt.Sprintf("Current branch: %s", m.currentBranch)) + "\n\n"
-		s += "Committing directly to main/master branches is not recommended.\n"
-		s += "Would you like to create a new branch instead?\n\n"
+		s := titleStyle.Render(tr("⚠️  Warning: You are on a protected branch!")) + "\n\n"
+		s += warningStyle.Render(tr("Current branch: %s", m.currentBranch)) + "\n\n"
+		s += tr("Committing directly to main/master branches is not recommended.") + "\n"
+		if m.unpushedCommits > 0 {
+			s += tr("%s already has %d commits that aren't on %s.", m.currentBranch, m.unpushedCommits, m.protectedUpstream) + "\n"
+		}
+		s += tr("Would you like to create a new branch instead?") + "\n\n"
 
 		for i, choice := range m.choices {
 			cursor := " "
@@ -754,25 +2046,126 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "branch_input" {
-		s := titleStyle.Render("Enter new branch name:") + "\n\n"
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(fmt.Sprintf("Suggested: %s", generateDefaultBranchName())) + "\n\n"
+		s := titleStyle.Render(tr("Enter new branch name:")) + "\n\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Suggested: %s", m.suggestedBranch)) + "\n\n"
 		s += fmt.Sprintf("> %s_\n\n", m.branchInput)
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("Tip: Use format like 'feature/description' or 'fix/issue-123'") + "\n"
-		s += "\n(type branch name, enter to create, q to quit)\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Tip: Use format like 'feature/description' or 'fix/issue-123'")) + "\n"
+		s += "\n" + tr("(type branch name, enter to create, q to quit)") + "\n"
+		return s
+	}
+
+	if m.phase == "branch_conflict" {
+		s := titleStyle.Render(tr("Can't create this branch")) + "\n\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(m.branchProblem) + "\n\n"
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "branch_creating" {
-		return titleStyle.Render(fmt.Sprintf("Creating and switching to branch '%s'...", m.branchInput)) + "\n"
+		return titleStyle.Render(tr("Creating and switching to branch '%s'...", m.branchInput)) + "\n"
 	}
 
 	if m.phase == "add" {
-		s := titleStyle.Render("No staged changes found. Would you like to add all changes?") + "\n\n"
+		s := titleStyle.Render(tr("No staged changes found. Select files to stage:")) + "\n\n"
+		if stagingPathspec() != nil {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Showing changes under %s only (stage_scope is \"cwd\")", startDir)) + "\n\n"
+		}
+		for i, f := range m.files {
+			cursor := " "
+			check := "[ ]"
+			if _, ok := m.selected[i]; ok {
+				check = "[x]"
+			}
+			line := fmt.Sprintf("%s %s %s", check, f.Status, f.Path)
+			if m.cursor == i {
+				cursor = ">"
+				line = selectedStyle.Render(line)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, line)
+		}
+		s += "\n" + tr("%d of %d files selected", len(m.selected), len(m.files))
+		if m.showIgnored {
+			s += tr(" (showing ignored files marked !!)")
+		}
+		s += "\n\n" + tr("(space to toggle, a to toggle all, t for tracked only, i to show/hide ignored files,\n enter to stage selected, q to quit)") + "\n"
+		return s
+	}
+
+	if m.phase == "exclude" {
+		s := titleStyle.Render(tr("Select files whose content may be sent to the AI:")) + "\n\n"
+		for i, f := range m.files {
+			cursor := " "
+			check := "[ ]"
+			if _, ok := m.selected[i]; ok {
+				check = "[x]"
+			}
+			line := fmt.Sprintf("%s %s %s", check, f.Status, f.Path)
+			if m.cursor == i {
+				cursor = ">"
+				line = selectedStyle.Render(line)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, line)
+		}
+		s += "\n" + tr("%d of %d files withheld (path only, still committed)", len(m.files)-len(m.selected), len(m.files)) + "\n"
+		s += "\n" + tr("(space to toggle, a to toggle all, enter to confirm, esc to go back)") + "\n"
+		return s
+	}
+
+	if m.phase == "coauthors" {
+		s := titleStyle.Render(tr("Select co-authors:")) + "\n\n"
+		for i, author := range m.coAuthors {
+			cursor := " "
+			check := "[ ]"
+			if m.coAuthorsSelected[author] {
+				check = "[x]"
+			}
+			line := fmt.Sprintf("%s %s", check, author)
+			if m.cursor == i {
+				cursor = ">"
+				line = selectedStyle.Render(line)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, line)
+		}
+		s += "\n" + tr("(space to toggle, enter when done, esc to go back)") + "\n"
+		return s
+	}
+
+	if m.phase == "unstage" {
+		s := titleStyle.Render(tr("Select staged files to unstage:")) + "\n\n"
+		for i, f := range m.files {
+			cursor := " "
+			check := "[ ]"
+			if _, ok := m.selected[i]; ok {
+				check = "[x]"
+			}
+			line := fmt.Sprintf("%s %s %s", check, f.Status, f.Path)
+			if m.cursor == i {
+				cursor = ">"
+				line = selectedStyle.Render(line)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, line)
+		}
+		s += "\n" + tr("%d of %d files will be unstaged", len(m.selected), len(m.files)) + "\n"
+		s += "\n" + tr("(space to toggle, a to toggle all, enter to unstage selected, esc to go back)") + "\n"
+		return s
+	}
+
+	if m.phase == "resume_draft" {
+		s := titleStyle.Render(tr("Unfinished commit message from a previous run (saved %s):", draftAge(m.savedDraft))) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("14")), m.savedDraft.Message) + "\n\n"
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -781,38 +2174,220 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "type" {
-		s := titleStyle.Render("Select commit type:") + "\n\n"
-		for i, commitType := range m.commitTypes {
+		s := titleStyle.Render(tr("Select commit type:")) + "\n\n"
+		nameWidth := 0
+		for _, t := range m.commitTypes {
+			nameWidth = max(nameWidth, utf8.RuneCountInString(t.Name))
+		}
+		descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+		for i, t := range m.commitTypes {
 			cursor := " "
+			name := fmt.Sprintf("%-*s", nameWidth, t.Name)
 			if m.typeSelected == i {
 				cursor = ">"
-				commitType = selectedStyle.Render(commitType)
+				name = selectedStyle.Render(name)
 			}
-			s += fmt.Sprintf("%s %s\n", cursor, commitType)
+			s += fmt.Sprintf("%s %s  %s\n", cursor, name, descStyle.Render(t.Description))
+		}
+		cursor, name := " ", fmt.Sprintf("%-*s", nameWidth, "auto")
+		if m.autoType() {
+			cursor, name = ">", selectedStyle.Render(name)
+		}
+		s += fmt.Sprintf("\n%s %s  %s\n", cursor, name, descStyle.Render(tr("Let the AI choose the type from the diff")))
+		if m.breaking {
+			s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true).Render(tr("⚠️  Breaking change: the message gets \"!\" and a BREAKING CHANGE footer")) + "\n"
+		}
+		if n := m.selectedCoAuthorCount(); n > 0 {
+			s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Co-authors: %d selected", n)) + "\n"
+		}
+		coAuthorHint := ""
+		if len(m.coAuthors) > 0 {
+			coAuthorHint = tr("c to pick co-authors, ")
+		}
+		if m.reword != nil || m.emptyCommit {
+			s += "\n" + tr("(use arrow keys to select, enter to confirm, b to toggle breaking change, %sq to quit)", coAuthorHint) + "\n"
+		} else {
+			s += "\n" + tr("(use arrow keys to select, enter to confirm, b to toggle breaking change, %su to unstage files, x to exclude files from AI, s to split into several commits, q to quit)", coAuthorHint) + "\n"
+		}
+		return s
+	}
+
+	if m.phase == "intent" {
+		dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+		if m.revert != nil {
+			s := m.revertView()
+			s += titleStyle.Render(tr("Why is this commit being reverted? (press enter when done):")) + "\n\n"
+			s += fmt.Sprintf("> %s_\n", m.intent)
+			s += "\n" + dimStyle.Render(tr("Leave it empty to let the AI work it out from the changes.")) + "\n"
+			return s
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s := titleStyle.Render(tr("What is this empty commit for? (press enter when done):")) + "\n\n"
+		s += fmt.Sprintf("> %s_\n", m.intent)
+		s += "\n" + dimStyle.Render(tr("For example: trigger a CI rebuild after the runner upgrade")) + "\n"
 		return s
 	}
 
 	if m.phase == "scope" {
-		s := titleStyle.Render(fmt.Sprintf("Enter scope for %s (press enter when done):", m.commitTypes[m.typeSelected])) + "\n\n"
+		s := titleStyle.Render(tr("Enter scope for %s (press enter when done):", m.typeLabel())) + "\n\n"
 		s += fmt.Sprintf("> %s_\n", m.scopeInput)
+		if len(m.scopePackages) > 1 {
+			warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+			s += "\n" + m.wrap(warningStyle, tr("⚠️  Changes span %d packages: %s", len(m.scopePackages), strings.Join(m.scopePackages, ", "))) + "\n"
+			s += m.wrap(warningStyle, tr("Consider splitting them into one commit per package (esc, then u to unstage files).")) + "\n"
+		}
+		if len(m.scopeSuggestions) > 0 {
+			dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+			s += "\n" + dimStyle.Render(tr("Suggestions:")) + "\n"
+			for _, scope := range m.scopeSuggestions {
+				if scope == m.scopeInput {
+					s += "  " + selectedStyle.Render(scope) + "\n"
+				} else {
+					s += "  " + dimStyle.Render(scope) + "\n"
+				}
+			}
+			s += "\n" + tr("(type a scope or use ↑/↓ to pick a suggestion, enter when done)") + "\n"
+		}
+		return s
+	}
+
+	if m.phase == "stash_prompt" {
+		s := titleStyle.Render(tr("You also have unstaged changes.")) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), tr("Stash them while committing so the verify command and git hooks see exactly what will be committed? They are restored afterwards.")) + "\n\n"
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
+		return s
+	}
+
+	if m.phase == "secrets_warning" {
+		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+		s := titleStyle.Render(tr("⚠️  Possible secrets in staged changes")) + "\n\n"
+		for _, f := range m.secretFindings {
+			s += warningStyle.Render(fmt.Sprintf("%s: %s", f.path, f.rule)) + "\n"
+			s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), "    "+f.line) + "\n"
+		}
+		s += "\n"
+		if len(m.choices) == 1 {
+			s += tr("Secret scanning is set to block. Remove the secrets and try again.") + "\n\n"
+		} else {
+			s += tr("These changes would be sent to the AI provider and committed.") + "\n\n"
+		}
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, esc to go back)") + "\n"
+		return s
+	}
+
+	if m.phase == "verifying" {
+		s := titleStyle.Render(tr("Running verify command...")) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), fmt.Sprintf("$ %s (%s)", m.verifyCommand, m.requestElapsed().Round(time.Second))) + "\n"
+		s += "\n" + tr("(esc to go back, q to quit)") + "\n"
+		return s
+	}
+
+	if m.phase == "verify_failed" {
+		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		s := titleStyle.Render(tr("⚠️  Verify command failed")) + "\n\n"
+		s += errorStyle.Render("$ "+m.verifyCommand) + "\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tailLines(m.verifyOutput, maxHookOutputLines)) + "\n\n"
+		s += titleStyle.Render(tr("Fix the problems above, then retry.")) + "\n\n"
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, esc to go back, q to quit)") + "\n"
+		return s
+	}
+
+	if m.phase == "split_planning" {
+		s := titleStyle.Render(tr("Planning commits...")) + "\n"
+		s += m.requestProgress()
+		if m.promptNote != "" {
+			s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(m.promptNote) + "\n"
+		}
+		s += "\n" + tr("(esc to go back, q to quit)") + "\n"
+		return s
+	}
+
+	if m.phase == "split_plan" {
+		var s string
+		if m.splitGroups == nil {
+			errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			s = errorStyle.Bold(true).Render(tr("⚠️  API Error")) + "\n\n"
+			s += tr("Failed to plan the split:") + "\n"
+			s += m.wrap(errorStyle, m.apiErrorMsg) + "\n\n"
+		} else {
+			s = titleStyle.Render(tr("Proposed commits:")) + "\n\n"
+			fileStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+			for i, g := range m.splitGroups {
+				s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("14")), fmt.Sprintf("%d. %s", i+1, g.label())) + "\n"
+				for _, f := range g.entries() {
+					s += fileStyle.Render("   "+f) + "\n"
+				}
+			}
+			s += "\n"
+		}
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, esc to go back)") + "\n"
 		return s
 	}
 
 	if m.phase == "generating" {
-		return titleStyle.Render("Generating commit message...") + "\n"
+		s := m.splitProgress()
+		s += titleStyle.Render(tr("Generating commit message...")) + "\n"
+		s += m.requestProgress()
+		if m.promptNote != "" {
+			s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(m.promptNote) + "\n"
+		}
+		return s
 	}
 
 	if m.phase == "confirm" {
-		s := titleStyle.Render("Generated commit message:") + "\n\n"
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Render(m.generatedMsg) + "\n\n"
-		s += titleStyle.Render("Use this message?") + "\n\n"
+		s := m.splitProgress()
+		s += m.rewordView()
+		s += m.revertView()
+		s += titleStyle.Render(tr("Generated commit message:")) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("14")), m.generatedMsg) + "\n\n"
+		if m.autoType() {
+			s += m.autoTypeNote()
+		}
+		if trailers := m.trailerPreview(); trailers != "" {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Added on commit:")+"\n"+trailers) + "\n\n"
+		}
+		if m.commitOpts.NoVerify {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(tr("Git hooks will be skipped (--no-verify)")) + "\n\n"
+		}
+		s += m.lintView()
+		s += m.editorErrView()
+		s += titleStyle.Render(tr("Use this message?")) + "\n\n"
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -821,30 +2396,52 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "edit" {
-		s := titleStyle.Render("Edit commit message (press enter when done):") + "\n\n"
-		s += fmt.Sprintf("%s_\n", m.generatedMsg)
+		s := titleStyle.Render(tr("Edit commit message (press enter when done):")) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle(), m.generatedMsg+"_") + "\n\n"
+		s += m.editorErrView()
+		s += tr("(ctrl+e to open in $EDITOR)") + "\n"
 		return s
 	}
 
 	if m.phase == "manual_input" {
 		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-		s := titleStyle.Render("⚠️  Large diff detected") + "\n\n"
-		s += warningStyle.Render(fmt.Sprintf("The diff is too large (>%d lines) to send to the API.", diffLineSizeLimit)) + "\n"
-		s += "Please enter your commit message manually:\n\n"
-		s += fmt.Sprintf("%s(%s): %s_\n\n", m.commitTypes[m.typeSelected], m.scopeInput, m.generatedMsg)
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("Tip: Follow conventional commits format") + "\n"
-		s += "\n(type your message, press enter when done)\n"
+		s := titleStyle.Render(tr("⚠️  Large diff detected")) + "\n\n"
+		s += warningStyle.Render(tr("The diff is too large to fit the model's context, even when truncated.")) + "\n"
+		s += tr("Please enter your commit message manually:") + "\n\n"
+		prefix := m.typeLabel()
+		if m.scopeInput != "" {
+			prefix += "(" + m.scopeInput + ")"
+		}
+		s += m.wrap(lipgloss.NewStyle(), fmt.Sprintf("%s: %s_", prefix, m.generatedMsg)) + "\n\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Tip: Follow conventional commits format")) + "\n"
+		s += m.editorErrView()
+		s += "\n" + tr("(type your message, press enter when done, ctrl+e to open in $EDITOR)") + "\n"
+		return s
+	}
+
+	if m.phase == "loop_prompt" {
+		s := titleStyle.Render(tr("✓ Commit created successfully!")) + "\n\n"
+		s += tr("Uncommitted changes remain.") + "\n\n"
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "push_prompt" {
-		s := titleStyle.Render("✓ Commit created successfully!") + "\n\n"
-		s += titleStyle.Render("Push to remote?") + "\n\n"
+		s := titleStyle.Render(tr("✓ Commit created successfully!")) + "\n\n"
+		s += titleStyle.Render(tr("Push to remote?")) + "\n\n"
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -853,13 +2450,17 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "upstream_prompt" {
-		s := titleStyle.Render("No upstream branch configured.") + "\n\n"
-		s += titleStyle.Render(fmt.Sprintf("Set upstream to 'origin/%s' and push?", m.currentBranch)) + "\n\n"
+		s := titleStyle.Render(tr("No upstream branch configured.")) + "\n\n"
+		if len(m.upstreamRemotes) > 1 {
+			s += titleStyle.Render(tr("Push %s to which remote?", m.currentBranch)) + "\n\n"
+		} else {
+			s += titleStyle.Render(tr("Set upstream to '%s/%s' and push?", m.upstreamRemotes[0], m.currentBranch)) + "\n\n"
+		}
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -868,12 +2469,15 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "pr_prompt" {
-		s := titleStyle.Render("Create a pull request?") + "\n\n"
+		s := titleStyle.Render(tr("Create a pull request?")) + "\n\n"
+		if m.existingPR != nil {
+			s = titleStyle.Render(tr("PR #%d is already open for this branch. Update its title and description from the latest commits?", m.existingPR.Number)) + "\n\n"
+		}
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -882,20 +2486,32 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "pr_generating" {
-		return titleStyle.Render("Generating PR title and body...") + "\n"
+		return titleStyle.Render(tr("Generating PR title and body...")) + "\n" + m.requestProgress()
+	}
+
+	if m.phase == "review_generating" {
+		return titleStyle.Render(tr("Reviewing the staged changes...")) + "\n" + m.requestProgress()
+	}
+
+	if m.phase == "review" || m.phase == "review_error" {
+		return m.reviewView()
+	}
+
+	if m.phase == "candidates" {
+		return m.candidatesView()
 	}
 
 	if m.phase == "commit_error" {
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-		s := titleStyle.Render("⚠️  API Error") + "\n\n"
-		s += errorStyle.Render("Failed to generate commit message:") + "\n"
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(m.apiErrorMsg) + "\n\n"
-		s += titleStyle.Render("What would you like to do?") + "\n\n"
+		s := titleStyle.Render(tr("⚠️  API Error")) + "\n\n"
+		s += errorStyle.Render(tr("Failed to generate commit message:")) + "\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), m.apiErrorMsg) + "\n\n"
+		s += titleStyle.Render(tr("What would you like to do?")) + "\n\n"
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -904,16 +2520,34 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
+		return s
+	}
+
+	if m.phase == "hook_failed" {
+		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+		s := titleStyle.Render(tr("⚠️  Commit rejected by hooks")) + "\n\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tailLines(m.hookOutput, maxHookOutputLines)) + "\n\n"
+		s += errorStyle.Render(tr("Fix the problems above (restage any files the hooks changed), then retry.")) + "\n\n"
+		s += titleStyle.Render(tr("What would you like to do?")) + "\n\n"
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, esc to go back, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "pr_error" {
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-		s := titleStyle.Render("⚠️  API Error") + "\n\n"
-		s += errorStyle.Render("Failed to generate PR content:") + "\n"
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(m.apiErrorMsg) + "\n\n"
-		s += titleStyle.Render("What would you like to do?") + "\n\n"
+		s := titleStyle.Render(tr("⚠️  API Error")) + "\n\n"
+		s += errorStyle.Render(tr("Failed to generate PR content:")) + "\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), m.apiErrorMsg) + "\n\n"
+		s += titleStyle.Render(tr("What would you like to do?")) + "\n\n"
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -922,44 +2556,61 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
 		return s
 	}
 
 	if m.phase == "pr_manual_title" {
-		s := titleStyle.Render("Enter PR title:") + "\n\n"
-		s += fmt.Sprintf("> %s_\n\n", m.prTitle)
+		s := titleStyle.Render(tr("Enter PR title:")) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle(), fmt.Sprintf("> %s_", m.prTitle)) + "\n\n"
 		counterColor := "8"
-		if len(m.prTitle) >= prTitleMaxLen {
+		if utf8.RuneCountInString(m.prTitle) >= prTitleMaxLen {
 			counterColor = "9"
 		}
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color(counterColor)).Render(fmt.Sprintf("(%d/%d characters)", len(m.prTitle), prTitleMaxLen)) + "\n"
-		s += "\n(type your title, press enter to continue to body)\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color(counterColor)).Render(tr("(%d/%d characters)", utf8.RuneCountInString(m.prTitle), prTitleMaxLen)) + "\n"
+		s += m.editorErrView()
+		if m.prEditReturn {
+			s += "\n" + tr("(edit the title, press enter to return to the preview, ctrl+e to open in $EDITOR)") + "\n"
+		} else {
+			s += "\n" + tr("(type your title, press enter to continue to body, ctrl+e to open in $EDITOR)") + "\n"
+		}
 		return s
 	}
 
 	if m.phase == "pr_manual_body" {
-		s := titleStyle.Render("Enter PR body:") + "\n\n"
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Render(fmt.Sprintf("Title: %s", m.prTitle)) + "\n\n"
-		s += fmt.Sprintf("%s_\n\n", m.prBody)
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("Tip: Describe your changes, press enter for newlines") + "\n"
-		s += "\n(type your body, press enter twice to continue)\n"
+		s := titleStyle.Render(tr("Enter PR body:")) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("14")), tr("Title: %s", m.prTitle)) + "\n\n"
+		s += m.wrap(lipgloss.NewStyle(), m.prBody+"_") + "\n\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Tip: Describe your changes, press enter for newlines")) + "\n"
+		s += m.editorErrView()
+		s += "\n" + tr("(type your body, press enter twice to continue, ctrl+e to open in $EDITOR)") + "\n"
 		return s
 	}
 
 	if m.phase == "pr_confirm" {
-		s := titleStyle.Render("PR Preview") + "\n\n"
-		s += lipgloss.NewStyle().Bold(true).Render("Title: ") + lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Render(m.prTitle) + "\n"
+		s := titleStyle.Render(tr("PR Preview")) + "\n\n"
+		titleLabel := tr("Title: ")
+		labelWidth := utf8.RuneCountInString(titleLabel)
+		s += lipgloss.NewStyle().Bold(true).Render(titleLabel) + m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("14")), m.prTitle, labelWidth) + "\n"
 		counterColor := "8"
-		if len(m.prTitle) > prTitleMaxLen {
+		if utf8.RuneCountInString(m.prTitle) > prTitleMaxLen {
 			counterColor = "9"
 		}
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color(counterColor)).Render(fmt.Sprintf("       (%d/%d characters)", len(m.prTitle), prTitleMaxLen)) + "\n\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color(counterColor)).Render(strings.Repeat(" ", labelWidth)+tr("(%d/%d characters)", utf8.RuneCountInString(m.prTitle), prTitleMaxLen)) + "\n"
+		if m.conventionalPRTitle && !validPRTitleType(m.prTitle, m.commitTypes) {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(strings.Repeat(" ", labelWidth)+tr("⚠️  Not in type(scope): subject format, which this repository's PR checks expect")) + "\n"
+		}
+		s += "\n"
 		if m.prBody != "" {
-			s += lipgloss.NewStyle().Bold(true).Render("Body:") + "\n"
-			s += lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Render(m.prBody) + "\n\n"
+			s += lipgloss.NewStyle().Bold(true).Render(tr("Body:")) + "\n"
+			s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("14")), m.prBody) + "\n\n"
+		}
+		s += m.prMetaView()
+		if m.existingPR != nil {
+			s += titleStyle.Render(tr("Replace the title and description of PR #%d?", m.existingPR.Number)) + "\n\n"
+		} else {
+			s += titleStyle.Render(tr("Create this PR?")) + "\n\n"
 		}
-		s += titleStyle.Render("Create this PR?") + "\n\n"
 		for i, choice := range m.choices {
 			cursor := " "
 			if m.cursor == i {
@@ -968,464 +2619,423 @@ func (m model) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		s += "\n(use arrow keys to select, enter to confirm, q to quit)\n"
-		return s
-	}
-
-	if m.phase == "pr_creating" {
-		summaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-		return summaryStyle.Render(m.getSummary()) + "\n"
-	}
-
-	if m.phase == "done" || m.phase == "exiting" {
-		if summary := m.getSummary(); summary != "" {
-			summaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-			return summaryStyle.Render(summary) + "\n"
-		}
-		return ""
-	}
-
-	return ""
-}
-
-type commitMsgMsg string
-type prContentMsg string
-type errMsg string
-type branchCreatedMsg string
-type commitMsgErrMsg string // API error during commit message generation
-type prContentErrMsg string // API error during PR content generation
-
-func generateCommitMsg(diff, commitType, scope string) tea.Cmd {
-	return func() tea.Msg {
-		config := getEffectiveConfig()
-		// Use the commit-specific model
-		config.Model = config.GetCommitModel()
-
-		prompt := fmt.Sprintf(`You are a commit message generator. Based on the following git diff, generate a concise commit message using conventional commits format.
-
-The commit type is: %s
-The scope is: %s
-
-Format: %s(%s): <description>
-
-The description should be:
-- Clear and concise (max 72 characters for the first line)
-- In imperative mood (e.g., "add" not "added")
-- Explain WHAT and WHY, not HOW
-
-If the changes warrant it, you can add a body after a blank line with more details.
-
-Git diff:
-%s
-
-Respond with ONLY the commit message, no explanations or markdown formatting.`, commitType, scope, commitType, scope, diff)
-
-		switch config.Provider {
-		case "ollama":
-			return generateWithOllama(config, prompt, 1024, false)
-		case "openai":
-			return generateWithOpenAI(config, prompt, 1024, false)
-		default:
-			return generateWithAnthropic(config, prompt, 1024, false)
+		if m.prMeta != nil && len(m.prMeta.suggested) > 0 {
+			s += "\n" + tr("(use arrow keys to select, enter to confirm, b to pick the commits, r to toggle suggested reviewers, q to quit)") + "\n"
+		} else {
+			s += "\n" + tr("(use arrow keys to select, enter to confirm, b to pick the commits, q to quit)") + "\n"
 		}
+		return s
 	}
-}
 
-// generateWithAnthropic sends a request to the Anthropic API
-func generateWithAnthropic(config *Config, prompt string, maxTokens int, isPR bool) tea.Msg {
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
-	if apiKey == "" {
-		if isPR {
-			return prContentErrMsg("ANTHROPIC_API_KEY environment variable not set")
+	if m.phase == "pr_base" {
+		s := titleStyle.Render(tr("Generate the PR from which commits?")) + "\n\n"
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
 		}
-		return commitMsgErrMsg("ANTHROPIC_API_KEY environment variable not set")
+		s += "\n" + tr("(use arrow keys to select, enter to confirm, esc to go back, q to quit)") + "\n"
+		return s
 	}
 
-	reqBody := AnthropicRequest{
-		Model:     config.Model,
-		MaxTokens: maxTokens,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+	if m.phase == "pr_creating" {
+		summaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		return summaryStyle.Render(m.getSummary()) + "\n" + m.autoMergeView() + m.noVerifyWarning()
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error marshaling request: %v", err))
+	if m.phase == "done" || m.phase == "exiting" {
+		if summary := m.getSummary(); summary != "" {
+			summaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+			return summaryStyle.Render(summary) + "\n" + m.gerritChangesView() + m.noVerifyWarning()
 		}
-		return commitMsgErrMsg(fmt.Sprintf("Error marshaling request: %v", err))
+		return ""
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	return ""
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", anthropicURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error creating request: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error creating request: %v", err))
-	}
+type commitMsgMsg string
+type startGenerationMsg struct{} // Starts generation for flows that open on it (batch reword, merges)
+type prContentMsg string
+type errMsg string
+type branchCreatedMsg string
+type commitMsgErrMsg string // API error during commit message generation
+type prContentErrMsg string // API error during PR content generation
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+// commitRequest is everything the commit message prompt is built from
+type commitRequest = generate.Request
+
+// commitRequest gathers the user's choices for a prompt over diff
+func (m model) commitRequest(diff string) commitRequest {
+	req := commitRequest{
+		Diff:      diff,
+		Type:      m.selectedType(),
+		Types:     m.commitTypes,
+		Scope:     m.scopeInput,
+		Breaking:  m.breaking,
+		Strict:    m.strict5072,
+		Verbosity: m.verbosity,
+		Examples:  m.styleExamples,
+		Exemplars: m.exemplars,
+		Glossary:  getEffectiveConfig().Glossary,
+	}
+	if m.squashBase != "" {
+		req.Squashed = m.reword.message
+	}
+	if m.revert != nil {
+		req.Type = revertType
+		req.Scope = ""
+		req.Reverted = m.revert.message
+	}
+	req.Merge = m.merge
+	req.Empty = m.emptyCommit
+	req.Intent = m.intent
+	return req
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error making request: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error making request: %v", err))
+// generateCommitMsg asks the commit model for a commit message
+func generateCommitMsg(ctx context.Context, req commitRequest) tea.Cmd {
+	return func() tea.Msg {
+		return generateCommitMsgWith(ctx, req, getEffectiveConfig().GetCommitModel())
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error reading response: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error reading response: %v", err))
-	}
+// generateCommitMsgWith asks the given model for a commit message
+func generateCommitMsgWith(ctx context.Context, req commitRequest, model string) tea.Msg {
+	c := getEffectiveConfig()
+	c.Model = model
 
-	if resp.StatusCode != http.StatusOK {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("API error (%d): %s", resp.StatusCode, string(body)))
+	data := generate.CommitPrompt(req)
+	prompt := data.Default
+	if req.Merge == nil { // Merges keep their own prompt
+		var err error
+		if prompt, err = renderPromptTemplate("commit", data, data.Default); err != nil {
+			return commitMsgErrMsg(err.Error())
 		}
-		return commitMsgErrMsg(fmt.Sprintf("API error (%d): %s", resp.StatusCode, string(body)))
 	}
 
-	var apiResp AnthropicResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error parsing response: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error parsing response: %v", err))
-	}
+	return generateMsg(ctx, c, prompt, c.GetCommitParams(), false)
+}
 
-	if len(apiResp.Content) == 0 {
-		if isPR {
-			return prContentErrMsg("No content in API response")
-		}
-		return commitMsgErrMsg("No content in API response")
+// generateMsg sends prompt to c's model and returns the response as the
+// message of a PR or a commit request: its content, an error, or
+// requestCanceledMsg when ctx was cancelled
+func generateMsg(ctx context.Context, c *Config, prompt string, params GenerationParams, isPR bool) tea.Msg {
+	result, err := generate.Text(ctx, c, prompt, params)
+	if errors.Is(err, context.Canceled) {
+		return requestCanceledMsg{}
 	}
-
-	result := strings.TrimSpace(apiResp.Content[0].Text)
 	if isPR {
+		if err != nil {
+			return prContentErrMsg(err.Error())
+		}
 		return prContentMsg(result)
 	}
+	if err != nil {
+		return commitMsgErrMsg(err.Error())
+	}
 	return commitMsgMsg(result)
 }
 
-// generateWithOllama sends a request to the Ollama API
-func generateWithOllama(config *Config, prompt string, _ int, isPR bool) tea.Msg {
-	reqBody := OllamaRequest{
-		Model: config.Model,
-		Messages: []OllamaMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: false,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
+func getGitStatus() (bool, error) {
+	cmd := exec.Command("git", append([]string{"status", "--porcelain"}, stagingPathspec()...)...)
+	output, err := runCommand(cmd)
 	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error marshaling request: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error marshaling request: %v", err))
+		return false, fmt.Errorf("git status failed: %w", err)
 	}
+	return len(output) > 0, nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second) // Longer timeout for local models
-	defer cancel()
-
-	ollamaEndpoint := config.OllamaURL + "/api/chat"
-	req, err := http.NewRequestWithContext(ctx, "POST", ollamaEndpoint, bytes.NewBuffer(jsonData))
+func countStagedFiles() int {
+	cmd := exec.Command("git", "diff", "--staged", "--name-only")
+	output, err := runCommand(cmd)
 	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error creating request: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error creating request: %v", err))
+		return 0
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return 0
 	}
+	return len(lines)
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// changedFile is an entry from git status shown in the add phase picker,
+// or a staged file
+type changedFile = gitops.File
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error making request to Ollama (%s): %v", ollamaEndpoint, err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error making request to Ollama (%s): %v", ollamaEndpoint, err))
+// listChangedFiles returns modified, deleted, renamed, and untracked files
+// from git status, plus ignored files when includeIgnored is set
+func listChangedFiles(includeIgnored bool) ([]changedFile, error) {
+	args := []string{"status", "--porcelain=v1", "-z"}
+	if includeIgnored {
+		args = append(args, "--ignored")
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	args = append(args, stagingPathspec()...)
+	cmd := exec.Command("git", args...)
+	output, err := runCommand(cmd)
 	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error reading response: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error reading response: %v", err))
+		return nil, fmt.Errorf("git status failed: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Ollama API error (%d): %s", resp.StatusCode, string(body)))
+	var files []changedFile
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
 		}
-		return commitMsgErrMsg(fmt.Sprintf("Ollama API error (%d): %s", resp.StatusCode, string(body)))
-	}
-
-	var apiResp OllamaResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error parsing response: %v", err))
+		status := entry[:2]
+		file := changedFile{Status: status, Path: entry[3:]}
+		// Renames and copies are followed by the original path
+		if (status[0] == 'R' || status[0] == 'C') && i+1 < len(entries) {
+			i++
+			file.OrigPath = entries[i]
 		}
-		return commitMsgErrMsg(fmt.Sprintf("Error parsing response: %v", err))
+		files = append(files, file)
 	}
+	return files, nil
+}
 
-	result := strings.TrimSpace(apiResp.Message.Content)
-	if result == "" {
-		if isPR {
-			return prContentErrMsg("No content in Ollama API response")
+// gitRestoreStaged removes files from the index, keeping working tree changes
+func gitRestoreStaged(files []changedFile) error {
+	args := []string{"restore", "--staged", "--"}
+	for _, f := range files {
+		args = append(args, f.Path)
+		if f.OrigPath != "" {
+			args = append(args, f.OrigPath)
 		}
-		return commitMsgErrMsg("No content in Ollama API response")
 	}
-
-	if isPR {
-		return prContentMsg(result)
+	cmd := exec.Command("git", args...)
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git restore --staged failed: %w\n%s", err, string(output))
 	}
-	return commitMsgMsg(result)
+	return nil
 }
 
-// generateWithOpenAI sends a request to an OpenAI-compatible API (e.g. LiteLLM)
-func generateWithOpenAI(config *Config, prompt string, maxTokens int, isPR bool) tea.Msg {
-	if config.OpenAIURL == "" {
-		msg := "OpenAI endpoint URL not configured. Set it via --openai-url or 'gitcat config'"
-		if isPR {
-			return prContentErrMsg(msg)
+// gitAddFiles stages the given files. Ignored files the user explicitly
+// chose are added with --force.
+func gitAddFiles(files []changedFile) error {
+	var paths, ignored []string
+	for _, f := range files {
+		if f.Status == "!!" {
+			ignored = append(ignored, f.Path)
+		} else {
+			paths = append(paths, f.Path)
 		}
-		return commitMsgErrMsg(msg)
 	}
 
-	apiKey := config.OpenAIAPIKey
-	if apiKey == "" {
-		apiKey = os.Getenv("OPENAI_API_KEY")
+	if len(paths) > 0 {
+		cmd := exec.Command("git", append([]string{"add", "--"}, paths...)...)
+		if output, err := runCommand(cmd); err != nil {
+			return fmt.Errorf("git add failed: %w\n%s", err, string(output))
+		}
 	}
-	if apiKey == "" {
-		msg := "OpenAI API key not set. Use --openai-api-key, config, or OPENAI_API_KEY env var"
-		if isPR {
-			return prContentErrMsg(msg)
+	if len(ignored) > 0 {
+		cmd := exec.Command("git", append([]string{"add", "--force", "--"}, ignored...)...)
+		if output, err := runCommand(cmd); err != nil {
+			return fmt.Errorf("git add --force failed: %w\n%s", err, string(output))
 		}
-		return commitMsgErrMsg(msg)
 	}
+	return nil
+}
 
-	reqBody := OpenAIRequest{
-		Model:     config.Model,
-		MaxTokens: maxTokens,
-		Messages: []OpenAIMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+// getCommitTemplate returns the contents of git's commit.template with
+// comment lines removed, or "" if none is configured. Relative paths are
+// resolved from the repository root, as git commit does.
+func getCommitTemplate() string {
+	output, err := runCommand(exec.Command("git", "config", "--path", "commit.template"))
+	templatePath := strings.TrimSpace(string(output))
+	if err != nil || templatePath == "" {
+		return ""
 	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error marshaling request: %v", err))
+	if !filepath.IsAbs(templatePath) {
+		if root, err := getRepoRoot(); err == nil {
+			templatePath = filepath.Join(root, templatePath)
 		}
-		return commitMsgErrMsg(fmt.Sprintf("Error marshaling request: %v", err))
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	endpoint := strings.TrimRight(config.OpenAIURL, "/") + "/v1/chat/completions"
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	data, err := os.ReadFile(templatePath)
 	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error creating request: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error creating request: %v", err))
+		debugf("failed to read commit template %s: %v", templatePath, err)
+		return ""
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error making request to %s: %v", endpoint, err))
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
 		}
-		return commitMsgErrMsg(fmt.Sprintf("Error making request to %s: %v", endpoint, err))
 	}
-	defer resp.Body.Close()
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error reading response: %v", err))
-		}
-		return commitMsgErrMsg(fmt.Sprintf("Error reading response: %v", err))
+// mergeCommitTemplate appends the template's lines (sections, required
+// trailers) below the message, skipping lines the message already has and
+// trailers whose key it already sets
+func mergeCommitTemplate(message, template string) string {
+	if template == "" {
+		return message
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("API error (%d): %s", resp.StatusCode, string(body)))
+	present := make(map[string]bool)
+	for _, line := range strings.Split(message, "\n") {
+		present[strings.TrimSpace(line)] = true
+		if key, _, ok := strings.Cut(line, ": "); ok && !strings.Contains(key, " ") {
+			present[key+":"] = true
 		}
-		return commitMsgErrMsg(fmt.Sprintf("API error (%d): %s", resp.StatusCode, string(body)))
 	}
 
-	var apiResp OpenAIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		if isPR {
-			return prContentErrMsg(fmt.Sprintf("Error parsing response: %v", err))
+	var extra []string
+	for _, line := range strings.Split(template, "\n") {
+		trimmed := strings.TrimSpace(line)
+		key, _, isTrailer := strings.Cut(trimmed, ":")
+		isTrailer = isTrailer && key != "" && !strings.Contains(key, " ")
+		if trimmed != "" && (present[trimmed] || (isTrailer && present[key+":"])) {
+			continue
 		}
-		return commitMsgErrMsg(fmt.Sprintf("Error parsing response: %v", err))
-	}
-
-	if len(apiResp.Choices) == 0 {
-		if isPR {
-			return prContentErrMsg("No choices in API response")
+		if trimmed == "" && (len(extra) == 0 || extra[len(extra)-1] == "") {
+			continue // Collapse blank lines left by skipped ones
 		}
-		return commitMsgErrMsg("No choices in API response")
+		extra = append(extra, line)
 	}
-
-	result := strings.TrimSpace(apiResp.Choices[0].Message.Content)
-	if isPR {
-		return prContentMsg(result)
+	if block := strings.Trim(strings.Join(extra, "\n"), "\n"); block != "" {
+		return strings.TrimRight(message, "\n") + "\n\n" + block
 	}
-	return commitMsgMsg(result)
+	return message
 }
 
-func getGitDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--staged")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("git diff failed: %w", err)
+// commitOptions are extra git commit switches
+type commitOptions = gitops.CommitOptions
+
+// worktree runs the git commands of the gitops package in the current
+// directory, logged like gitcat's others
+var worktree = gitops.Repo{Run: runCommand}
+
+// currentCommitOptions resolves commit switches from flags, the gitcat
+// config, and the repository's git config (gitcat.* keys)
+func currentCommitOptions() commitOptions {
+	return commitOptions{
+		Signoff:  *signoffFlag || getEffectiveConfig().Signoff || gitConfigBool("gitcat.signoff"),
+		NoVerify: *noVerifyFlag || getEffectiveConfig().NoVerify || gitConfigBool("gitcat.noVerify"),
 	}
-	return string(output), nil
 }
 
-func isDiffTooLarge(diff string) bool {
-	lines := strings.Split(diff, "\n")
-	return len(lines) > diffLineSizeLimit
+// gitConfigBool reads a boolean git config key, false if unset or invalid
+func gitConfigBool(key string) bool {
+	output, err := runCommand(exec.Command("git", "config", "--type=bool", "--get", key))
+	return err == nil && strings.TrimSpace(string(output)) == "true"
 }
 
-func getGitStatus() (bool, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	output, err := cmd.CombinedOutput()
+// getCommitterIdent returns "Name <email>" for the identity git will commit as
+func getCommitterIdent() string {
+	output, err := runCommand(exec.Command("git", "var", "GIT_COMMITTER_IDENT"))
 	if err != nil {
-		return false, fmt.Errorf("git status failed: %w", err)
+		return ""
 	}
-	return len(output) > 0, nil
+	ident := strings.TrimSpace(string(output))
+	if i := strings.LastIndex(ident, ">"); i >= 0 {
+		ident = ident[:i+1] // Drop the timestamp and timezone
+	}
+	return ident
 }
 
-func countStagedFiles() int {
-	cmd := exec.Command("git", "diff", "--staged", "--name-only")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return 0
-	}
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return 0
+// gitPush pushes branch to its upstream, or to the push remote chosen
+// with --push-remote or in config when that is set and the branch already
+// has an upstream. Without an upstream it fails as git push does, so the
+// user can be asked where to set one.
+func gitPush(branch string, noVerify bool) error {
+	remote := configuredPushRemote(branch)
+	if branchUpstream(branch) == "" {
+		remote = ""
 	}
-	return len(lines)
+	return worktree.Push(remote, branch, gitops.PushOptions{NoVerify: noVerify})
 }
 
-func gitAdd() error {
-	cmd := exec.Command("git", "add", ".")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git add failed: %w", err)
+// Stage scopes (Config.StageScope)
+const (
+	stageScopeRepo = "repo" // Offer changes anywhere in the repository (default)
+	stageScopeCwd  = "cwd"  // Offer only changes under the directory gitcat runs in
+)
+
+// stagingPathspec limits git status to the files that may be offered for
+// staging: everything, or with stage_scope "cwd" the start directory
+func stagingPathspec() []string {
+	if getEffectiveConfig().StageScope != stageScopeCwd || startDir == "" {
+		return nil
 	}
-	return nil
+	return []string{"--", startDir}
 }
 
-func gitCommit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	output, err := cmd.CombinedOutput()
+// enterRepoRoot moves to the top of the working tree, so the root-relative
+// paths git reports can be passed back to it. A relative GIT_DIR or
+// GIT_WORK_TREE is made absolute first, since it would otherwise be
+// resolved from the new directory. It returns the directory gitcat was
+// started in, relative to the root.
+func enterRepoRoot() (string, error) {
+	output, err := runCommand(exec.Command("git", "rev-parse", "--show-prefix"))
 	if err != nil {
-		return fmt.Errorf("git commit failed: %w\n%s", err, string(output))
+		return "", fmt.Errorf("not in a git repository")
 	}
-	return nil
+	prefix := strings.TrimSpace(string(output))
+	root, err := getRepoRoot()
+	if err != nil {
+		return "", fmt.Errorf("not in a git working tree (set GIT_WORK_TREE to use a bare or separate git directory)")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		output, err := runCommand(exec.Command("git", "rev-parse", "--absolute-git-dir"))
+		if err != nil {
+			return "", fmt.Errorf("git rev-parse --absolute-git-dir failed: %w", err)
+		}
+		os.Setenv("GIT_DIR", strings.TrimSpace(string(output)))
+	}
+	if os.Getenv("GIT_WORK_TREE") != "" {
+		os.Setenv("GIT_WORK_TREE", root)
+	}
+	if err := os.Chdir(root); err != nil {
+		return "", err
+	}
+	return prefix, nil
 }
 
-func gitPush() error {
-	cmd := exec.Command("git", "push")
-	output, err := cmd.CombinedOutput()
+// getRepoRoot returns the top-level directory of the current repository
+func getRepoRoot() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	output, err := runCommand(cmd)
 	if err != nil {
-		return fmt.Errorf("git push failed: %w\n%s", err, string(output))
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
 	}
-	return nil
+	return strings.TrimSpace(string(output)), nil
 }
 
 func getCurrentBranch() (string, error) {
 	cmd := exec.Command("git", "branch", "--show-current")
-	output, err := cmd.CombinedOutput()
+	output, err := runCommand(cmd)
 	if err != nil {
 		return "", fmt.Errorf("git branch failed: %w", err)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-func gitPushSetUpstream(branch string) error {
-	cmd := exec.Command("git", "push", "--set-upstream", "origin", branch)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("git push --set-upstream failed: %w\n%s", err, string(output))
-	}
-	return nil
-}
-
-func validateBranchName(name string) error {
-	if name == "" {
-		return fmt.Errorf("branch name cannot be empty")
-	}
-	if strings.HasPrefix(name, "-") {
-		return fmt.Errorf("branch name cannot start with a hyphen")
-	}
-	// Check for invalid characters (git doesn't allow certain chars)
-	invalidChars := []string{"..", "~", "^", ":", "?", "*", "[", "\\", " "}
-	for _, invalid := range invalidChars {
-		if strings.Contains(name, invalid) {
-			return fmt.Errorf("branch name contains invalid character: %s", invalid)
-		}
-	}
-	return nil
-}
-
-func generateDefaultBranchName() string {
-	// Get current timestamp for uniqueness
-	now := time.Now()
-	dateStr := now.Format("2006-01-02")
-
-	// Try to get git username
-	cmd := exec.Command("git", "config", "user.name")
-	output, err := cmd.CombinedOutput()
-	userName := "dev"
-	if err == nil && len(output) > 0 {
-		userName = strings.ToLower(strings.TrimSpace(string(output)))
-		userName = strings.ReplaceAll(userName, " ", "-")
-	}
-
-	return fmt.Sprintf("%s/feature-%s", userName, dateStr)
+// enterBranchConflictPhase explains why the entered branch name can't be
+// created and offers the alternative, if there is one
+func (m model) enterBranchConflictPhase(problem, alternative string) model {
+	m.phase = "branch_conflict"
+	m.branchProblem = problem
+	m.branchAlternative = alternative
+	m.cursor = 0
+	m.choices = []string{tr("Enter a different name")}
+	if alternative != "" {
+		m.choices = append([]string{tr("Use '%s' instead", alternative)}, m.choices...)
+	}
+	return m
 }
 
 func createAndCheckoutBranch(branchName string) tea.Cmd {
 	return func() tea.Msg {
 		// Create and checkout the branch
 		cmd := exec.Command("git", "checkout", "-b", branchName)
-		output, err := cmd.CombinedOutput()
+		output, err := runCommand(cmd)
 		if err != nil {
 			return errMsg(fmt.Sprintf("Failed to create branch: %v\n%s", err, string(output)))
 		}
@@ -1433,42 +3043,96 @@ func createAndCheckoutBranch(branchName string) tea.Cmd {
 	}
 }
 
-func isGitHubOrigin() error {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	output, err := cmd.CombinedOutput()
+// checkPRRemote checks that remote is hosted where gitcat can open PRs: on
+// GitHub, with gh or a token for its API, or on a known Gitea or Forgejo
+// instance with a token
+func checkPRRemote(remote string) error {
+	url, err := remoteURL(remote)
 	if err != nil {
-		return fmt.Errorf("failed to get origin URL: %w", err)
+		return err
 	}
-
-	originURL := strings.TrimSpace(string(output))
-	if !strings.Contains(originURL, "github.com") {
-		return fmt.Errorf("origin is not GitHub (found: %s). Only GitHub repositories are supported for PR creation", originURL)
+	if host, _ := giteaRepo(url); host != nil {
+		if host.token() == "" {
+			return fmt.Errorf("creating PRs on %s needs a token in GITEA_TOKEN or FORGEJO_TOKEN, or a tea login", host.name)
+		}
+		return nil
+	}
+	if githubRepo(url) == "" {
+		return fmt.Errorf("%s is not GitHub or a known Gitea/Forgejo host (found: %s). Add Gitea hosts with gitea_hosts in the config", remote, url)
+	}
+	if useGitHubAPI() && githubToken() == "" {
+		return fmt.Errorf("creating PRs needs the GitHub CLI (gh) or a GitHub token in GH_TOKEN or GITHUB_TOKEN")
 	}
-
 	return nil
 }
 
-func hasExistingPR(branch string) bool {
-	cmd := exec.Command("gh", "pr", "list", "--head", branch, "--json", "number")
-	output, err := cmd.CombinedOutput()
+// existingPR is an open PR found for the branch, which gitcat can update
+// instead of creating another
+type existingPR struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Owner  struct {
+		Login string `json:"login"`
+	} `json:"headRepositoryOwner"`
+}
+
+// findExistingPR returns the open PR from branch, or nil when there is none
+func findExistingPR(branch string, target prTarget) *existingPR {
+	if target.gitea != nil {
+		return giteaFindExistingPR(target, branch)
+	}
+	if useGitHubAPI() {
+		if target.repo == "" {
+			return nil
+		}
+		head := target.head
+		if target.forkOwner() == "" {
+			owner, _, _ := strings.Cut(target.repo, "/")
+			head = owner + ":" + branch
+		}
+		return apiFindExistingPR(target.repo, head)
+	}
+	args := []string{"pr", "list", "--head", branch, "--json", "number,url,headRepositoryOwner"}
+	if target.repo != "" {
+		args = append(args, "--repo", target.repo)
+	}
+	output, err := runCommand(exec.Command("gh", args...))
 	if err != nil {
-		return false
+		return nil
 	}
-
-	// If output is "[]" there are no PRs, otherwise there's at least one
-	result := strings.TrimSpace(string(output))
-	return result != "[]" && result != ""
+	var prs []existingPR
+	if err := json.Unmarshal(output, &prs); err != nil {
+		debugf("gh pr list: %v", err)
+		return nil
+	}
+	owner := target.forkOwner()
+	for _, pr := range prs {
+		// Other forks may have PRs from a branch of the same name
+		if owner == "" || strings.EqualFold(pr.Owner.Login, owner) {
+			return &pr
+		}
+	}
+	return nil
 }
 
-func getGitLog(branch string) (string, error) {
-	// Get the default branch (usually main or master)
-	cmd := exec.Command("git", "remote", "show", "origin")
-	output, err := cmd.CombinedOutput()
+// getDefaultBranch returns the base remote's default branch (usually main
+// or master); see baseRemote
+func getDefaultBranch() (string, error) {
+	if useGitHubAPI() {
+		if repo := baseGitHubRepo(); repo != "" {
+			r, err := apiGetRepo(repo)
+			if err == nil {
+				return r.DefaultBranch, nil
+			}
+			debugf("default branch from the GitHub API: %v", err)
+		}
+	}
+	cmd := exec.Command("git", "remote", "show", baseRemote())
+	output, err := runCommand(cmd)
 	if err != nil {
 		return "", fmt.Errorf("failed to get remote info: %w", err)
 	}
 
-	// Parse the default branch
 	defaultBranch := "main"
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
@@ -1480,14 +3144,32 @@ func getGitLog(branch string) (string, error) {
 			break
 		}
 	}
+	return defaultBranch, nil
+}
+
+// defaultBaseRef returns the ref branches are compared against: the base
+// remote's default branch, or the local branch of that name when the remote
+// ref or the remote itself is missing
+func defaultBaseRef() string {
+	defaultBranch, err := getDefaultBranch()
+	if err != nil {
+		defaultBranch = "main"
+	}
+	remote := baseRemote()
+	if _, err := runCommand(exec.Command("git", "rev-parse", "--verify", "--quiet", remote+"/"+defaultBranch)); err == nil {
+		return remote + "/" + defaultBranch
+	}
+	return defaultBranch
+}
 
-	// Get commits that are on current branch but not on default branch
-	cmd = exec.Command("git", "log", fmt.Sprintf("origin/%s..%s", defaultBranch, branch), "--pretty=format:%s%n%b%n---")
-	output, err = cmd.CombinedOutput()
+// getGitLog returns the messages of the commits on branch since base
+func getGitLog(base, branch string) (string, error) {
+	cmd := exec.Command("git", "log", base+".."+branch, "--pretty=format:%s%n%b%n---")
+	output, err := runCommand(cmd)
 	if err != nil {
 		// If the branch comparison fails, just get recent commits
 		cmd = exec.Command("git", "log", "-10", "--pretty=format:%s%n%b%n---")
-		output, err = cmd.CombinedOutput()
+		output, err = runCommand(cmd)
 		if err != nil {
 			return "", fmt.Errorf("failed to get git log: %w", err)
 		}
@@ -1496,58 +3178,139 @@ func getGitLog(branch string) (string, error) {
 	return string(output), nil
 }
 
-func generatePRContent(branch string) tea.Cmd {
+// startPRGeneration asks the PR model for the title and body, showing the
+// request's progress
+func (m model) startPRGeneration() (model, tea.Cmd) {
+	m.phase = "pr_generating"
+	m, ctx, tick := m.beginRequest(getEffectiveConfig().GetPRModel())
+	return m, tea.Batch(tick, m.generatePRContent(ctx))
+}
+
+// generatePRContent generates the title and body of the current branch's
+// PR from the log of its commits since prBaseRef, and from their diff when
+// pr_diff is set
+func (m model) generatePRContent(ctx context.Context) tea.Cmd {
+	branch, base := m.currentBranch, m.prBaseRef()
 	return func() tea.Msg {
 		config := getEffectiveConfig()
 		// Use the PR-specific model
 		config.Model = config.GetPRModel()
 
-		gitLog, err := getGitLog(branch)
+		gitLog, err := getGitLog(base, branch)
 		if err != nil {
 			return prContentErrMsg(fmt.Sprintf("Error getting git log: %v", err))
 		}
+		links := referencedIssues(branch, gitLog)
+		issues := fetchLinkedIssues(links)
+
+		source, changes := "git log", "Git log:\n"+gitLog
+		var diff string
+		if depth := prDiffDepth(); depth != "" {
+			budget := diffTokenBudget(config, config.GetPRParams().MaxTokens) - estimateTokens(gitLog)
+			diff, err = m.prPromptDiff(base, branch, depth, budget, config)
+			// The PR is written from the log alone when the diff has
+			// secrets, unless secret_scan blocks it
+			var secrets diffSecretsError
+			if errors.As(err, &secrets) && config.SecretScan == secretScanBlock {
+				return prContentErrMsg(fmt.Sprintf("Not generating the PR: %v", err))
+			}
+			if err != nil {
+				debugf("PR diff: %v", err)
+			}
+			if diff != "" {
+				source = "git log and diff"
+				changes += fmt.Sprintf("\nDiff against %s:\n%s", base, diff)
+			}
+		}
 
-		prompt := fmt.Sprintf(`You are a pull request generator. Based on the following git log from a branch, generate a clear and concise pull request title and body.
-
-IMPORTANT: Only describe changes that are explicitly mentioned in the git log. Do NOT infer, assume, or fabricate details that are not directly present in the commits. If the log is vague, keep the description general rather than guessing specifics.
+		data := prPromptData{
+			Log:               gitLog,
+			Diff:              diff,
+			Base:              base,
+			Context:           issuePromptContext(issues) + generate.GlossaryPrompt(config.Glossary),
+			TitleInstructions: prTitleInstructions(conventionalPRTitles()),
+			BodyInstructions:  prBodyInstructions(loadPRTemplate()),
+		}
+		data.Default = fmt.Sprintf(`You are a pull request generator. Based on the following %s from a branch, generate a clear and concise pull request title and body.
 
-Git log:
 %s
 
+%s
+%s
 Generate:
-1. A clear, concise PR title (max 72 characters) that summarizes the changes
-2. A PR body that:
-   - Summarizes the changes in bullet points based strictly on the commit messages
-   - Notes any breaking changes if explicitly mentioned
-   - Do NOT add implementation details, test descriptions, or context that is not in the log
+%s
+%s
 
 Format your response as:
 [PR Title]
 ---BODY---
 [PR Body]
 
-Respond with ONLY the title and body in this format, no explanations or markdown code blocks.`, gitLog)
+Respond with ONLY the title and body in this format, no explanations or markdown code blocks.`, source, groundingRule(source), changes, data.Context, data.TitleInstructions, data.BodyInstructions)
+		prompt, err := renderPromptTemplate("pr", data, data.Default)
+		if err != nil {
+			return prContentErrMsg(err.Error())
+		}
 
-		switch config.Provider {
-		case "ollama":
-			return generateWithOllama(config, prompt, 2048, true)
-		case "openai":
-			return generateWithOpenAI(config, prompt, 2048, true)
-		default:
-			return generateWithAnthropic(config, prompt, 2048, true)
+		msg := generateMsg(ctx, config, prompt, config.GetPRParams(), true)
+		if content, ok := msg.(prContentMsg); ok && strings.Contains(string(content), "\n---BODY---\n") {
+			if closes := closingKeywords(string(content), gitLog, links, issues); closes != "" {
+				msg = prContentMsg(strings.TrimRight(string(content), "\n") + "\n\n" + closes)
+			}
 		}
+		return msg
 	}
 }
 
-func createPR(title, body string) error {
-	cmd := exec.Command("gh", "pr", "create", "--title", title, "--body", body)
-	output, err := cmd.CombinedOutput()
+// updatePR replaces the title and body of an existing PR, and adds the
+// reviewers, assignees, and labels in meta to those it already has
+func updatePR(pr *existingPR, title, body string, meta *prMeta) error {
+	if host, repo, number, ok := parseGiteaPRURL(pr.URL); ok {
+		return giteaUpdatePR(host, repo, number, title, body, meta)
+	}
+	if useGitHubAPI() {
+		return apiUpdatePR(pr, title, body, meta)
+	}
+	args := append([]string{"pr", "edit", pr.URL, "--title", title, "--body", body}, meta.ghEditArgs()...)
+	output, err := runCommand(exec.Command("gh", args...))
 	if err != nil {
-		return fmt.Errorf("gh pr create failed: %w\n%s", err, string(output))
+		return fmt.Errorf("gh pr edit failed: %w\n%s", err, string(output))
 	}
 	return nil
 }
 
+// createPR opens the PR with gh (or the GitHub API without it), or on a
+// Gitea instance through its API, against the upstream repository when the
+// branch is in a fork, and returns its URL
+func createPR(title, body string, meta *prMeta, target *prTarget) (string, error) {
+	body = normalizeNewlines(body)
+	if target != nil && target.gitea != nil {
+		return giteaCreatePR(*target, title, body, meta)
+	}
+	if useGitHubAPI() {
+		if target == nil || target.repo == "" {
+			return "", fmt.Errorf("could not determine the GitHub repository for the PR")
+		}
+		return apiCreatePR(*target, title, body, meta)
+	}
+	args := append([]string{"pr", "create", "--title", title, "--body", body}, meta.ghArgs()...)
+	if target != nil {
+		args = append(args, target.ghArgs()...)
+	}
+	cmd := exec.Command("gh", args...)
+	output, err := runCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("gh pr create failed: %w\n%s", err, string(output))
+	}
+	url := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "https://") {
+			url = line
+		}
+	}
+	return url, nil
+}
+
 // Config TUI model for endpoint configuration
 type configModel struct {
 	phase        string // "provider", "commit_model", "pr_model", "ollama_url", "openai_url", "openai_api_key", "confirm", "saved", "error"
@@ -1560,6 +3323,7 @@ type configModel struct {
 	input        string // Current input value
 	errorMsg     string
 	configPath   string
+	base         Config // Loaded config, so fields without a TUI screen survive saving
 }
 
 const (
@@ -1592,9 +3356,24 @@ func initialConfigModel(config *Config, configPath string) configModel {
 		openaiURL:    config.OpenAIURL,
 		openaiAPIKey: config.OpenAIAPIKey,
 		configPath:   configPath,
+		base:         *config,
 	}
 }
 
+// buildConfig returns the loaded config with the TUI's edits applied
+func (m configModel) buildConfig() *Config {
+	config := m.base
+	config.Provider = m.provider
+	config.CommitModel = m.commitModel
+	config.PRModel = m.prModel
+	config.OllamaURL = m.ollamaURL
+	config.OpenAIURL = m.openaiURL
+	config.OpenAIAPIKey = m.openaiAPIKey
+	// Set Model as fallback for backward compatibility
+	config.Model = m.commitModel
+	return &config
+}
+
 func (m configModel) Init() tea.Cmd {
 	return nil
 }
@@ -1649,17 +3428,7 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.phase = phaseConfirm
 			case phaseConfirm:
 				// Save the config
-				newConfig := &Config{
-					Provider:     m.provider,
-					CommitModel:  m.commitModel,
-					PRModel:      m.prModel,
-					OllamaURL:    m.ollamaURL,
-					OpenAIURL:    m.openaiURL,
-					OpenAIAPIKey: m.openaiAPIKey,
-				}
-				// Set Model as fallback for backward compatibility
-				newConfig.Model = m.commitModel
-				if err := saveConfig(newConfig); err != nil {
+				if err := saveConfig(m.buildConfig()); err != nil {
 					m.errorMsg = err.Error()
 					m.phase = phaseError
 				} else {
@@ -1673,13 +3442,11 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "backspace":
-			if len(m.input) > 0 {
-				m.input = m.input[:len(m.input)-1]
-			}
+			m.input = trimLastRune(m.input)
 
 		default:
-			key := msg.String()
-			if len(key) != 1 {
+			key, ok := keyInput(msg, false)
+			if !ok {
 				break
 			}
 			switch m.phase {
@@ -1694,17 +3461,7 @@ func (m configModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case phaseConfirm:
 				if key == "y" {
 					// Save the config
-					newConfig := &Config{
-						Provider:     m.provider,
-						CommitModel:  m.commitModel,
-						PRModel:      m.prModel,
-						OllamaURL:    m.ollamaURL,
-						OpenAIURL:    m.openaiURL,
-						OpenAIAPIKey: m.openaiAPIKey,
-					}
-					// Set Model as fallback for backward compatibility
-					newConfig.Model = m.commitModel
-					if err := saveConfig(newConfig); err != nil {
+					if err := saveConfig(m.buildConfig()); err != nil {
 						m.errorMsg = err.Error()
 						m.phase = phaseError
 					} else {
@@ -1733,34 +3490,34 @@ func (m configModel) View() string {
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
 
 	if m.phase == phaseError {
-		s := titleStyle.Render("Error saving configuration") + "\n\n"
+		s := titleStyle.Render(tr("Error saving configuration")) + "\n\n"
 		s += errorStyle.Render(m.errorMsg) + "\n\n"
-		s += "Press enter to exit or esc to quit\n"
+		s += tr("Press enter to exit or esc to quit") + "\n"
 		return s
 	}
 
 	if m.phase == phaseSaved {
 		successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-		s := successStyle.Render("✓ Configuration saved successfully!") + "\n\n"
-		s += labelStyle.Render("Provider:") + " " + m.provider + "\n"
-		s += labelStyle.Render("Commit model:") + " " + m.commitModel + "\n"
-		s += labelStyle.Render("PR model:") + " " + m.prModel + "\n"
+		s := successStyle.Render(tr("✓ Configuration saved successfully!")) + "\n\n"
+		s += labelStyle.Render(tr("Provider:")) + " " + m.provider + "\n"
+		s += labelStyle.Render(tr("Commit model:")) + " " + m.commitModel + "\n"
+		s += labelStyle.Render(tr("PR model:")) + " " + m.prModel + "\n"
 		if m.provider == "ollama" {
-			s += labelStyle.Render("Ollama URL:") + " " + m.ollamaURL + "\n"
+			s += labelStyle.Render(tr("Ollama URL:")) + " " + m.ollamaURL + "\n"
 		}
 		if m.provider == "openai" {
-			s += labelStyle.Render("OpenAI URL:") + " " + m.openaiURL + "\n"
+			s += labelStyle.Render(tr("OpenAI URL:")) + " " + m.openaiURL + "\n"
 			if m.openaiAPIKey != "" {
-				s += labelStyle.Render("OpenAI API Key:") + " configured\n"
+				s += labelStyle.Render(tr("OpenAI API Key:")) + " " + tr("configured") + "\n"
 			}
 		}
-		s += "\n" + labelStyle.Render("Config file:") + " " + m.configPath + "\n\n"
-		s += "Press enter to exit\n"
+		s += "\n" + labelStyle.Render(tr("Config file:")) + " " + m.configPath + "\n\n"
+		s += tr("Press enter to exit") + "\n"
 		return s
 	}
 
 	if m.phase == phaseProvider {
-		s := titleStyle.Render("Select LLM Provider") + "\n\n"
+		s := titleStyle.Render(tr("Select LLM Provider")) + "\n\n"
 		providers := []string{"anthropic", "ollama", "openai"}
 		for _, p := range providers {
 			prefix := " "
@@ -1770,101 +3527,89 @@ func (m configModel) View() string {
 			}
 			s += fmt.Sprintf("%s %s\n", prefix, p)
 		}
-		s += "\n(press 1 for anthropic, 2 for ollama, 3 for openai, enter to continue)\n"
+		s += "\n" + tr("(press 1 for anthropic, 2 for ollama, 3 for openai, enter to continue)") + "\n"
 		return s
 	}
 
 	if m.phase == phaseCommitModel {
-		defaultModel := defaultAnthropicModel
-		switch m.provider {
-		case "ollama":
-			defaultModel = defaultOllamaModel
-		case "openai":
-			defaultModel = defaultOpenAIModel
-		}
-		s := titleStyle.Render("Configure Commit Model") + "\n\n"
-		s += labelStyle.Render("Provider:") + " " + m.provider + "\n\n"
-		s += "Enter model for commit message generation (fast model recommended):\n"
+		defaultModel := config.DefaultModel(m.provider)
+		s := titleStyle.Render(tr("Configure Commit Model")) + "\n\n"
+		s += labelStyle.Render(tr("Provider:")) + " " + m.provider + "\n\n"
+		s += tr("Enter model for commit message generation (fast model recommended):") + "\n"
 		s += fmt.Sprintf("> %s_\n", m.input)
-		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("Default: "+defaultModel) + "\n"
-		s += "(press enter when done)\n"
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Default: %s", defaultModel)) + "\n"
+		s += tr("(press enter when done)") + "\n"
 		return s
 	}
 
 	if m.phase == phasePRModel {
-		defaultModel := defaultAnthropicModel
-		switch m.provider {
-		case "ollama":
-			defaultModel = defaultOllamaModel
-		case "openai":
-			defaultModel = defaultOpenAIModel
-		}
-		s := titleStyle.Render("Configure PR Model") + "\n\n"
-		s += labelStyle.Render("Provider:") + " " + m.provider + "\n"
-		s += labelStyle.Render("Commit model:") + " " + m.commitModel + "\n\n"
-		s += "Enter model for PR description generation (smarter model recommended):\n"
+		defaultModel := config.DefaultModel(m.provider)
+		s := titleStyle.Render(tr("Configure PR Model")) + "\n\n"
+		s += labelStyle.Render(tr("Provider:")) + " " + m.provider + "\n"
+		s += labelStyle.Render(tr("Commit model:")) + " " + m.commitModel + "\n\n"
+		s += tr("Enter model for PR description generation (smarter model recommended):") + "\n"
 		s += fmt.Sprintf("> %s_\n", m.input)
-		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("Default: "+defaultModel) + "\n"
-		s += "(press enter when done)\n"
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Default: %s", defaultModel)) + "\n"
+		s += tr("(press enter when done)") + "\n"
 		return s
 	}
 
 	if m.phase == phaseOllamaURL {
-		s := titleStyle.Render("Configure Ollama Server URL") + "\n\n"
-		s += labelStyle.Render("Provider:") + " ollama\n"
-		s += labelStyle.Render("Commit model:") + " " + m.commitModel + "\n"
-		s += labelStyle.Render("PR model:") + " " + m.prModel + "\n\n"
-		s += "Enter Ollama server URL:\n"
+		s := titleStyle.Render(tr("Configure Ollama Server URL")) + "\n\n"
+		s += labelStyle.Render(tr("Provider:")) + " ollama\n"
+		s += labelStyle.Render(tr("Commit model:")) + " " + m.commitModel + "\n"
+		s += labelStyle.Render(tr("PR model:")) + " " + m.prModel + "\n\n"
+		s += tr("Enter Ollama server URL:") + "\n"
 		s += fmt.Sprintf("> %s_\n", m.input)
-		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("Default: "+defaultOllamaURL) + "\n"
-		s += "(press enter when done)\n"
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Default: %s", config.DefaultOllamaURL)) + "\n"
+		s += tr("(press enter when done)") + "\n"
 		return s
 	}
 
 	if m.phase == phaseOpenAIURL {
-		s := titleStyle.Render("Configure OpenAI-compatible Endpoint URL") + "\n\n"
-		s += labelStyle.Render("Provider:") + " openai\n"
-		s += labelStyle.Render("Commit model:") + " " + m.commitModel + "\n"
-		s += labelStyle.Render("PR model:") + " " + m.prModel + "\n\n"
-		s += "Enter endpoint base URL (e.g. http://localhost:4000):\n"
+		s := titleStyle.Render(tr("Configure OpenAI-compatible Endpoint URL")) + "\n\n"
+		s += labelStyle.Render(tr("Provider:")) + " openai\n"
+		s += labelStyle.Render(tr("Commit model:")) + " " + m.commitModel + "\n"
+		s += labelStyle.Render(tr("PR model:")) + " " + m.prModel + "\n\n"
+		s += tr("Enter endpoint base URL (e.g. http://localhost:4000):") + "\n"
 		s += fmt.Sprintf("> %s_\n", m.input)
-		s += "\n(press enter when done)\n"
+		s += "\n" + tr("(press enter when done)") + "\n"
 		return s
 	}
 
 	if m.phase == phaseOpenAIAPIKey {
-		s := titleStyle.Render("Configure OpenAI-compatible API Key") + "\n\n"
-		s += labelStyle.Render("Provider:") + " openai\n"
-		s += labelStyle.Render("Commit model:") + " " + m.commitModel + "\n"
-		s += labelStyle.Render("PR model:") + " " + m.prModel + "\n"
-		s += labelStyle.Render("Endpoint URL:") + " " + m.openaiURL + "\n\n"
-		s += "Enter API key (or leave empty to use OPENAI_API_KEY env var):\n"
+		s := titleStyle.Render(tr("Configure OpenAI-compatible API Key")) + "\n\n"
+		s += labelStyle.Render(tr("Provider:")) + " openai\n"
+		s += labelStyle.Render(tr("Commit model:")) + " " + m.commitModel + "\n"
+		s += labelStyle.Render(tr("PR model:")) + " " + m.prModel + "\n"
+		s += labelStyle.Render(tr("Endpoint URL:")) + " " + m.openaiURL + "\n\n"
+		s += tr("Enter API key (or leave empty to use OPENAI_API_KEY env var):") + "\n"
 		s += fmt.Sprintf("> %s_\n", m.input)
-		s += "\n(press enter when done)\n"
+		s += "\n" + tr("(press enter when done)") + "\n"
 		return s
 	}
 
 	if m.phase == phaseConfirm {
-		s := titleStyle.Render("Confirm Configuration") + "\n\n"
-		s += labelStyle.Render("Provider:") + " " + m.provider + "\n"
-		s += labelStyle.Render("Commit model:") + " " + m.commitModel + "\n"
-		s += labelStyle.Render("PR model:") + " " + m.prModel + "\n"
+		s := titleStyle.Render(tr("Confirm Configuration")) + "\n\n"
+		s += labelStyle.Render(tr("Provider:")) + " " + m.provider + "\n"
+		s += labelStyle.Render(tr("Commit model:")) + " " + m.commitModel + "\n"
+		s += labelStyle.Render(tr("PR model:")) + " " + m.prModel + "\n"
 		if m.provider == "ollama" {
-			s += labelStyle.Render("Ollama URL:") + " " + m.ollamaURL + "\n"
+			s += labelStyle.Render(tr("Ollama URL:")) + " " + m.ollamaURL + "\n"
 		}
 		if m.provider == "openai" {
-			s += labelStyle.Render("OpenAI URL:") + " " + m.openaiURL + "\n"
+			s += labelStyle.Render(tr("OpenAI URL:")) + " " + m.openaiURL + "\n"
 			if m.openaiAPIKey != "" {
-				s += labelStyle.Render("OpenAI API Key:") + " " + m.openaiAPIKey[:min(8, len(m.openaiAPIKey))] + "..." + "\n"
+				s += labelStyle.Render(tr("OpenAI API Key:")) + " " + m.openaiAPIKey[:min(8, len(m.openaiAPIKey))] + "..." + "\n"
 			} else {
-				s += labelStyle.Render("OpenAI API Key:") + " (from OPENAI_API_KEY env var)\n"
+				s += labelStyle.Render(tr("OpenAI API Key:")) + " " + tr("(from OPENAI_API_KEY env var)") + "\n"
 			}
 		}
-		s += "\n" + labelStyle.Render("Config file:") + " " + m.configPath + "\n\n"
-		s += titleStyle.Render("Save this configuration?") + "\n\n"
-		s += "  [y] Yes, save\n"
-		s += "  [n] No, cancel\n\n"
-		s += "(press y to save, n to cancel, esc to quit)\n"
+		s += "\n" + labelStyle.Render(tr("Config file:")) + " " + m.configPath + "\n\n"
+		s += titleStyle.Render(tr("Save this configuration?")) + "\n\n"
+		s += tr("  [y] Yes, save") + "\n"
+		s += tr("  [n] No, cancel") + "\n\n"
+		s += tr("(press y to save, n to cancel, esc to quit)") + "\n"
 		return s
 	}
 
@@ -1873,44 +3618,38 @@ func (m configModel) View() string {
 
 func runConfigUI() {
 	// Load current config
-	config, err := loadConfig()
+	current, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
+	setLanguage(resolveLanguage(current.Language))
 
-	configPath, err := getConfigPath()
+	configPath, err := config.Path()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting config path: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
-	p := tea.NewProgram(initialConfigModel(config, configPath))
+	p := tea.NewProgram(initialConfigModel(current, configPath))
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running config UI: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 }
 
 func printHelp() {
-	fmt.Println(`gitcat - AI-powered git commit message generator
+	fmt.Printf(`gitcat - AI-powered git commit message generator
 
 USAGE:
-    gitcat [OPTIONS]
-
-OPTIONS:
-    -m, --model <model>           Model to use for both commit and PR (overrides config)
-    --commit-model <model>        Model for commit message generation (overrides config and -m)
-    --pr-model <model>            Model for PR description generation (overrides config and -m)
-    -p, --provider <provider>     LLM provider: anthropic, ollama, or openai (overrides config)
-    --ollama-url <url>            Ollama server URL (overrides config)
-    --openai-url <url>            OpenAI-compatible endpoint URL (overrides config)
-    --openai-api-key <key>        OpenAI-compatible API key (overrides config)
-    --pr                          Generate a PR from existing commits (no commit required)
-
-SUBCOMMANDS:
-    config                        Open configuration TUI to set provider, models, and endpoints
-    help                          Show this help message
+    gitcat [OPTIONS]              Same as gitcat commit
+    gitcat <command> [OPTIONS] [<args>]
+
+COMMANDS:
+%s
+GLOBAL OPTIONS:
+%s
+Run 'gitcat help <command>' or 'gitcat <command> --help' for the options of a command.
 
 EXAMPLES:
     gitcat                        Generate a commit message with default config
@@ -1920,34 +3659,68 @@ EXAMPLES:
     gitcat -p ollama              Use Ollama provider
     gitcat -p openai --openai-url http://localhost:4000 --openai-api-key sk-test
                                   Use OpenAI-compatible provider (e.g. LiteLLM)
-    gitcat --pr                   Generate a PR from current branch commits
+    gitcat pr                     Generate a PR from current branch commits
     gitcat config                 Configure endpoints and settings
+    gitcat help commit            Show the options of the commit flow
+    gitcat reword                 Regenerate the last commit's message and amend it
+    gitcat reword main..          Regenerate the message of each commit since main
+    gitcat squash                 Squash the branch's commits into one with a generated message
+    gitcat pr --squash            Squash the branch, force-push it, and create a PR
+    gitcat revert a1b2c3d         Revert a commit with a message explaining why
+    gitcat release                Write release notes since the last tag, then tag and publish them
+    gitcat release v2.0.0         Release under a version of your choosing
+    gitcat explain a1b2c3d        Explain in plain words what a commit changed and why it matters
+    gitcat explain main..feature  Explain everything a branch changes
+    gitcat review                 Review the staged changes for bugs, missing tests, and style, then commit
+    gitcat standup                Summarize your commits since yesterday, across branches, for a standup update
+    gitcat standup --since monday --author alice
+                                  Summarize someone else's week so far
+    gitcat lint                   Check the messages of the branch's commits, suggesting rewrites
+    gitcat lint --offline HEAD~20 Check the last 20 messages without suggestions (e.g. in CI)
 
 CONFIGURATION:
-    Config is stored in: ~/.config/gitcat/config.json
+    Config is stored in: ~/.config/gitcat/config.json (%%AppData%%\gitcat\config.json on Windows)
     Separate models can be configured for commit messages and PR descriptions.
     Use 'gitcat config' to set them interactively.
 
+    Debug logging can also be enabled with GITCAT_DEBUG=1. API keys are never logged.
+    The interactive screens follow GITCAT_LANG or LANG; Spanish (es) is built in.
+
     Available providers:
       - anthropic: Requires ANTHROPIC_API_KEY environment variable
       - ollama: Local Ollama instance for running open-source models
-      - openai: OpenAI-compatible API (e.g. LiteLLM proxy), requires endpoint URL and API key`)
+      - openai: OpenAI-compatible API (e.g. LiteLLM proxy), requires endpoint URL and API key
+
+EXIT CODES:
+    0    Completed normally
+    1    Usage or unexpected error (invalid flag or branch name, config, terminal UI)
+    2    Nothing to commit
+    3    Aborted by the user
+    4    LLM provider request failed
+    5    A git command failed
+    6    PR creation or GitHub checks failed
+    7    The verify command failed
+    8    gitcat lint found messages that break the rules
+`, commandList(), flagHelp(globalFlags))
 }
 
 func main() {
+	flag.Usage = printHelp
 	flag.Parse()
+	cmd, args, bare := parseCommand()
+	checkCommandFlags(cmd, bare)
 
-	// Handle subcommands
-	if len(flag.Args()) > 0 {
-		switch flag.Arg(0) {
-		case "config":
-			// Run the configuration TUI and exit
-			runConfigUI()
-			return
-		case "help", "-h", "--help":
-			printHelp()
-			return
-		}
+	if err := initDebugLog(); err != nil {
+		// Non-fatal: continue without debug logging
+		fmt.Fprintf(os.Stderr, "Warning: could not enable debug logging: %v\n", err)
+	}
+	enableTerminalColors()
+
+	// Help and the config TUI need neither a repository nor the config
+	// loaded up front
+	if cmd.name == "help" || cmd.name == "config" {
+		cmd.run(args)
+		return
 	}
 
 	// Load configuration
@@ -1955,60 +3728,156 @@ func main() {
 	appConfig, err = loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
+	setLanguage(resolveLanguage(appConfig.Language))
 
-	// Save config if it doesn't exist (creates default config file)
-	configPath, _ := getConfigPath()
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		if err := saveConfig(appConfig); err != nil {
-			// Non-fatal: just warn if we can't save the default config
-			fmt.Fprintf(os.Stderr, "Warning: could not save default config: %v\n", err)
-		}
+	// Walk through setting up a provider on first launch. Runs without a
+	// terminal use the built-in defaults and leave the setup for later.
+	if configPath, err := config.Path(); err == nil && shouldOnboard(configPath) {
+		appConfig = runOnboarding(appConfig, configPath)
 	}
 
-	// Handle --pr flag: skip commit flow and generate PR directly
-	if *prFlag {
-		currentBranch, err := getCurrentBranch()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
-			os.Exit(1)
+	for _, value := range *coAuthorFlags {
+		if _, err := resolveCoAuthor(value, appConfig.CoAuthors); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	if *typeFlag != "" {
+		var names []string
+		for _, t := range commitTypesFor(appConfig) {
+			names = append(names, t.Name)
+		}
+		if !slices.Contains(names, *typeFlag) {
+			fmt.Fprintf(os.Stderr, "Error: unknown --type %q (use %s)\n", *typeFlag, strings.Join(names, ", "))
+			os.Exit(exitError)
 		}
+	}
+	if *verbosityFlag != "" && !slices.Contains(generate.Verbosities, *verbosityFlag) {
+		fmt.Fprintf(os.Stderr, "Error: unknown --verbosity %q (use terse, standard, or detailed)\n", *verbosityFlag)
+		os.Exit(exitError)
+	}
+	if *mergeStrategyFlag != "" && !slices.Contains(mergeStrategies, *mergeStrategyFlag) {
+		fmt.Fprintf(os.Stderr, "Error: unknown --merge-strategy %q (use merge, squash, or rebase)\n", *mergeStrategyFlag)
+		os.Exit(exitError)
+	}
+	if *prDiffFlag != "" && *prDiffFlag != prDiffNone && !validDiffDepth(*prDiffFlag) {
+		fmt.Fprintf(os.Stderr, "Error: unknown --pr-diff %q (use full, hunks, stat, or none)\n", *prDiffFlag)
+		os.Exit(exitError)
+	}
 
-		if err := isGitHubOrigin(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+	startDir, err = enterRepoRoot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	for _, flagRemote := range []struct{ name, value string }{{"push-remote", *pushRemoteFlag}, {"pr-remote", *prRemoteFlag}} {
+		if flagRemote.value != "" && !slices.Contains(listRemotes(), flagRemote.value) {
+			fmt.Fprintf(os.Stderr, "Error: --%s %q is not a remote of this repository\n", flagRemote.name, flagRemote.value)
+			os.Exit(exitError)
 		}
+	}
+
+	if op := rebaseInProgress(); op != "" {
+		fmt.Fprint(os.Stderr, finishRebaseMessage(op))
+		os.Exit(exitError)
+	}
+	if mergeInProgress() && (cmd.name == "reword" || cmd.name == "squash" || cmd.name == "revert") {
+		fmt.Fprintln(os.Stderr, "A merge is in progress. Commit it (run gitcat) or cancel it (git merge --abort) first.")
+		os.Exit(exitError)
+	}
+
+	if bare && *prFlag {
+		cmd = findCommand("pr")
+	}
+	cmd.run(args)
+}
 
-		if hasExistingPR(currentBranch) {
-			fmt.Fprintf(os.Stderr, "A pull request already exists for branch '%s'.\n", currentBranch)
-			os.Exit(1)
+// runPR generates a PR from the commits already on the branch, without
+// committing
+func runPR(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat pr [OPTIONS]")
+		os.Exit(exitError)
+	}
+	currentBranch, err := getCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+
+	if review := loadGerritReview(currentBranch); review != nil {
+		fmt.Fprintf(os.Stderr, "Error: this is a Gerrit project; commits are pushed for review with git push %s HEAD:refs/for/%s instead of opened as PRs\n", review.remote, review.branch)
+		os.Exit(exitError)
+	}
+
+	if err := checkPRRemote(prRemote(currentBranch)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitGHFailure)
+	}
+
+	if *baseFlag != "" {
+		if _, err := runCommand(exec.Command("git", "rev-parse", "--verify", "--quiet", *baseFlag+"^{commit}")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --base %q is not a commit\n", *baseFlag)
+			os.Exit(exitError)
 		}
+	}
 
-		p := tea.NewProgram(initialModel("", false, currentBranch, false, true))
-		if _, err := p.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
-			os.Exit(1)
+	target := resolvePRTarget(currentBranch)
+	m := initialModel("", false, currentBranch, false, true)
+	m.prTarget = &target
+	m.prBase = *baseFlag
+	// An open PR gets its title and body regenerated instead
+	m.existingPR = findExistingPR(currentBranch, target)
+	if *squashFlag {
+		m = m.useSquashTarget(defaultBaseRef(), true)
+		if m.errorMsg != "" {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", m.errorMsg)
+			os.Exit(m.exitCode)
 		}
+	}
+	p := tea.NewProgram(m, programOptions()...)
+	finalModel, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(exitError)
+	}
+	exitWith(finalModel.(model))
+}
+
+// runCommit runs the commit flow: staging, the type and scope, generating
+// the message, committing, then pushing and creating a PR
+func runCommit(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat commit [OPTIONS]")
+		os.Exit(exitError)
+	}
+	if mergeInProgress() {
+		runMerge()
 		return
 	}
 
-	diff, err := getGitDiff()
+	diff, err := worktree.StagedDiff()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting git diff: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitGitFailure)
 	}
 
 	needsAdd := false
-	if diff == "" {
+	if diff == "" && !*allowEmptyFlag {
 		hasChanges, err := getGitStatus()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error checking git status: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitGitFailure)
+		}
+		if !hasChanges && stagingPathspec() != nil {
+			fmt.Printf("No changes to commit under %s.\n", startDir)
+			os.Exit(exitNothingToCommit)
 		}
 		if !hasChanges {
 			fmt.Println("No changes to commit.")
-			os.Exit(0)
+			os.Exit(exitNothingToCommit)
 		}
 		needsAdd = true
 	}
@@ -2016,14 +3885,49 @@ func main() {
 	currentBranch, err := getCurrentBranch()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitGitFailure)
 	}
 
 	isProtectedBranch := currentBranch == "main" || currentBranch == "master"
 
-	p := tea.NewProgram(initialModel(diff, needsAdd, currentBranch, isProtectedBranch, false))
-	if _, err := p.Run(); err != nil {
+	if drafts, _ := newDraftStore(); *reuseLastFlag && drafts.load(currentBranch) == nil {
+		fmt.Fprintf(os.Stderr, "No saved commit message to reuse for branch '%s'.\n", currentBranch)
+		os.Exit(exitError)
+	}
+
+	if *branchFlag != "" && *branchFlag != currentBranch {
+		if problem, _ := checkNewBranch(*branchFlag); problem != "" {
+			fmt.Fprintf(os.Stderr, "Error: --branch: %s\n", problem)
+			os.Exit(exitError)
+		}
+	}
+
+	m := initialModel(diff, needsAdd, currentBranch, isProtectedBranch, false)
+	p := tea.NewProgram(m, programOptions()...)
+	finalModel, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+	exitWith(finalModel.(model))
+}
+
+// exitWith undoes any split, squash, or revert left half done and brings
+// back stashed unstaged changes, then exits with the run's exit code
+func exitWith(m model) {
+	m.cancelRequests()
+	if err := m.restoreSplit(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not restage the uncommitted split groups: %v\n", err)
+	}
+	if err := m.restoreSquash(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not restore the branch after the unfinished squash: %v\n", err)
+	}
+	if err := m.restoreRevert(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not undo the uncommitted revert: %v\n", err)
+	}
+	if _, err := m.restoreUnstaged(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not restore your unstaged changes: %v\n", err)
 	}
+	m.writeCommitNotes()
+	os.Exit(m.exitCode)
 }
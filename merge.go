@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/gitcat/generate"
+)
+
+// mergeMaxCommits caps the merged-in commit subjects listed in the prompt
+const mergeMaxCommits = 50
+
+// mergeInfo describes a merge waiting to be committed
+type mergeInfo = generate.Merge
+
+// gitPathExists reports whether name exists in the git directory, e.g.
+// "MERGE_HEAD" (worktrees included)
+func gitPathExists(name string) bool {
+	output, err := runCommand(exec.Command("git", "rev-parse", "--git-path", name))
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(strings.TrimSpace(string(output)))
+	return err == nil
+}
+
+// rebaseInProgress returns "rebase" or "am" when one of them has stopped
+// partway, and "" otherwise
+func rebaseInProgress() string {
+	if gitPathExists("rebase-merge") {
+		return "rebase"
+	}
+	if gitPathExists("rebase-apply") {
+		if gitPathExists("rebase-apply/applying") {
+			return "am"
+		}
+		return "rebase"
+	}
+	return ""
+}
+
+// finishRebaseMessage explains how to get out of a rebase (or am) before
+// running gitcat
+func finishRebaseMessage(op string) string {
+	return fmt.Sprintf(`A git %[1]s is in progress, so gitcat won't commit on top of it.
+Finish the %[1]s first:
+    git %[1]s --continue    once conflicts are resolved and staged
+    git %[1]s --skip        to drop the commit being applied
+    git %[1]s --abort       to go back to where you started
+`, op)
+}
+
+// mergeInProgress reports whether a merge is waiting to be committed
+func mergeInProgress() bool {
+	return gitPathExists("MERGE_HEAD")
+}
+
+// unmergedFiles lists the files that still have unresolved conflicts
+func unmergedFiles() ([]string, error) {
+	output, err := runCommand(exec.Command("git", "diff", "--name-only", "--diff-filter=U"))
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// loadMergeInfo reads the merge's prepared message and the commits it brings
+// in
+func loadMergeInfo() (*mergeInfo, error) {
+	info := &mergeInfo{}
+	output, err := runCommand(exec.Command("git", "rev-parse", "--git-path", "MERGE_MSG"))
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	if data, err := os.ReadFile(strings.TrimSpace(string(output))); err == nil {
+		info.Subject, info.Conflicts = parseMergeMsg(string(data))
+	}
+	if info.Subject == "" {
+		output, err := runCommand(exec.Command("git", "rev-parse", "--short", "MERGE_HEAD"))
+		if err != nil {
+			return nil, fmt.Errorf("git rev-parse MERGE_HEAD failed: %w", err)
+		}
+		info.Subject = fmt.Sprintf("Merge commit '%s'", strings.TrimSpace(string(output)))
+	}
+
+	output, err = runCommand(exec.Command("git", "log", "--no-merges", "--format=%s", fmt.Sprintf("-%d", mergeMaxCommits), "HEAD..MERGE_HEAD"))
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			info.Commits = append(info.Commits, line)
+		}
+	}
+	return info, nil
+}
+
+// parseMergeMsg reads git's prepared MERGE_MSG: the subject is its first
+// uncommented line, and the conflicts are the tab-indented paths under
+// "Conflicts:", commented out or not
+func parseMergeMsg(data string) (subject string, conflicts []string) {
+	inConflicts := false
+	for _, line := range strings.Split(data, "\n") {
+		text := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		if text == "Conflicts:" {
+			inConflicts = true
+			continue
+		}
+		if inConflicts {
+			if text == "" || !strings.HasPrefix(strings.TrimPrefix(line, "#"), "\t") {
+				inConflicts = false
+			} else {
+				conflicts = append(conflicts, text)
+				continue
+			}
+		}
+		if subject == "" && text != "" && !strings.HasPrefix(line, "#") {
+			subject = text
+		}
+	}
+	return subject, conflicts
+}
+
+// mergeMessage keeps git's merge subject on a generated message
+func mergeMessage(message string, info *mergeInfo) string {
+	_, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return info.Subject
+	}
+	return info.Subject + "\n\n" + body
+}
+
+// runMerge generates the message that concludes a merge in progress. Files
+// with unresolved conflicts must be resolved and staged first.
+func runMerge() {
+	unmerged, err := unmergedFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking for conflicts: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	if len(unmerged) > 0 {
+		fmt.Fprintf(os.Stderr, "A merge is in progress with unresolved conflicts in:\n    %s\nResolve them and stage the files (git add), then run gitcat again to commit the merge, or run git merge --abort to cancel it.\n", strings.Join(unmerged, "\n    "))
+		os.Exit(exitGitFailure)
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	info, err := loadMergeInfo()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading the merge: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	diff, err := worktree.StagedDiff()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting git diff: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+
+	m := initialModel(diff, false, branch, false, false)
+	m.savedDraft = nil
+	m.merge = info
+	m.phase = "generating"
+
+	p := tea.NewProgram(m, programOptions()...)
+	finalModel, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(exitError)
+	}
+	exitWith(finalModel.(model))
+}
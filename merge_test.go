@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestParseMergeMsg(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantSubject   string
+		wantConflicts []string
+	}{
+		{"empty", "", "", nil},
+		{"subject only", "Merge branch 'topic'\n", "Merge branch 'topic'", nil},
+		{
+			"commented conflicts",
+			"Merge branch 'topic' into main\n\n# Conflicts:\n#\tapi/client.go\n#\tREADME.md\n#\n# It looks like you may be committing a merge.\n",
+			"Merge branch 'topic' into main",
+			[]string{"api/client.go", "README.md"},
+		},
+		{
+			"uncommented conflicts",
+			"Merge remote-tracking branch 'origin/main'\n\nConflicts:\n\tgo.mod\n\tgo.sum\n",
+			"Merge remote-tracking branch 'origin/main'",
+			[]string{"go.mod", "go.sum"},
+		},
+		{
+			"conflicts block ends at unindented line",
+			"# Conflicts:\n#\tmain.go\n# Please enter a commit message\nMerge commit 'abc1234'\n",
+			"Merge commit 'abc1234'",
+			[]string{"main.go"},
+		},
+		{"comments only", "# Please enter a commit message\n#\n", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, conflicts := parseMergeMsg(tt.data)
+			if subject != tt.wantSubject {
+				t.Errorf("parseMergeMsg() subject = %q, want %q", subject, tt.wantSubject)
+			}
+			if !slices.Equal(conflicts, tt.wantConflicts) {
+				t.Errorf("parseMergeMsg() conflicts = %q, want %q", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestMergeMessage(t *testing.T) {
+	info := &mergeInfo{Subject: "Merge branch 'topic'"}
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"body kept", "merge topic\n\nBrings in retries.\n", "Merge branch 'topic'\n\nBrings in retries."},
+		{"model's subject replaced", "feat: add retries\n\nBrings in retries.", "Merge branch 'topic'\n\nBrings in retries."},
+		{"empty body", "Merge branch 'topic'\n\n", "Merge branch 'topic'"},
+		{"subject only", "feat: add retries", "Merge branch 'topic'"},
+		{"empty message", "", "Merge branch 'topic'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeMessage(tt.message, info); got != tt.want {
+				t.Errorf("mergeMessage(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMergeInfo(t *testing.T) {
+	git := gitRepo(t)
+	writeFile := func(content string) {
+		if err := os.WriteFile("a.txt", []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("base\n")
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "topic")
+	writeFile("topic\n")
+	git("commit", "-q", "-am", "change a on topic")
+	git("checkout", "-q", "-")
+	writeFile("main\n")
+	git("commit", "-q", "-am", "change a on main")
+	// The merge stops on the conflict
+	if err := exec.Command("git", "merge", "topic").Run(); err == nil || !mergeInProgress() {
+		t.Fatalf("git merge = %v, want it stopped on a conflict", err)
+	}
+	writeFile("resolved\n")
+	git("add", "a.txt")
+
+	info, err := loadMergeInfo()
+	if err != nil {
+		t.Fatalf("loadMergeInfo() error = %v", err)
+	}
+	if info.Subject != "Merge branch 'topic'" {
+		t.Errorf("subject = %q, want git's merge subject", info.Subject)
+	}
+	if !slices.Equal(info.Conflicts, []string{"a.txt"}) {
+		t.Errorf("conflicts = %q, want [a.txt]", info.Conflicts)
+	}
+	if !slices.Equal(info.Commits, []string{"change a on topic"}) {
+		t.Errorf("commits = %q, want the commit merged in", info.Commits)
+	}
+}
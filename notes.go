@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// notesRef is the notes ref gitcat's commit explanations are stored under
+const notesRef = "refs/notes/gitcat"
+
+// notesEnabled reports whether new commits get an explanation as a git
+// note: --notes, notes in the config, or git config gitcat.notes. Offline
+// runs have no model to write one.
+func notesEnabled() bool {
+	if *offlineFlag {
+		return false
+	}
+	return *notesFlag || getEffectiveConfig().Notes || gitConfigBool("gitcat.notes")
+}
+
+// recordNoteCommit remembers the commit just made so it gets a note on exit
+func (m model) recordNoteCommit() model {
+	if !notesEnabled() {
+		return m
+	}
+	output, err := runCommand(exec.Command("git", "rev-parse", "HEAD"))
+	if err != nil {
+		debugf("recording commit for notes: %v", err)
+		return m
+	}
+	m.noteCommits = append(m.noteCommits, strings.TrimSpace(string(output)))
+	return m
+}
+
+// addCommitNote generates an explanation of sha with the explain prompt and
+// stores it as the commit's note, replacing any earlier one
+func addCommitNote(sha string) error {
+	base, head, err := explainRange(sha)
+	if err != nil {
+		return err
+	}
+	config := getEffectiveConfig()
+	config.Model = config.GetPRModel()
+	prompt, err := explainPrompt(base, head, config)
+	if err != nil {
+		return err
+	}
+
+	msg := generateMsg(context.Background(), config, prompt, config.GetPRParams(), true)
+	explanation, ok := msg.(prContentMsg)
+	if !ok {
+		return fmt.Errorf("%s", msg)
+	}
+
+	cmd := exec.Command("git", "notes", "--ref="+notesRef, "add", "--force", "--file=-", sha)
+	cmd.Stdin = strings.NewReader(strings.TrimSpace(string(explanation)) + "\n")
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git notes add failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// writeCommitNotes adds a note to each commit made in this run, and pushes
+// the notes along with the branch when the branch was pushed. Failures are
+// reported as warnings; the commits themselves are already made.
+func (m model) writeCommitNotes() {
+	if len(m.noteCommits) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Adding git notes to %d commit(s)...\n", len(m.noteCommits))
+	added := 0
+	for _, sha := range m.noteCommits {
+		if err := addCommitNote(sha); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not add a note to %.7s: %v\n", sha, err)
+			continue
+		}
+		added++
+	}
+	if added == 0 || !m.didPush || m.gerrit != nil {
+		return
+	}
+	args := []string{"push"}
+	if m.commitOpts.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	remote := pushRemote(m.currentBranch)
+	if output, err := runCommand(exec.Command("git", append(args, remote, notesRef)...)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not push the notes to %s: %s\n", remote, strings.TrimSpace(string(output)))
+	}
+}
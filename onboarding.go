@@ -0,0 +1,503 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+
+	"github.com/burritocatai/gitcat/config"
+	"github.com/burritocatai/gitcat/generate"
+	"github.com/burritocatai/gitcat/provider"
+)
+
+// onboardingSampleDiff is the change the setup wizard's test generation
+// writes a commit message for
+const onboardingSampleDiff = `diff --git a/greet.go b/greet.go
+--- a/greet.go
++++ b/greet.go
+@@ -3,5 +3,8 @@ package main
+ import "fmt"
+
+ func greet(name string) string {
++	if name == "" {
++		name = "world"
++	}
+ 	return fmt.Sprintf("Hello, %s!", name)
+ }
+`
+
+// onboardingModelsShown is how many models the model pickers list at once
+const onboardingModelsShown = 10
+
+type onboardingModelsMsg []string // Models the provider offers
+type onboardingSampleMsg string   // Message written by the test generation
+type onboardingErrMsg string      // Connection check or test generation failed
+
+// Setup wizard actions, behind the choices on its error and result screens
+const (
+	onboardingActionRetry    = "retry"
+	onboardingActionSettings = "settings"
+	onboardingActionManual   = "manual"
+	onboardingActionModels   = "models"
+	onboardingActionSave     = "save"
+	onboardingActionQuit     = "quit"
+)
+
+// listModels asks the provider for the models it offers, which also checks
+// that the endpoint is reachable and the API key works
+func listModels(ctx context.Context, c *Config) ([]string, error) {
+	switch c.Provider {
+	case "ollama":
+	case "openai":
+		if c.OpenAIURL == "" {
+			return nil, fmt.Errorf("OpenAI endpoint URL not configured")
+		}
+		if c.OpenAIAPIKey == "" && os.Getenv("OPENAI_API_KEY") == "" {
+			return nil, fmt.Errorf("OpenAI API key not set. Enter one or set the OPENAI_API_KEY env var")
+		}
+	default:
+		if os.Getenv("ANTHROPIC_API_KEY") == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable not set. Set it in your shell and run gitcat again, or choose another provider")
+		}
+	}
+	models, err := provider.ListModels(ctx, c.ProviderSettings())
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 && c.Provider == "ollama" {
+		return nil, fmt.Errorf("Ollama at %s has no models yet. Pull one with: ollama pull %s", c.OllamaURL, config.DefaultOllamaModel)
+	}
+	return models, nil
+}
+
+// onboardingModel is the setup wizard run on first launch, before any
+// config file exists
+type onboardingModel struct {
+	ctx        context.Context // Cancelled when the wizard exits, aborting a request still running
+	phase      string          // "provider", "url", "api_key", "checking", "check_failed", "commit_model", "pr_model", "testing", "tested", "test_failed", "error"
+	config     Config          // Built-in defaults with the wizard's choices applied
+	configPath string
+	providers  []string
+	models     []string // Models the provider offers, nil to type a name
+	input      string   // Current input value
+	sample     string
+	errMsg     string
+	choices    []string
+	actions    []string // Action behind each choice
+	cursor     int
+	saved      bool
+	width      int
+}
+
+func initialOnboardingModel(ctx context.Context, config *Config, configPath string) onboardingModel {
+	m := onboardingModel{
+		ctx:        ctx,
+		phase:      "provider",
+		config:     *config,
+		configPath: configPath,
+		providers:  []string{"anthropic", "ollama", "openai"},
+	}
+	m.cursor = max(slices.Index(m.providers, config.Provider), 0)
+	return m
+}
+
+// enterSettings asks for whatever the chosen provider needs to connect, or
+// checks the connection straight away when it needs nothing more
+func (m onboardingModel) enterSettings() (onboardingModel, tea.Cmd) {
+	switch m.config.Provider {
+	case "ollama":
+		m.phase = "url"
+		m.input = m.config.OllamaURL
+		if m.input == "" {
+			m.input = config.DefaultOllamaURL
+		}
+		return m, nil
+	case "openai":
+		m.phase = "url"
+		m.input = m.config.OpenAIURL
+		return m, nil
+	}
+	return m.check()
+}
+
+// check fetches the provider's model list
+func (m onboardingModel) check() (onboardingModel, tea.Cmd) {
+	m.phase = "checking"
+	m.errMsg = ""
+	ctx, config := m.ctx, m.config
+	return m, func() tea.Msg {
+		models, err := listModels(ctx, &config)
+		if err != nil {
+			return onboardingErrMsg(err.Error())
+		}
+		return onboardingModelsMsg(models)
+	}
+}
+
+// enterModelPhase picks the commit or PR model, from the provider's list
+// with current preselected, or by name when there is no list
+func (m onboardingModel) enterModelPhase(phase, current string) onboardingModel {
+	m.phase = phase
+	m.input = current
+	m.cursor = 0
+	for i, name := range m.models {
+		if name == current || name == current+":latest" {
+			m.cursor = i
+		}
+	}
+	return m
+}
+
+// test writes a commit message for a sample diff with each chosen model
+func (m onboardingModel) test() (onboardingModel, tea.Cmd) {
+	m.phase = "testing"
+	m.errMsg = ""
+	ctx, base := m.ctx, m.config
+	return m, func() tea.Msg {
+		prompt := generate.CommitPrompt(commitRequest{
+			Diff:  onboardingSampleDiff,
+			Type:  CommitType{Name: "fix", Description: "A bug fix"},
+			Scope: "greet",
+		}).Default
+		var sample tea.Msg
+		for _, model := range slices.Compact([]string{base.CommitModel, base.PRModel}) {
+			c := base
+			c.Model = model
+			msg := generateMsg(ctx, &c, prompt, c.GetCommitParams(), false)
+			result, ok := msg.(commitMsgMsg)
+			if !ok {
+				return onboardingErrMsg(fmt.Sprintf("%s: %s", model, msg))
+			}
+			if sample == nil {
+				sample = onboardingSampleMsg(result)
+			}
+		}
+		return sample
+	}
+}
+
+// save writes the config and ends the wizard
+func (m onboardingModel) save() (onboardingModel, tea.Cmd) {
+	// Set Model as fallback for backward compatibility
+	m.config.Model = m.config.CommitModel
+	if err := saveConfig(&m.config); err != nil {
+		m.phase = "error"
+		m.errMsg = err.Error()
+		return m, nil
+	}
+	m.saved = true
+	return m, tea.Quit
+}
+
+func (m onboardingModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m onboardingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case onboardingModelsMsg:
+		m.models = msg
+		return m.enterModelPhase("commit_model", m.config.GetCommitModel()), nil
+
+	case onboardingSampleMsg:
+		m.phase = "tested"
+		m.sample = strings.TrimSpace(string(msg))
+		m.cursor = 0
+		m.choices = []string{tr("Save and continue"), tr("Pick other models"), tr("Quit without saving")}
+		m.actions = []string{onboardingActionSave, onboardingActionModels, onboardingActionQuit}
+		return m, nil
+
+	case onboardingErrMsg:
+		m.errMsg = string(msg)
+		m.cursor = 0
+		if m.phase == "testing" {
+			m.phase = "test_failed"
+			m.choices = []string{tr("Retry"), tr("Pick other models"), tr("Change provider settings"), tr("Save anyway"), tr("Quit without saving")}
+			m.actions = []string{onboardingActionRetry, onboardingActionModels, onboardingActionSettings, onboardingActionSave, onboardingActionQuit}
+			return m, nil
+		}
+		m.phase = "check_failed"
+		m.choices = []string{tr("Retry"), tr("Change provider settings"), tr("Type model names instead"), tr("Quit without saving")}
+		m.actions = []string{onboardingActionRetry, onboardingActionSettings, onboardingActionManual, onboardingActionQuit}
+		return m, nil
+
+	case tea.KeyMsg:
+		inputPhase := m.phase == "url" || m.phase == "api_key" ||
+			((m.phase == "commit_model" || m.phase == "pr_model") && len(m.models) == 0)
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, tea.Quit
+		case "q":
+			if !inputPhase && m.phase != "checking" && m.phase != "testing" {
+				return m, tea.Quit
+			}
+		case "up", "k":
+			if !inputPhase || msg.String() == "up" {
+				if m.cursor > 0 {
+					m.cursor--
+				}
+				return m, nil
+			}
+		case "down", "j":
+			if !inputPhase || msg.String() == "down" {
+				limit := len(m.choices)
+				switch m.phase {
+				case "provider":
+					limit = len(m.providers)
+				case "commit_model", "pr_model":
+					limit = len(m.models)
+				}
+				if m.cursor < limit-1 {
+					m.cursor++
+				}
+				return m, nil
+			}
+		case "backspace":
+			if inputPhase {
+				m.input = trimLastRune(m.input)
+			}
+			return m, nil
+		case "enter":
+			return m.enter()
+		}
+		if inputPhase {
+			if key, ok := keyInput(msg, false); ok {
+				m.input += key
+			}
+		}
+	}
+	return m, nil
+}
+
+// enter confirms the current step
+func (m onboardingModel) enter() (tea.Model, tea.Cmd) {
+	switch m.phase {
+	case "provider":
+		providerName := m.providers[m.cursor]
+		if providerName != m.config.Provider {
+			m.config.CommitModel, m.config.PRModel = "", ""
+			m.config.Model = config.DefaultModel(providerName)
+		}
+		m.config.Provider = providerName
+		return m.enterSettings()
+
+	case "url":
+		url := strings.TrimSpace(m.input)
+		if url == "" {
+			return m, nil
+		}
+		if m.config.Provider == "ollama" {
+			m.config.OllamaURL = url
+			return m.check()
+		}
+		m.config.OpenAIURL = url
+		m.phase = "api_key"
+		m.input = m.config.OpenAIAPIKey
+		return m, nil
+
+	case "api_key":
+		m.config.OpenAIAPIKey = strings.TrimSpace(m.input)
+		return m.check()
+
+	case "commit_model", "pr_model":
+		name := strings.TrimSpace(m.input)
+		if len(m.models) > 0 {
+			name = m.models[m.cursor]
+		}
+		if name == "" {
+			return m, nil
+		}
+		if m.phase == "commit_model" {
+			m.config.CommitModel = name
+			return m.enterModelPhase("pr_model", m.config.GetPRModel()), nil
+		}
+		m.config.PRModel = name
+		return m.test()
+
+	case "check_failed", "test_failed", "tested":
+		switch m.actions[m.cursor] {
+		case onboardingActionRetry:
+			if m.phase == "test_failed" {
+				return m.test()
+			}
+			return m.check()
+		case onboardingActionSettings:
+			m.phase = "provider"
+			m.cursor = slices.Index(m.providers, m.config.Provider)
+			return m, nil
+		case onboardingActionManual:
+			m.models = nil
+			return m.enterModelPhase("commit_model", m.config.GetCommitModel()), nil
+		case onboardingActionModels:
+			return m.enterModelPhase("commit_model", m.config.GetCommitModel()), nil
+		case onboardingActionSave:
+			return m.save()
+		case onboardingActionQuit:
+			return m, tea.Quit
+		}
+
+	case "error":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m onboardingModel) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	wrap := lipgloss.NewStyle()
+	if m.width > 0 {
+		wrap = wrap.Width(m.width)
+	}
+	list := func(items []string, from int) string {
+		s := ""
+		for i := from; i < len(items) && i < from+onboardingModelsShown; i++ {
+			cursor, item := " ", items[i]
+			if m.cursor == i {
+				cursor = ">"
+				item = selectedStyle.Render(item)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, item)
+		}
+		return s
+	}
+	choicesView := func() string {
+		return list(m.choices, 0) + "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
+	}
+
+	switch m.phase {
+	case "provider":
+		s := titleStyle.Render(tr("Welcome to gitcat")) + "\n\n"
+		s += wrap.Render(tr("There is no config file yet, so let's set up the AI provider gitcat writes messages with. Nothing is saved until the end.")) + "\n\n"
+		s += tr("Select LLM Provider") + "\n\n"
+		descriptions := map[string]string{
+			"anthropic": tr("Claude models, with ANTHROPIC_API_KEY"),
+			"ollama":    tr("Open-source models on a local Ollama server"),
+			"openai":    tr("An OpenAI-compatible API, e.g. OpenAI or a LiteLLM proxy"),
+		}
+		var items []string
+		for _, p := range m.providers {
+			items = append(items, fmt.Sprintf("%-10s %s", p, dimStyle.Render(descriptions[p])))
+		}
+		return s + list(items, 0) + "\n" + tr("(use arrow keys to select, enter to confirm, esc to quit)") + "\n"
+
+	case "url":
+		s := labelStyle.Render(tr("Provider:")) + " " + m.config.Provider + "\n\n"
+		if m.config.Provider == "ollama" {
+			s = titleStyle.Render(tr("Configure Ollama Server URL")) + "\n\n" + s + tr("Enter Ollama server URL:") + "\n"
+		} else {
+			s = titleStyle.Render(tr("Configure OpenAI-compatible Endpoint URL")) + "\n\n" + s + tr("Enter endpoint base URL (e.g. http://localhost:4000):") + "\n"
+		}
+		s += fmt.Sprintf("> %s_\n", m.input)
+		return s + "\n" + tr("(press enter when done)") + "\n"
+
+	case "api_key":
+		s := titleStyle.Render(tr("Configure OpenAI-compatible API Key")) + "\n\n"
+		s += labelStyle.Render(tr("Endpoint URL:")) + " " + m.config.OpenAIURL + "\n\n"
+		s += tr("Enter API key (or leave empty to use OPENAI_API_KEY env var):") + "\n"
+		s += fmt.Sprintf("> %s_\n", strings.Repeat("*", len([]rune(m.input))))
+		return s + "\n" + tr("(press enter when done)") + "\n"
+
+	case "checking":
+		return titleStyle.Render(tr("Connecting to %s...", m.config.Provider)) + "\n"
+
+	case "check_failed":
+		s := titleStyle.Render(tr("⚠️  Could not connect to %s", m.config.Provider)) + "\n\n"
+		s += wrap.Foreground(lipgloss.Color("8")).Render(m.errMsg) + "\n\n"
+		return s + choicesView()
+
+	case "commit_model", "pr_model":
+		var s string
+		if m.phase == "commit_model" {
+			s = titleStyle.Render(tr("Configure Commit Model")) + "\n\n"
+			s += tr("Enter model for commit message generation (fast model recommended):") + "\n"
+		} else {
+			s = titleStyle.Render(tr("Configure PR Model")) + "\n\n"
+			s += labelStyle.Render(tr("Commit model:")) + " " + m.config.CommitModel + "\n\n"
+			s += tr("Enter model for PR description generation (smarter model recommended):") + "\n"
+		}
+		if len(m.models) == 0 {
+			s += fmt.Sprintf("> %s_\n", m.input)
+			s += "\n" + dimStyle.Render(tr("Default: %s", config.DefaultModel(m.config.Provider))) + "\n"
+			return s + tr("(press enter when done)") + "\n"
+		}
+		from := max(0, min(m.cursor-onboardingModelsShown/2, len(m.models)-onboardingModelsShown))
+		s += "\n" + list(m.models, from)
+		if len(m.models) > onboardingModelsShown {
+			s += dimStyle.Render(tr("%d of %d models", m.cursor+1, len(m.models))) + "\n"
+		}
+		return s + "\n" + tr("(use arrow keys to select, enter to confirm, esc to quit)") + "\n"
+
+	case "testing":
+		return titleStyle.Render(tr("Testing a sample commit message...")) + "\n"
+
+	case "tested":
+		s := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render(tr("✓ %s is working. A sample commit message:", m.config.Provider)) + "\n\n"
+		s += wrap.Foreground(lipgloss.Color("14")).Render(m.sample) + "\n\n"
+		s += labelStyle.Render(tr("Commit model:")) + " " + m.config.CommitModel + "\n"
+		s += labelStyle.Render(tr("PR model:")) + " " + m.config.PRModel + "\n"
+		s += labelStyle.Render(tr("Config file:")) + " " + m.configPath + "\n\n"
+		return s + choicesView()
+
+	case "test_failed":
+		s := titleStyle.Render(tr("⚠️  API Error")) + "\n\n"
+		s += errorStyle.Render(tr("The sample generation failed:")) + "\n"
+		s += wrap.Foreground(lipgloss.Color("8")).Render(m.errMsg) + "\n\n"
+		return s + choicesView()
+
+	case "error":
+		s := titleStyle.Render(tr("Error saving configuration")) + "\n\n"
+		s += errorStyle.Render(m.errMsg) + "\n\n"
+		return s + tr("Press enter to exit or esc to quit") + "\n"
+	}
+	return ""
+}
+
+// runOnboarding runs the setup wizard and returns the saved config, or
+// exits when the user quits it
+func runOnboarding(config *Config, configPath string) *Config {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := tea.NewProgram(initialOnboardingModel(ctx, config, configPath))
+	finalModel, err := p.Run()
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running setup: %v\n", err)
+		os.Exit(exitError)
+	}
+	m := finalModel.(onboardingModel)
+	if m.phase == "error" {
+		os.Exit(exitError)
+	}
+	if !m.saved {
+		fmt.Fprintln(os.Stderr, "Setup cancelled; nothing was saved. Run gitcat again or gitcat config to set up.")
+		os.Exit(exitUserAborted)
+	}
+	fmt.Fprintf(os.Stderr, "Saved config to %s\n", configPath)
+	return &m.config
+}
+
+// shouldOnboard reports whether this run should start with the setup
+// wizard: there is no config file yet and someone is at the terminal to
+// answer it. Offline runs don't need a provider.
+func shouldOnboard(configPath string) bool {
+	if *offlineFlag {
+		return false
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		return false
+	}
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// labelerFiles are where actions/labeler keeps its configuration
+var labelerFiles = []string{".github/labeler.yml", ".github/labeler.yaml"}
+
+// pathLabelRules maps PR labels to the globs of the paths that earn them.
+// Globs are matched against whole repo-relative paths, with "**" matching
+// any number of directories ("docs/**", "**/*.md").
+type pathLabelRules map[string][]string
+
+// loadPathLabelRules combines the labels for changed paths from the config
+// (pr_path_labels), the repository's git config (gitcat.pathLabel, each
+// value "label=glob glob ...", repeatable), and its actions/labeler
+// configuration
+func loadPathLabelRules() pathLabelRules {
+	rules := make(pathLabelRules)
+	for label, globs := range getEffectiveConfig().PRPathLabels {
+		rules[label] = append(rules[label], globs...)
+	}
+	for _, value := range gitConfigAll("gitcat.pathLabel") {
+		label, globs, ok := strings.Cut(value, "=")
+		if label = strings.TrimSpace(label); !ok || label == "" {
+			debugf("gitcat.pathLabel %q: expected label=glob ...", value)
+			continue
+		}
+		rules[label] = append(rules[label], strings.Fields(globs)...)
+	}
+	if root, err := getRepoRoot(); err == nil {
+		for _, name := range labelerFiles {
+			if data, err := os.ReadFile(filepath.Join(root, name)); err == nil {
+				for label, globs := range parseLabeler(string(data)) {
+					rules[label] = append(rules[label], globs...)
+				}
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// parseLabeler reads the changed-file globs from an actions/labeler
+// configuration, in both the v4 form (a label followed by its globs) and
+// the v5 form (changed-files with any-glob-to-any-file). Labels using other
+// conditions (branches, all-globs-to-..., negated globs) can't be judged
+// the same way here and are skipped.
+func parseLabeler(data string) pathLabelRules {
+	rules := make(pathLabelRules)
+	unsupported := make(map[string]bool)
+	label := ""
+	for _, line := range strings.Split(data, "\n") {
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '-' {
+			key, value, ok := cutYAMLKey(trimmed)
+			if !ok {
+				label = ""
+				continue
+			}
+			label = key
+			rules[label] = append(rules[label], yamlList(value)...)
+			continue
+		}
+		if label == "" {
+			continue
+		}
+		item := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+		if key, value, ok := cutYAMLKey(item); ok {
+			switch key {
+			case "changed-files":
+			case "any-glob-to-any-file":
+				rules[label] = append(rules[label], yamlList(value)...)
+			default:
+				unsupported[label] = true
+			}
+			continue
+		}
+		rules[label] = append(rules[label], yamlList(item)...)
+	}
+	for label, globs := range rules {
+		if unsupported[label] || len(globs) == 0 || slices.ContainsFunc(globs, func(g string) bool { return strings.HasPrefix(g, "!") }) {
+			debugf("labeler: skipping label %q, which uses conditions gitcat doesn't support", label)
+			delete(rules, label)
+		}
+	}
+	return rules
+}
+
+// cutYAMLKey splits a "key: value" line, unquoting the key
+func cutYAMLKey(line string) (key, value string, ok bool) {
+	if line != "" && (line[0] == '\'' || line[0] == '"') {
+		end := strings.IndexByte(line[1:], line[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest := line[1:end+1], line[end+2:]
+		value, ok = strings.CutPrefix(rest, ":")
+		return key, strings.TrimSpace(value), ok
+	}
+	if key, ok := strings.CutSuffix(line, ":"); ok {
+		return key, "", true
+	}
+	key, value, ok = strings.Cut(line, ": ")
+	return key, strings.TrimSpace(value), ok
+}
+
+// yamlList reads a scalar or a flow sequence ("[a, 'b']") of YAML strings
+func yamlList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	items := []string{value}
+	if inner, ok := strings.CutPrefix(value, "["); ok {
+		items = strings.Split(strings.TrimSuffix(inner, "]"), ",")
+	}
+	var list []string
+	for _, item := range items {
+		item = strings.Trim(strings.TrimSpace(item), `'"`)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// match returns the labels earned by the changed paths, sorted
+func (rules pathLabelRules) match(paths []string) []string {
+	var labels []string
+	for label, globs := range rules {
+		matched := slices.ContainsFunc(paths, func(p string) bool {
+			return slices.ContainsFunc(globs, func(glob string) bool {
+				return matchSegments(strings.Split(strings.TrimPrefix(glob, "/"), "/"), strings.Split(p, "/"))
+			})
+		})
+		if matched {
+			labels = append(labels, label)
+		}
+	}
+	slices.Sort(labels)
+	return labels
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// onWindows is true when gitcat was built for Windows, where config lives
+// under %AppData%, there may be no sh on PATH, and files can use CRLF
+const onWindows = runtime.GOOS == "windows"
+
+// defaultEditor is the editor used when git and the environment name none
+func defaultEditor() string {
+	if onWindows {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// shellCommand runs script with sh, as git runs editors and aliases, with
+// args as $1, $2, and so on. Git for Windows ships sh but only puts git
+// itself on PATH, so there sh is looked up beside git.exe.
+func shellCommand(script string, args ...string) *exec.Cmd {
+	return exec.Command(findShell(), append([]string{"-c", script, "sh"}, args...)...)
+}
+
+// shellCommandContext is shellCommand for a script that cancelling ctx
+// stops
+func shellCommandContext(ctx context.Context, script string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, findShell(), append([]string{"-c", script, "sh"}, args...)...)
+}
+
+// findShell returns the sh to run scripts with
+func findShell() string {
+	if !onWindows {
+		return "sh"
+	}
+	if path, err := exec.LookPath("sh"); err == nil {
+		return path
+	}
+	if git, err := exec.LookPath("git"); err == nil {
+		// git.exe is in Git\cmd or Git\bin, and sh.exe in Git\bin and
+		// Git\usr\bin
+		root := filepath.Dir(filepath.Dir(git))
+		for _, dir := range []string{"bin", filepath.Join("usr", "bin")} {
+			path := filepath.Join(root, dir, "sh.exe")
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return "sh"
+}
+
+// normalizeNewlines turns CRLF line endings into LF, so text from Windows
+// tools, editors, and files checked out with core.autocrlf reads the same
+// as anywhere else
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// enableTerminalColors turns on ANSI escape handling in a Windows console,
+// which PowerShell and cmd.exe leave off, so the styled output printed
+// outside the TUI shows colors rather than raw escape codes. The TUI turns
+// it on by itself; elsewhere this does nothing.
+func enableTerminalColors() {
+	if _, err := termenv.EnableVirtualTerminalProcessing(termenv.NewOutput(os.Stdout)); err != nil {
+		debugf("enabling terminal colors: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// prBasePickerLimit caps the commits the range picker lists
+const prBasePickerLimit = 30
+
+// prBaseRef returns the ref whose commits the PR is generated from: the one
+// picked or given with --base, else the default branch
+func (m model) prBaseRef() string {
+	if m.prBase != "" {
+		return m.prBase
+	}
+	return defaultBaseRef()
+}
+
+// enterPRBasePhase lists the commit ranges the PR can be generated from: all
+// commits since the default branch, or only those from one of the branch's
+// recent commits onwards
+func (m model) enterPRBasePhase() model {
+	m.phase = "pr_base"
+	m.cursor = 0
+	base := defaultBaseRef()
+	m.prBaseRefs = []string{""}
+	m.choices = []string{tr("All commits since %s", base)}
+	output, err := runCommand(exec.Command("git", "log", "-n", fmt.Sprint(prBasePickerLimit), "--format=%H %h %s", base+".."+m.currentBranch))
+	if err != nil {
+		debugf("listing branch commits: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		sha, commit, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		// The range starts after the commit's parent; a root commit has none
+		if _, err := runCommand(exec.Command("git", "rev-parse", "--verify", "--quiet", sha+"^")); err != nil {
+			continue
+		}
+		if m.prBase == sha+"^" {
+			m.cursor = len(m.choices)
+		}
+		m.prBaseRefs = append(m.prBaseRefs, sha+"^")
+		m.choices = append(m.choices, tr("From %s", commit))
+	}
+	return m
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// prDiffNone turns the branch diff in the PR prompt off
+const prDiffNone = "none"
+
+// prDiffDepth returns how much of the branch diff the PR prompt includes:
+// --pr-diff, git config gitcat.prDiff, or pr_diff in the config, as a diff
+// depth. It returns "" when the prompt has only the commit log.
+func prDiffDepth() string {
+	depth := *prDiffFlag
+	if depth == "" {
+		depth = gitConfigString("gitcat.prDiff")
+	}
+	if depth == "" {
+		depth = getEffectiveConfig().PRDiff
+	}
+	if !validDiffDepth(depth) {
+		if depth != "" && depth != prDiffNone {
+			debugf("ignoring unknown PR diff depth %q", depth)
+		}
+		return ""
+	}
+	return depth
+}
+
+// shallowerDepth returns whichever of two diff depths sends less
+func shallowerDepth(a, b string) string {
+	rank := map[string]int{diffDepthFull: 0, diffDepthHunks: 1, diffDepthStat: 2}
+	if rank[a] >= rank[b] {
+		return a
+	}
+	return b
+}
+
+// diffSecretsError reports that a diff was kept out of a prompt because
+// the secret scan flagged it
+type diffSecretsError struct {
+	findings []secretFinding
+}
+
+func (e diffSecretsError) Error() string {
+	var found []string
+	for _, f := range e.findings {
+		found = append(found, f.path+": "+f.rule)
+	}
+	return "the diff looks like it contains secrets (" + strings.Join(found, ", ") + ")"
+}
+
+// prPromptDiff returns the changes branch made since it left base, filtered
+// like the commit prompt's diff, at depth or each file's configured depth
+// where that is shallower. A diff over budget tokens falls back to stat
+// depth, and is left out if even that doesn't fit. Unless secret_scan is
+// off, a diff the secret scan flags is left out too, with a
+// diffSecretsError, so that callers can go on without it or refuse.
+func (m model) prPromptDiff(base, branch, depth string, budget int, config *Config) (string, error) {
+	output, err := runCommand(exec.Command("git", "diff", base+"..."+branch))
+	if err != nil {
+		return "", fmt.Errorf("git diff %s...%s failed: %w", base, branch, err)
+	}
+	return m.fitPromptDiff(string(output), depth, budget, config)
+}
+
+// commitPromptDiff returns the changes of one commit as prPromptDiff does.
+// A root commit is diffed against the empty tree.
+func (m model) commitPromptDiff(sha, depth string, budget int, config *Config) (string, error) {
+	output, err := runCommand(exec.Command("git", "diff-tree", "-p", "--root", "--no-commit-id", sha))
+	if err != nil {
+		return "", fmt.Errorf("git diff-tree %s failed: %w", sha, err)
+	}
+	return m.fitPromptDiff(string(output), depth, budget, config)
+}
+
+// fitPromptDiff filters a raw diff for a prompt and fits it to budget, as
+// prPromptDiff describes
+func (m model) fitPromptDiff(raw, depth string, budget int, config *Config) (string, error) {
+	raw = normalizeNewlines(raw)
+	for _, depth := range []string{depth, diffDepthStat} {
+		diff := m.filterPromptDiff(raw, func(path string) string {
+			return shallowerDepth(depth, diffDepthFor(config, path))
+		})
+		if diff, ok := fitDiffToBudget(diff, budget, config.MaxDiffLines); ok {
+			if config.SecretScan != secretScanOff {
+				if findings := scanDiffForSecrets(diff, m.promptAutoExclude); len(findings) > 0 {
+					return "", diffSecretsError{findings}
+				}
+			}
+			return diff, nil
+		}
+	}
+	debugf("branch diff too large for the PR prompt")
+	return "", nil
+}
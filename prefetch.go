@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prPrefetch is PR content being generated in the background for the
+// commit at head
+type prPrefetch struct {
+	head    string
+	cancel  context.CancelFunc
+	result  tea.Msg // prContentMsg or prContentErrMsg, nil while the request runs
+	waiting bool    // The user accepted the PR and pr_generating waits for the result
+}
+
+// prPrefetchMsg delivers the background PR content generated for head
+type prPrefetchMsg struct {
+	head string
+	msg  tea.Msg
+}
+
+// prefetchPRContent starts generating the PR title and body as soon as the
+// commits are made, so that the model works while the user answers the push
+// prompt and the push runs. Runs where no PR can be created don't spend a
+// request on it.
+func (m model) prefetchPRContent() (model, tea.Cmd) {
+	m.prPrefetch = nil
+	if *offlineFlag || m.gerrit != nil || m.prBase != "" || checkPRRemote(prRemote(m.currentBranch)) != nil {
+		return m, nil
+	}
+	head := currentHead()
+	if head == "" {
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.prPrefetch = &prPrefetch{head: head, cancel: cancel}
+	generatePR := m.generatePRContent(ctx)
+	return m, func() tea.Msg {
+		return prPrefetchMsg{head: head, msg: generatePR()}
+	}
+}
+
+// usePrefetchedPR moves on from accepting the PR with the content generated
+// in the background: straight to the preview when it is ready, otherwise
+// waiting for it. ok is false when there is none for the branch as it is
+// now, and the content must be generated afresh.
+func (m model) usePrefetchedPR() (_ tea.Model, _ tea.Cmd, ok bool) {
+	prefetch := m.prPrefetch
+	m.prPrefetch = nil
+	if prefetch == nil || m.prBase != "" || currentHead() != prefetch.head {
+		return m, nil, false
+	}
+	m.phase = "pr_generating"
+	if prefetch.result == nil {
+		m.prPrefetch = &prPrefetch{head: prefetch.head, cancel: prefetch.cancel, waiting: true}
+		m, tick := m.showRequest(getEffectiveConfig().GetPRModel(), prefetch.cancel)
+		return m, tick, true
+	}
+	debugf("using prefetched PR content for %s", prefetch.head)
+	next, cmd := m.Update(prefetch.result)
+	return next, cmd, true
+}
+
+// receivePrefetchedPR stores background PR content as it arrives, or shows
+// it if the user is already waiting for it
+func (m model) receivePrefetchedPR(msg prPrefetchMsg) (tea.Model, tea.Cmd) {
+	if m.prPrefetch == nil || m.prPrefetch.head != msg.head {
+		return m, nil // Stale: more commits were made since
+	}
+	if m.prPrefetch.waiting && m.phase == "pr_generating" {
+		m.prPrefetch = nil
+		return m.Update(msg.msg)
+	}
+	m.prPrefetch = &prPrefetch{head: msg.head, cancel: m.prPrefetch.cancel, result: msg.msg}
+	return m, nil
+}
+
+// currentHead returns the commit HEAD points at, "" if it can't be read
+func currentHead() string {
+	output, err := runCommand(exec.Command("git", "rev-parse", "HEAD"))
+	if err != nil {
+		debugf("reading HEAD: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// codeownersFiles are where GitHub looks for CODEOWNERS, in its order
+var codeownersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// prMeta is who and what a new PR is tagged with. Reviewers, assignees, and
+// labels come from flags and config; suggested reviewers are the code
+// owners of the changed paths, requested unless toggled off.
+type prMeta struct {
+	reviewers    []string
+	assignees    []string
+	labels       []string
+	suggested    []string
+	useSuggested bool
+}
+
+// loadPRMeta combines the --reviewer, --assignee, and --label flags with the
+// config defaults (pr_reviewers and friends) and the repository's own
+// (git config gitcat.reviewer, gitcat.assignee, gitcat.label, each
+// repeatable), plus the labels earned by the paths changed since base (see
+// loadPathLabelRules). With suggest_reviewers on, code owners of those paths
+// are suggested as well.
+func loadPRMeta(base string) *prMeta {
+	config := getEffectiveConfig()
+	meta := &prMeta{
+		reviewers: mergeValues(config.PRReviewers, gitConfigAll("gitcat.reviewer"), *reviewerFlags),
+		assignees: mergeValues(config.PRAssignees, gitConfigAll("gitcat.assignee"), *assigneeFlags),
+		labels:    mergeValues(config.PRLabels, gitConfigAll("gitcat.label"), *labelFlags),
+	}
+	pathLabels := loadPathLabelRules()
+	suggest := config.SuggestReviewers || gitConfigBool("gitcat.suggestReviewers")
+	if len(pathLabels) == 0 && !suggest {
+		return meta
+	}
+
+	output, err := runCommand(exec.Command("git", "diff", "--name-only", base+"...HEAD"))
+	if err != nil {
+		debugf("git diff --name-only %s...HEAD failed: %v", base, err)
+		return meta
+	}
+	paths := strings.Fields(string(output))
+	meta.labels = mergeValues(meta.labels, pathLabels.match(paths))
+	if !suggest {
+		return meta
+	}
+	owners := codeOwners(paths)
+	if len(owners) == 0 {
+		return meta
+	}
+	// GitHub refuses review requests to the PR's author
+	self := githubLogin()
+	for _, owner := range owners {
+		if !strings.EqualFold(owner, self) && !slices.Contains(meta.reviewers, owner) {
+			meta.suggested = append(meta.suggested, owner)
+		}
+	}
+	meta.useSuggested = len(meta.suggested) > 0
+	return meta
+}
+
+// mergeValues joins lists of values, dropping blanks and repeats
+func mergeValues(lists ...[]string) []string {
+	var merged []string
+	for _, list := range lists {
+		for _, value := range list {
+			if value = strings.TrimSpace(value); value != "" && !slices.Contains(merged, value) {
+				merged = append(merged, value)
+			}
+		}
+	}
+	return merged
+}
+
+// gitConfigAll reads every value of a multi-valued git config key
+func gitConfigAll(key string) []string {
+	output, err := runCommand(exec.Command("git", "config", "--get-all", key))
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+// codeownersRule is one CODEOWNERS line: a path pattern and its owners
+type codeownersRule struct {
+	pattern ignoreMatcher
+	owners  []string
+}
+
+// loadCodeowners reads the repository's CODEOWNERS file. Owners are kept as
+// gh takes them ("user" or "org/team"); email owners are dropped, since a
+// review can't be requested from an address.
+func loadCodeowners() []codeownersRule {
+	root, err := getRepoRoot()
+	if err != nil {
+		return nil
+	}
+	for _, name := range codeownersFiles {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		var rules []codeownersRule
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			rule := codeownersRule{pattern: parseIgnorePatterns(fields[:1])}
+			for _, owner := range fields[1:] {
+				if strings.HasPrefix(owner, "#") {
+					break // Trailing comment
+				}
+				if login, ok := strings.CutPrefix(owner, "@"); ok {
+					rule.owners = append(rule.owners, login)
+				}
+			}
+			rules = append(rules, rule)
+		}
+		return rules
+	}
+	return nil
+}
+
+// codeOwners returns the owners of the given paths, in the order they are
+// first seen. As on GitHub, the last rule matching a path decides its owners.
+func codeOwners(paths []string) []string {
+	rules := loadCodeowners()
+	var owners []string
+	for _, p := range paths {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].pattern.match(p) {
+				owners = mergeValues(owners, rules[i].owners)
+				break
+			}
+		}
+	}
+	return owners
+}
+
+// ghArgs renders the metadata as gh pr create flags
+func (meta *prMeta) ghArgs() []string {
+	return meta.flags("--reviewer", "--assignee", "--label")
+}
+
+// ghEditArgs renders the metadata as gh pr edit flags, which add to what
+// the PR already has
+func (meta *prMeta) ghEditArgs() []string {
+	return meta.flags("--add-reviewer", "--add-assignee", "--add-label")
+}
+
+// flags renders the reviewers, assignees, and labels with the given flags
+func (meta *prMeta) flags(reviewerFlag, assigneeFlag, labelFlag string) []string {
+	if meta == nil {
+		return nil
+	}
+	var args []string
+	reviewers := meta.reviewers
+	if meta.useSuggested {
+		reviewers = mergeValues(reviewers, meta.suggested)
+	}
+	for _, reviewer := range reviewers {
+		args = append(args, reviewerFlag, reviewer)
+	}
+	for _, assignee := range meta.assignees {
+		args = append(args, assigneeFlag, assignee)
+	}
+	for _, label := range meta.labels {
+		args = append(args, labelFlag, label)
+	}
+	return args
+}
+
+// prMetaView lists the reviewers, assignees, labels, and auto-merge
+// strategy on the PR preview
+func (m model) prMetaView() string {
+	meta := m.prMeta
+	if meta == nil {
+		return ""
+	}
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	var s string
+	if len(meta.reviewers) > 0 || len(meta.suggested) > 0 {
+		s += labelStyle.Render(tr("Reviewers:")) + " " + strings.Join(meta.reviewers, ", ")
+		if len(meta.suggested) > 0 {
+			if len(meta.reviewers) > 0 {
+				s += ", "
+			}
+			suggested := strings.Join(meta.suggested, ", ")
+			if meta.useSuggested {
+				s += suggested + " " + dimStyle.Render(tr("(suggested from CODEOWNERS)"))
+			} else {
+				s += dimStyle.Render(tr("%s (suggested from CODEOWNERS, not requested)", suggested))
+			}
+		}
+		s += "\n"
+	}
+	if len(meta.assignees) > 0 {
+		s += labelStyle.Render(tr("Assignees:")) + " " + strings.Join(meta.assignees, ", ") + "\n"
+	}
+	if len(meta.labels) > 0 {
+		s += labelStyle.Render(tr("Labels:")) + " " + strings.Join(meta.labels, ", ") + "\n"
+	}
+	if m.autoMerge != "" {
+		s += labelStyle.Render(tr("Auto-merge:")) + " " + tr("%s, once required checks pass", m.autoMerge) + "\n"
+	}
+	if s != "" {
+		s += "\n"
+	}
+	return s
+}
+
+// Merge strategies for auto-merge (Config.MergeStrategy), as gh pr merge
+// flags
+var mergeStrategies = []string{"merge", "squash", "rebase"}
+
+// defaultMergeStrategy is used when auto-merge is on without a strategy
+const defaultMergeStrategy = "merge"
+
+// autoMergeStrategy returns how to auto-merge created PRs, or "" when
+// auto-merge is off. --merge-strategy implies --auto-merge. Flags win over
+// the repository's git config (gitcat.autoMerge, gitcat.mergeStrategy),
+// which wins over the config file.
+func autoMergeStrategy() string {
+	config := getEffectiveConfig()
+	if !*autoMergeFlag && *mergeStrategyFlag == "" && !config.AutoMerge && !gitConfigBool("gitcat.autoMerge") {
+		return ""
+	}
+	if *mergeStrategyFlag != "" {
+		return *mergeStrategyFlag
+	}
+	output, err := runCommand(exec.Command("git", "config", "--get", "gitcat.mergeStrategy"))
+	if strategy := strings.TrimSpace(string(output)); err == nil && slices.Contains(mergeStrategies, strategy) {
+		return strategy
+	}
+	if config.MergeStrategy != "" {
+		return config.MergeStrategy
+	}
+	return defaultMergeStrategy
+}
+
+// enableAutoMerge turns on auto-merge for the PR at url (the current
+// branch's if empty), so it is merged with strategy once its required checks
+// pass
+func enableAutoMerge(url, strategy string) error {
+	if host, repo, number, ok := parseGiteaPRURL(url); ok {
+		return giteaEnableAutoMerge(host, repo, number, strategy)
+	}
+	if useGitHubAPI() {
+		return apiEnableAutoMerge(url, strategy)
+	}
+	args := []string{"pr", "merge", "--auto", "--" + strategy}
+	if url != "" {
+		args = append(args, url)
+	}
+	output, err := runCommand(exec.Command("gh", args...))
+	if err != nil {
+		return fmt.Errorf("gh pr merge --auto failed: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// autoMergeView reports on auto-merge after the PR is created
+func (m model) autoMergeView() string {
+	if m.autoMergeErr != "" {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(tr("⚠️  Could not enable auto-merge: %s", m.autoMergeErr)) + "\n"
+	}
+	if m.autoMerge != "" && (m.didCreatePR || m.didUpdatePR) {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Auto-merge (%s) is on; the PR merges once its required checks pass", m.autoMerge)) + "\n"
+	}
+	return ""
+}
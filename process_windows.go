@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// killProcessTreeOnCancel makes cancelling cmd's context kill it along
+// with the processes it started, which taskkill finds by their parent
+func killProcessTreeOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+	}
+}
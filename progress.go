@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// spinnerFrames animate the screens that wait on the model
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// requestCanceledMsg is what a provider call returns when its context was
+// cancelled; the screen that cancelled it has already moved on
+type requestCanceledMsg struct{}
+
+// startRequestMsg starts the request of a flow that opens on a waiting
+// screen, since Init can't record it in the model
+type startRequestMsg struct{}
+
+// requestTickMsg advances the spinner of request id
+type requestTickMsg struct{ id int }
+
+// pendingRequest is the API request, or the verify command, a waiting
+// screen shows progress for
+type pendingRequest struct {
+	id      int
+	model   string // The model, or the verify command
+	started time.Time
+	cancel  context.CancelFunc
+}
+
+// requestTick schedules the next spinner frame for request id
+func requestTick(id int) tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return requestTickMsg{id}
+	})
+}
+
+// beginRequest gives a request to modelName a context that esc cancels, and
+// starts showing its progress. A request still running from before is
+// cancelled.
+func (m model) beginRequest(modelName string) (model, context.Context, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m, tick := m.showRequest(modelName, cancel)
+	return m, ctx, tick
+}
+
+// showRequest shows the progress of a request already running, which
+// cancel aborts
+func (m model) showRequest(modelName string, cancel context.CancelFunc) (model, tea.Cmd) {
+	m = m.trackRequest(modelName, cancel)
+	m.spinnerFrame = 0
+	return m, requestTick(m.requestSeq)
+}
+
+// trackRequest records a request or command that is starting, which esc
+// and quitting abort through cancel. A request still running from before
+// is cancelled.
+func (m model) trackRequest(name string, cancel context.CancelFunc) model {
+	m.cancelPending()
+	m.requestSeq++
+	m.pending = &pendingRequest{id: m.requestSeq, model: name, started: time.Now(), cancel: cancel}
+	return m
+}
+
+// cancelPending aborts the request shown last, if it is still running
+func (m model) cancelPending() {
+	if m.pending != nil {
+		m.pending.cancel()
+	}
+}
+
+// cancelRequests aborts every request still running when the UI exits: the
+// one shown last, which covers a round of candidates, and the PR content
+// generated in the background
+func (m model) cancelRequests() {
+	m.cancelPending()
+	if m.prPrefetch != nil {
+		m.prPrefetch.cancel()
+	}
+}
+
+// waiting reports whether the current screen waits on the pending request
+func (m model) waiting() bool {
+	switch m.phase {
+	case "generating", "pr_generating", "review_generating", "split_planning", "verifying":
+		return m.pending != nil
+	}
+	return false
+}
+
+// tickRequest advances the spinner while its request's screen is showing
+func (m model) tickRequest(msg requestTickMsg) (model, tea.Cmd) {
+	if !m.waiting() || m.pending.id != msg.id {
+		return m, nil
+	}
+	m.spinnerFrame = (m.spinnerFrame + 1) % len(spinnerFrames)
+	return m, requestTick(msg.id)
+}
+
+// cancelRequest aborts the request the screen waits on at the user's
+// request, and shows the screen a failed request would, so it can be
+// retried or done without the model
+func (m model) cancelRequest() (tea.Model, tea.Cmd) {
+	m.cancelPending()
+	m.pending = nil
+	debugf("request cancelled in phase %s", m.phase)
+	cancelled := tr("Cancelled.")
+	switch m.phase {
+	case "generating":
+		// Late results of a round of candidates are dropped
+		m.candidateRound++
+		return m.Update(commitMsgErrMsg(cancelled))
+	case "pr_generating":
+		m.prPrefetch = nil
+		return m.Update(prContentErrMsg(cancelled))
+	case "review_generating":
+		return m.Update(reviewErrMsg(cancelled))
+	}
+	return m.goBack(), nil
+}
+
+// requestProgress shows a spinner with the model and how long the request
+// has been running, for the waiting screens
+func (m model) requestProgress() string {
+	if m.pending == nil {
+		return ""
+	}
+	elapsed := time.Since(m.pending.started).Truncate(time.Second)
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(
+		fmt.Sprintf("%s %s", spinnerFrames[m.spinnerFrame], tr("%s via %s · %s · esc to cancel", m.pending.model, getEffectiveConfig().Provider, elapsed))) + "\n"
+}
+
+// requestElapsed returns how long the pending request has been running
+func (m model) requestElapsed() time.Duration {
+	if m.pending == nil {
+		return 0
+	}
+	return time.Since(m.pending.started)
+}
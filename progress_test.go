@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestQuitCancelsPendingRequest(t *testing.T) {
+	keys := []tea.KeyMsg{
+		{Type: tea.KeyCtrlC},
+		{Type: tea.KeyRunes, Runes: []rune("q")},
+	}
+	phases := []string{"generating", "pr_generating", "review_generating", "split_planning", "verifying"}
+	for _, phase := range phases {
+		for _, key := range keys {
+			t.Run(phase+"/"+key.String(), func(t *testing.T) {
+				useConfig(t, &Config{})
+				m := model{phase: phase}
+				m, ctx, _ := m.beginRequest("test-model")
+				prefetchCtx, prefetchCancel := context.WithCancel(context.Background())
+				m.prPrefetch = &prPrefetch{cancel: prefetchCancel}
+
+				next, cmd := m.Update(key)
+				if cmd == nil || cmd() != tea.Quit() {
+					t.Fatalf("%s in %s did not quit", key, phase)
+				}
+				if got := next.(model); got.exitCode != exitUserAborted {
+					t.Errorf("exit code %d, want %d", got.exitCode, exitUserAborted)
+				}
+				if ctx.Err() == nil {
+					t.Error("the pending request was not cancelled")
+				}
+				if prefetchCtx.Err() == nil {
+					t.Error("the background PR request was not cancelled")
+				}
+			})
+		}
+	}
+}
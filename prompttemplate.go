@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/burritocatai/gitcat/config"
+)
+
+// repoPromptDir is where a repository keeps prompt templates that override
+// the user's, relative to its root
+const repoPromptDir = ".gitcat/prompts"
+
+// prPromptData is what a pr.tmpl prompt template can use
+type prPromptData struct {
+	Log               string // The branch's commits since the base
+	Diff              string // The diff against the base with pr_diff, otherwise ""
+	Base              string
+	Context           string // Linked issues and the glossary
+	TitleInstructions string // How to write the title: conventional or plain
+	BodyInstructions  string // How to write the body, filling in the repository's PR template if any
+	Default           string // The built-in prompt, for templates that only add to it
+}
+
+// promptTemplatePaths returns where the prompt template name is looked up,
+// the repository's first so that a project's style wins over the user's
+func promptTemplatePaths(name string) []string {
+	var paths []string
+	if root, err := getRepoRoot(); err == nil {
+		paths = append(paths, filepath.Join(root, repoPromptDir, name+".tmpl"))
+	}
+	if dir, err := config.Dir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "prompts", name+".tmpl"))
+	}
+	return paths
+}
+
+// renderPromptTemplate builds the prompt from the template name in the
+// repository's .gitcat/prompts, or else the prompts beside config.json, and
+// returns fallback when neither has one. A template that fails to parse or
+// render is an error rather than silently replaced by the built-in prompt.
+func renderPromptTemplate(name string, data any, fallback string) (string, error) {
+	for _, path := range promptTemplatePaths(name) {
+		text, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading prompt template: %w", err)
+		}
+		tmpl, err := template.New(filepath.Base(path)).Parse(string(text))
+		if err != nil {
+			return "", fmt.Errorf("invalid prompt template %s: %w", path, err)
+		}
+		var prompt strings.Builder
+		if err := tmpl.Execute(&prompt, data); err != nil {
+			return "", fmt.Errorf("invalid prompt template %s: %w", path, err)
+		}
+		debugf("prompt from template %s", path)
+		return prompt.String(), nil
+	}
+	return fallback, nil
+}
+
+// groundingRule tells the model to keep to what source (the commits, the
+// diff) shows, for prompts that describe changes in prose
+func groundingRule(source string) string {
+	return fmt.Sprintf("IMPORTANT: Only describe what is explicitly present in the %s. Do NOT infer, assume, or fabricate details that are not directly present there. Where something is unclear, keep the description general rather than guessing specifics.", source)
+}
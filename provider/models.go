@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListModels asks the provider for the models it offers, which also checks
+// that the endpoint is reachable and the API key works
+func ListModels(ctx context.Context, s Settings) ([]string, error) {
+	var endpoint string
+	header := http.Header{}
+	switch s.Provider {
+	case Ollama:
+		endpoint = strings.TrimRight(s.OllamaURL, "/") + "/api/tags"
+	case OpenAI:
+		if s.OpenAIURL == "" {
+			return nil, errors.New("OpenAI endpoint URL not configured")
+		}
+		apiKey := s.openAIKey()
+		if apiKey == "" {
+			return nil, errors.New("OpenAI API key not set")
+		}
+		endpoint = strings.TrimRight(s.OpenAIURL, "/") + "/v1/models"
+		header.Set("Authorization", "Bearer "+apiKey)
+	default:
+		apiKey := s.anthropicKey()
+		if apiKey == "" {
+			return nil, errors.New("ANTHROPIC_API_KEY environment variable not set")
+		}
+		endpoint = strings.TrimSuffix(anthropicURL, "/messages") + "/models?limit=100"
+		header.Set("x-api-key", apiKey)
+		s.setAnthropicHeaders(header)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header = header
+	resp, err := s.doRequest(&http.Client{}, req, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("error making request to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	// Ollama lists {"models": [{"name": ...}]}; Anthropic and OpenAI list
+	// {"data": [{"id": ...}]}
+	var list struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	var models []string
+	for _, m := range list.Models {
+		models = append(models, m.Name)
+	}
+	for _, m := range list.Data {
+		models = append(models, m.ID)
+	}
+	// Anthropic lists the newest models first; the others in no useful order
+	if s.Provider == Ollama || s.Provider == OpenAI {
+		slices.Sort(models)
+	}
+	return models, nil
+}
+
+// OllamaContextLength returns the context window Ollama runs model with
+// when its Modelfile sets one (num_ctx), capped at the length the model was
+// trained on. It returns 0 when the model leaves it to the server's default.
+func OllamaContextLength(ctx context.Context, s Settings, model string) (int, error) {
+	endpoint := strings.TrimRight(s.OllamaURL, "/") + "/api/show"
+	jsonData, err := json.Marshal(map[string]string{"model": model})
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.doRequest(&http.Client{}, req, model, 0)
+	if err != nil {
+		return 0, fmt.Errorf("Ollama: error making request to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("Ollama: error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Ollama: API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	// parameters is the Modelfile's PARAMETER lines, e.g. "num_ctx 8192";
+	// model_info has "<architecture>.context_length"
+	var show struct {
+		Parameters string         `json:"parameters"`
+		ModelInfo  map[string]any `json:"model_info"`
+	}
+	if err := json.Unmarshal(body, &show); err != nil {
+		return 0, fmt.Errorf("Ollama: error parsing response: %w", err)
+	}
+	numCtx := 0
+	for _, line := range strings.Split(show.Parameters, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "num_ctx" {
+			numCtx, _ = strconv.Atoi(fields[1])
+		}
+	}
+	for key, value := range show.ModelInfo {
+		if trained, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") && trained > 0 {
+			numCtx = min(numCtx, int(trained))
+		}
+	}
+	return numCtx, nil
+}
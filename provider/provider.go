@@ -0,0 +1,298 @@
+// Package provider sends prompts to the LLM providers gitcat supports: the
+// Anthropic API, a local Ollama server, and OpenAI-compatible endpoints such
+// as LiteLLM. gitcat's own commands go through it, so editor plugins and
+// other Go tools get the same requests, rate limiting, and errors without
+// running the TUI.
+//
+// Callers bring their own prompt. The generate package builds gitcat's
+// commit message prompt from a diff and sends it with settings from the
+// config package.
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// The providers Settings.Provider names
+const (
+	Anthropic = "anthropic"
+	Ollama    = "ollama"
+	OpenAI    = "openai"
+)
+
+const (
+	// AnthropicVersion is the anthropic-version header sent unless
+	// Settings.AnthropicVersion overrides it
+	AnthropicVersion = "2023-06-01"
+	anthropicURL     = "https://api.anthropic.com/v1/messages"
+)
+
+// Settings says which provider requests go to and how to reach it
+type Settings struct {
+	Provider          string   // Anthropic (also when empty), Ollama, or OpenAI
+	AnthropicAPIKey   string   // Defaults to ANTHROPIC_API_KEY
+	AnthropicVersion  string   // anthropic-version header, defaults to AnthropicVersion
+	AnthropicBeta     []string // Beta features sent in the anthropic-beta header
+	OllamaURL         string   // Ollama server, e.g. http://localhost:11434
+	OpenAIURL         string   // OpenAI-compatible endpoint, without /v1
+	OpenAIAPIKey      string   // Defaults to OPENAI_API_KEY
+	RequestsPerMinute int      // Spaces requests out to stay under this many a minute, 0 for no limit
+
+	// Logf, if set, logs each request's URL, model, prompt size, status, and
+	// timing. Headers are never logged since they carry API keys.
+	Logf func(format string, args ...any)
+}
+
+// Params tunes one generation. Zero values leave the provider's defaults,
+// except MaxTokens, which Anthropic and OpenAI-compatible APIs require.
+type Params struct {
+	MaxTokens   int      // Response budget (not sent to Ollama)
+	Temperature *float64 // Sampling temperature
+	Stop        []string // Stop sequences that end the response
+}
+
+func (s Settings) logf(format string, args ...any) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// anthropicKey returns the Anthropic API key, from the settings or the
+// environment
+func (s Settings) anthropicKey() string {
+	if s.AnthropicAPIKey != "" {
+		return s.AnthropicAPIKey
+	}
+	return os.Getenv("ANTHROPIC_API_KEY")
+}
+
+// openAIKey returns the OpenAI-compatible API key, from the settings or the
+// environment
+func (s Settings) openAIKey() string {
+	if s.OpenAIAPIKey != "" {
+		return s.OpenAIAPIKey
+	}
+	return os.Getenv("OPENAI_API_KEY")
+}
+
+// setAnthropicHeaders sets the API version and beta features the settings
+// opt into on a request to the Anthropic API
+func (s Settings) setAnthropicHeaders(header http.Header) {
+	version := s.AnthropicVersion
+	if version == "" {
+		version = AnthropicVersion
+	}
+	header.Set("anthropic-version", version)
+	if len(s.AnthropicBeta) > 0 {
+		header.Set("anthropic-beta", strings.Join(s.AnthropicBeta, ","))
+	}
+}
+
+type anthropicRequest struct {
+	Model         string    `json:"model"`
+	MaxTokens     int       `json:"max_tokens"`
+	Messages      []message `json:"messages"`
+	Temperature   *float64  `json:"temperature,omitempty"`
+	StopSequences []string  `json:"stop_sequences,omitempty"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// OpenAI-compatible API types (for LiteLLM and similar proxies)
+type openAIRequest struct {
+	Model       string    `json:"model"`
+	Messages    []message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	Stop        []string  `json:"stop,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message message `json:"message"`
+	} `json:"choices"`
+}
+
+// Ollama API types
+type ollamaRequest struct {
+	Model    string         `json:"model"`
+	Messages []message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Options  *ollamaOptions `json:"options,omitempty"`
+}
+
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+type ollamaResponse struct {
+	Model   string  `json:"model"`
+	Message message `json:"message"`
+}
+
+// Generate sends prompt to model and returns its response, trimmed and with
+// LF line endings. When ctx is cancelled the error wraps context.Canceled.
+func Generate(ctx context.Context, s Settings, model, prompt string, params Params) (string, error) {
+	var result string
+	var err error
+	switch s.Provider {
+	case Ollama:
+		result, err = generateWithOllama(ctx, s, model, prompt, params)
+	case OpenAI:
+		result, err = generateWithOpenAI(ctx, s, model, prompt, params)
+	default:
+		result, err = generateWithAnthropic(ctx, s, model, prompt, params)
+	}
+	return strings.ReplaceAll(result, "\r\n", "\n"), err
+}
+
+// generateWithAnthropic sends a request to the Anthropic API
+func generateWithAnthropic(ctx context.Context, s Settings, model, prompt string, params Params) (string, error) {
+	apiKey := s.anthropicKey()
+	if apiKey == "" {
+		return "", errors.New("ANTHROPIC_API_KEY environment variable not set")
+	}
+
+	reqBody := anthropicRequest{
+		Model:         model,
+		MaxTokens:     params.MaxTokens,
+		Messages:      []message{{Role: "user", Content: prompt}},
+		Temperature:   params.Temperature,
+		StopSequences: params.Stop,
+	}
+	header := http.Header{}
+	header.Set("x-api-key", apiKey)
+	s.setAnthropicHeaders(header)
+
+	var apiResp anthropicResponse
+	client := &http.Client{Timeout: 30 * time.Second}
+	if err := s.post(ctx, client, anthropicURL, header, reqBody, model, len(prompt), &apiResp); err != nil {
+		return "", err
+	}
+	if len(apiResp.Content) == 0 {
+		return "", errors.New("no content in API response")
+	}
+	return strings.TrimSpace(apiResp.Content[0].Text), nil
+}
+
+// generateWithOllama sends a request to the Ollama API
+func generateWithOllama(ctx context.Context, s Settings, model, prompt string, params Params) (string, error) {
+	reqBody := ollamaRequest{
+		Model:    model,
+		Messages: []message{{Role: "user", Content: prompt}},
+		Stream:   false,
+	}
+	// Local models get no response budget: thinking models would run out
+	// of one sized for the answer alone
+	if params.Temperature != nil || len(params.Stop) > 0 {
+		reqBody.Options = &ollamaOptions{Temperature: params.Temperature, Stop: params.Stop}
+	}
+
+	var apiResp ollamaResponse
+	client := &http.Client{Timeout: 60 * time.Second} // Longer timeout for local models
+	if err := s.post(ctx, client, strings.TrimRight(s.OllamaURL, "/")+"/api/chat", http.Header{}, reqBody, model, len(prompt), &apiResp); err != nil {
+		return "", fmt.Errorf("Ollama: %w", err)
+	}
+	result := strings.TrimSpace(apiResp.Message.Content)
+	if result == "" {
+		return "", errors.New("no content in Ollama API response")
+	}
+	return result, nil
+}
+
+// generateWithOpenAI sends a request to an OpenAI-compatible API (e.g. LiteLLM)
+func generateWithOpenAI(ctx context.Context, s Settings, model, prompt string, params Params) (string, error) {
+	if s.OpenAIURL == "" {
+		return "", errors.New("OpenAI endpoint URL not configured. Set it via --openai-url or 'gitcat config'")
+	}
+	apiKey := s.openAIKey()
+	if apiKey == "" {
+		return "", errors.New("OpenAI API key not set. Use --openai-api-key, config, or OPENAI_API_KEY env var")
+	}
+
+	reqBody := openAIRequest{
+		Model:       model,
+		MaxTokens:   params.MaxTokens,
+		Messages:    []message{{Role: "user", Content: prompt}},
+		Temperature: params.Temperature,
+		Stop:        params.Stop,
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+apiKey)
+
+	var apiResp openAIResponse
+	client := &http.Client{Timeout: 30 * time.Second}
+	if err := s.post(ctx, client, strings.TrimRight(s.OpenAIURL, "/")+"/v1/chat/completions", header, reqBody, model, len(prompt), &apiResp); err != nil {
+		return "", err
+	}
+	if len(apiResp.Choices) == 0 {
+		return "", errors.New("no choices in API response")
+	}
+	return strings.TrimSpace(apiResp.Choices[0].Message.Content), nil
+}
+
+// post sends reqBody as JSON to endpoint when the rate limiter gives it a
+// turn, and decodes a 200 response into apiResp
+func (s Settings) post(ctx context.Context, client *http.Client, endpoint string, header http.Header, reqBody any, model string, promptLen int, apiResp any) error {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("error marshaling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.doProviderRequest(client, req, model, promptLen)
+	if err != nil {
+		return fmt.Errorf("error making request to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, apiResp); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+	return nil
+}
+
+// doRequest sends req and logs its metadata, response status, and duration
+func (s Settings) doRequest(client *http.Client, req *http.Request, model string, promptLen int) (*http.Response, error) {
+	s.logf("http %s %s model=%s prompt_bytes=%d", req.Method, req.URL.Redacted(), model, promptLen)
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		s.logf("http %s %s failed after %s: %v", req.Method, req.URL.Redacted(), time.Since(start), err)
+		return nil, err
+	}
+	s.logf("http %s %s status=%d in %s", req.Method, req.URL.Redacted(), resp.StatusCode, time.Since(start))
+	return resp, nil
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRateLimitAttempts caps how often a request the provider rejected
+	// with 429 Too Many Requests is sent
+	maxRateLimitAttempts = 4
+	// rateLimitBackoff is how long to hold off after a 429 that doesn't say
+	// when to retry
+	rateLimitBackoff = 10 * time.Second
+	// maxRateLimitWait is the longest a request waits for its turn; a
+	// provider that asks for longer is reported as an error instead
+	maxRateLimitWait = 2 * time.Minute
+)
+
+// rateLimiter paces the requests to the provider so that batches of them,
+// such as rewording a range, planning and writing a split, or a round of
+// candidates, wait their turn instead of failing one after another with 429
+type rateLimiter struct {
+	mu   sync.Mutex
+	next time.Time // Earliest time the next request may start
+}
+
+// providerLimiter paces every request to the providers in this process
+var providerLimiter = &rateLimiter{}
+
+// requestInterval returns how far apart RequestsPerMinute spaces requests,
+// 0 when it isn't set
+func (s Settings) requestInterval() time.Duration {
+	if s.RequestsPerMinute <= 0 {
+		return 0
+	}
+	return time.Minute / time.Duration(s.RequestsPerMinute)
+}
+
+// wait blocks until a request may start, then reserves the time until the
+// one after it, interval later, may start. It fails without waiting when
+// the turn is further off than maxRateLimitWait, and when ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, interval time.Duration, logf func(string, ...any)) error {
+	l.mu.Lock()
+	start := l.next
+	if now := time.Now(); start.Before(now) {
+		start = now
+	}
+	delay := time.Until(start)
+	if delay > maxRateLimitWait {
+		l.mu.Unlock()
+		return fmt.Errorf("rate limited by the provider until %s", start.Format(time.Kitchen))
+	}
+	l.next = start.Add(interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	logf("rate limit: waiting %s for the next request", delay.Round(time.Millisecond))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// holdUntil keeps requests from starting before t
+func (l *rateLimiter) holdUntil(t time.Time, logf func(string, ...any)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.next) {
+		logf("rate limit: holding requests until %s", t.Format(time.RFC3339))
+		l.next = t
+	}
+}
+
+// observe holds off later requests as the provider's response asks:
+// after a 429 until retry-after says, and once the requests left in the
+// window run out until it resets, read from Anthropic's
+// anthropic-ratelimit-requests-* and OpenAI's x-ratelimit-*-requests headers
+func (l *rateLimiter) observe(resp *http.Response, logf func(string, ...any)) {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		l.holdUntil(time.Now().Add(retryAfter(resp.Header)), logf)
+		return
+	}
+	if resp.Header.Get("anthropic-ratelimit-requests-remaining") == "0" {
+		if reset, err := time.Parse(time.RFC3339, resp.Header.Get("anthropic-ratelimit-requests-reset")); err == nil {
+			l.holdUntil(reset, logf)
+		}
+	}
+	if resp.Header.Get("x-ratelimit-remaining-requests") == "0" {
+		if reset, err := time.ParseDuration(resp.Header.Get("x-ratelimit-reset-requests")); err == nil {
+			l.holdUntil(time.Now().Add(reset), logf)
+		}
+	}
+}
+
+// retryAfter returns how long a 429's retry-after header asks to wait, in
+// seconds or as a date, or rateLimitBackoff without one
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("retry-after")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return rateLimitBackoff
+}
+
+// doProviderRequest sends a request to the model provider when the
+// limiter gives it a turn, and sends it again when the provider answers
+// 429, up to maxRateLimitAttempts times. The client's timeout applies to
+// each attempt, not to the time spent waiting.
+func (s Settings) doProviderRequest(client *http.Client, req *http.Request, model string, promptLen int) (*http.Response, error) {
+	interval := s.requestInterval()
+	for attempt := 1; ; attempt++ {
+		if err := providerLimiter.wait(req.Context(), interval, s.logf); err != nil {
+			return nil, err
+		}
+		if attempt > 1 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		resp, err := s.doRequest(client, req, model, promptLen)
+		if err != nil {
+			return nil, err
+		}
+		providerLimiter.observe(resp, s.logf)
+		if resp.StatusCode != http.StatusTooManyRequests || attempt == maxRateLimitAttempts {
+			return resp, nil
+		}
+		resp.Body.Close()
+		s.logf("rate limit: %s answered 429, retrying (attempt %d of %d)", req.URL.Host, attempt+1, maxRateLimitAttempts)
+	}
+}
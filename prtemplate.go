@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prTemplateDirs are where GitHub looks for a pull request template, in its
+// order; the file name is matched case-insensitively
+var prTemplateDirs = []string{".github", "", "docs"}
+
+// maxPRTemplateLength caps the template included in the PR prompt
+const maxPRTemplateLength = 4000
+
+// loadPRTemplate returns the repository's pull_request_template.md, or ""
+// when it has none. Templates chosen by query parameter, in
+// .github/PULL_REQUEST_TEMPLATE/, aren't used by default and are skipped.
+func loadPRTemplate() string {
+	root, err := getRepoRoot()
+	if err != nil {
+		return ""
+	}
+	for _, dir := range prTemplateDirs {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(entry.Name(), "pull_request_template.md") {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(root, dir, entry.Name()))
+			if err != nil {
+				debugf("reading PR template: %v", err)
+				continue
+			}
+			if template := strings.TrimSpace(string(data)); template != "" {
+				return truncateRunes(template, maxPRTemplateLength)
+			}
+		}
+	}
+	return ""
+}
+
+// prBodyInstructions tells the model how to write the PR body: by filling
+// in the repository's template when it has one, otherwise as a bullet list
+func prBodyInstructions(template string) string {
+	if template == "" {
+		return `2. A PR body that:
+   - Summarizes the changes in bullet points based strictly on the commit messages
+   - Notes any breaking changes if explicitly mentioned
+   - Do NOT add implementation details, test descriptions, or context that is not in the log`
+	}
+	return fmt.Sprintf(`2. A PR body that fills in the repository's pull request template below:
+   - Keep the template's headings, in order, and write each section from the git log
+   - Follow any instructions in HTML comments, then drop the comments
+   - Leave checklist items unchecked ("- [ ]") unless the log shows they are done
+   - Where the log says nothing relevant to a section, write "N/A" rather than guessing
+   - Note any breaking changes if explicitly mentioned
+
+Pull request template:
+%s`, template)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// semanticPRMarkers are signs in a workflow file that PR titles are checked
+// against Conventional Commits
+var semanticPRMarkers = []string{"semantic-pull-request", "semantic-pr", "commitlint-pr-title"}
+
+// conventionalPRTitles reports whether PR titles should be in conventional
+// format: when conventional_pr_title is set (per repo: git config
+// gitcat.conventionalPRTitle true), or when the repository checks its PR
+// titles with a semantic PR workflow or the Semantic Pull Requests app
+func conventionalPRTitles() bool {
+	if getEffectiveConfig().ConventionalPRTitle || gitConfigBool("gitcat.conventionalPRTitle") {
+		return true
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(root, ".github", "semantic.yml")); err == nil {
+		return true
+	}
+	workflows, _ := filepath.Glob(filepath.Join(root, ".github", "workflows", "*.y*ml"))
+	for _, path := range workflows {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, marker := range semanticPRMarkers {
+			if strings.Contains(string(data), marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// prTitleInstructions tells the model how to write the PR title
+func prTitleInstructions(conventional bool) string {
+	if !conventional {
+		return "1. A clear, concise PR title (max 72 characters) that summarizes the changes"
+	}
+	return `1. A clear, concise PR title (max 72 characters) that summarizes the changes, in Conventional Commits format: "type(scope): description", with the type and scope that best cover the commits (feat if any adds a feature, else fix if any fixes a bug) and "!" after them if any commit is a breaking change`
+}
+
+// branchSubjects returns the subjects of the commits on branch since base
+func branchSubjects(base, branch string) []string {
+	output, err := runCommand(exec.Command("git", "log", "--format=%s", base+".."+branch))
+	if err != nil {
+		debugf("git log %s..%s failed: %v", base, branch, err)
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+// branchTitlePrefix derives a PR title prefix from the branch's commit
+// subjects: feat if any commit is a feature, else fix if any is a fix, else
+// the most common type; the scope the commits share, if they all have the
+// same one; and "!" if any is breaking. It returns "" when no commit is
+// conventional.
+func branchTitlePrefix(subjects []string) string {
+	counts := make(map[string]int)
+	scopes := make(map[string]bool)
+	breaking := false
+	for _, subject := range subjects {
+		commitType := messageType(subject)
+		if commitType == "" {
+			continue
+		}
+		counts[commitType]++
+		scopes[messageScope(subject)] = true
+		prefix, _, _ := strings.Cut(subject, ":")
+		breaking = breaking || strings.HasSuffix(prefix, "!")
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+	commitType := rankByCount(counts)[0]
+	for _, preferred := range []string{"fix", "feat"} {
+		if counts[preferred] > 0 {
+			commitType = preferred
+		}
+	}
+	prefix := commitType
+	if len(scopes) == 1 {
+		for scope := range scopes {
+			if scope != "" {
+				prefix += "(" + scope + ")"
+			}
+		}
+	}
+	if breaking {
+		prefix += "!"
+	}
+	return prefix
+}
+
+// normalizePRTitle puts a PR title into conventional format. A title that
+// already has one of the configured types is kept; otherwise the prefix
+// derived from the branch's commits replaces whatever prefix it has. The
+// title is returned unchanged when no prefix can be derived.
+func normalizePRTitle(title string, types []CommitType, subjects []string) string {
+	if validPRTitleType(title, types) {
+		return title
+	}
+	prefix := branchTitlePrefix(subjects)
+	if prefix == "" {
+		return title
+	}
+	description := title
+	if messageType(title) != "" {
+		_, description, _ = strings.Cut(title, ":")
+	}
+	description = strings.TrimSpace(description)
+	// Lowercase the first word unless it is an acronym ("API", "CI")
+	if first, size := utf8.DecodeRuneInString(description); size < len(description) {
+		if next, _ := utf8.DecodeRuneInString(description[size:]); !unicode.IsUpper(next) {
+			description = string(unicode.ToLower(first)) + description[size:]
+		}
+	}
+	return prefix + ": " + description
+}
+
+// validPRTitleType reports whether title starts with a configured type
+func validPRTitleType(title string, types []CommitType) bool {
+	commitType := messageType(title)
+	return commitType != "" && slices.ContainsFunc(types, func(t CommitType) bool { return t.Name == commitType })
+}
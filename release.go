@@ -0,0 +1,559 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/burritocatai/gitcat/generate"
+)
+
+// commitBodyLimit caps how much of each commit body goes into prompts built
+// from commit history, such as release notes and standup summaries
+const commitBodyLimit = 600
+
+// editTargetRelease is the editor target for release notes
+const editTargetRelease = "release"
+
+// releaseCommit is one commit going into the release notes
+type releaseCommit struct {
+	sha     string
+	message string
+}
+
+// subject returns the first line of the commit message
+func (c releaseCommit) subject() string {
+	subject, _, _ := strings.Cut(c.message, "\n")
+	return subject
+}
+
+// breaking reports whether the commit is a conventional breaking change:
+// "type!:" in the subject or a BREAKING CHANGE footer
+func (c releaseCommit) breaking() bool {
+	if prefix, _, ok := strings.Cut(c.subject(), ":"); ok && messageType(c.message) != "" && strings.HasSuffix(prefix, "!") {
+		return true
+	}
+	for _, line := range strings.Split(c.message, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseSections are the groups release notes are organized into. A
+// commit goes into the first section it matches.
+var releaseSections = []struct {
+	title string
+	match func(c releaseCommit) bool
+}{
+	{"Breaking Changes", releaseCommit.breaking},
+	{"Features", func(c releaseCommit) bool { return messageType(c.message) == "feat" }},
+	{"Fixes", func(c releaseCommit) bool { return messageType(c.message) == "fix" }},
+	{"Other Changes", func(releaseCommit) bool { return true }},
+}
+
+// groupReleaseCommits sorts commits into releaseSections, returning one
+// group per section
+func groupReleaseCommits(commits []releaseCommit) [][]releaseCommit {
+	groups := make([][]releaseCommit, len(releaseSections))
+	for _, c := range commits {
+		for i, section := range releaseSections {
+			if section.match(c) {
+				groups[i] = append(groups[i], c)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// lastTag returns the most recent tag reachable from HEAD, or "" when there
+// is none
+func lastTag() string {
+	output, err := runCommand(exec.Command("git", "describe", "--tags", "--abbrev=0"))
+	if err != nil {
+		debugf("no previous tag: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// releaseCommits lists the commits since tag, oldest first, or all commits
+// when tag is "". Merge commits are left out; their changes arrive with the
+// commits they merge.
+func releaseCommits(tag string) ([]releaseCommit, error) {
+	rev := "HEAD"
+	if tag != "" {
+		rev = tag + "..HEAD"
+	}
+	output, err := runCommand(exec.Command("git", "log", "--reverse", "--no-merges", "--format=%h%x00%B%x1e", rev))
+	if err != nil {
+		return nil, fmt.Errorf("git log %s failed: %w\n%s", rev, err, string(output))
+	}
+	var commits []releaseCommit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		sha, message, ok := strings.Cut(strings.TrimSpace(record), "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, releaseCommit{sha: sha, message: strings.TrimSpace(message)})
+	}
+	return commits, nil
+}
+
+// semverTag matches a tag such as v1.2.3 or 1.2.3
+var semverTag = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+// nextVersion suggests the version after tag from the commits since it: a
+// major bump for breaking changes (minor before 1.0), a minor bump for
+// features, and a patch bump otherwise. It returns "" when tag isn't a
+// semantic version.
+func nextVersion(tag string, commits []releaseCommit) string {
+	if tag == "" {
+		return "v0.1.0"
+	}
+	parts := semverTag.FindStringSubmatch(tag)
+	if parts == nil {
+		return ""
+	}
+	major, _ := strconv.Atoi(parts[2])
+	minor, _ := strconv.Atoi(parts[3])
+	patch, _ := strconv.Atoi(parts[4])
+	groups := groupReleaseCommits(commits)
+	switch {
+	case len(groups[0]) > 0 && major > 0:
+		major, minor, patch = major+1, 0, 0
+	case len(groups[0]) > 0 || len(groups[1]) > 0:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", parts[1], major, minor, patch)
+}
+
+// offlineReleaseNotes lists the commit subjects under their section
+// headings, for use without an AI provider
+func offlineReleaseNotes(commits []releaseCommit) string {
+	var b strings.Builder
+	for i, group := range groupReleaseCommits(commits) {
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n", releaseSections[i].title)
+		for _, c := range group {
+			fmt.Fprintf(&b, "- %s (%s)\n", c.subject(), c.sha)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// releasePrompt asks for release notes for version, from the commits since
+// previous grouped the way the notes should be
+func releasePrompt(version, previous string, commits []releaseCommit, glossary map[string]string) string {
+	since := "the start of the project"
+	if previous != "" {
+		since = previous
+	}
+	var b strings.Builder
+	for i, group := range groupReleaseCommits(commits) {
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", releaseSections[i].title)
+		for _, c := range group {
+			fmt.Fprintf(&b, "- %s (%s)\n", c.subject(), c.sha)
+			_, body, _ := strings.Cut(c.message, "\n")
+			if body = strings.TrimSpace(body); body != "" {
+				if len(body) > commitBodyLimit {
+					body = body[:commitBodyLimit] + "..."
+				}
+				b.WriteString("  " + strings.ReplaceAll(body, "\n", "\n  ") + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return fmt.Sprintf(`You are a release notes generator. Based on the following commits made since %s, grouped by conventional commit type, write the release notes for version %s.
+
+%s
+
+Commits:
+%s%s
+Write the notes in Markdown under "## Breaking Changes", "## Features", "## Fixes", and "## Other Changes" headings, in that order, leaving out sections with no changes. Under each heading, write one bullet per change in plain words for the project's users, combining commits that make the same change. For breaking changes, say what users must do when upgrading.
+
+Respond with ONLY the release notes, with no title line, explanations, or code blocks.`, since, version, groundingRule("commits"), b.String(), generate.GlossaryPrompt(glossary))
+}
+
+type releaseNotesMsg string
+type releaseNotesErrMsg string // API error during release notes generation
+
+// releaseFinishedMsg reports the outcome of tagging and publishing
+type releaseFinishedMsg struct {
+	summary string
+	err     error
+	code    int // Exit code for err
+}
+
+// generateReleaseNotes asks the PR model for the release notes
+func generateReleaseNotes(ctx context.Context, version, previous string, commits []releaseCommit) tea.Cmd {
+	return func() tea.Msg {
+		config := getEffectiveConfig()
+		config.Model = config.GetPRModel()
+		prompt := releasePrompt(version, previous, commits, config.Glossary)
+
+		msg := generateMsg(ctx, config, prompt, config.GetPRParams(), true)
+		switch msg := msg.(type) {
+		case prContentMsg:
+			return releaseNotesMsg(strings.TrimSpace(string(msg)))
+		case prContentErrMsg:
+			return releaseNotesErrMsg(msg)
+		}
+		return msg
+	}
+}
+
+// createReleaseTag makes an annotated tag at HEAD with the notes as its
+// message
+func createReleaseTag(version, notes string) error {
+	cmd := exec.Command("git", "tag", "--annotate", "--cleanup=verbatim", "--file=-", version)
+	cmd.Stdin = strings.NewReader(version + "\n\n" + notes + "\n")
+	output, err := runCommand(cmd)
+	if err != nil {
+		return fmt.Errorf("git tag %s failed: %w\n%s", version, err, string(output))
+	}
+	return nil
+}
+
+// pushReleaseTag pushes the tag to remote
+func pushReleaseTag(remote, version string, noVerify bool) error {
+	args := []string{"push"}
+	if noVerify {
+		args = append(args, "--no-verify")
+	}
+	output, err := runCommand(exec.Command("git", append(args, remote, "refs/tags/"+version)...))
+	if err != nil {
+		return fmt.Errorf("git push %s %s failed: %w\n%s", remote, version, err, string(output))
+	}
+	return nil
+}
+
+// createGitHubRelease publishes a release for the pushed tag on repo with
+// gh, or the GitHub API without it, and returns the release's URL
+func createGitHubRelease(repo, version, notes string) (string, error) {
+	if useGitHubAPI() {
+		var release struct {
+			HTMLURL string `json:"html_url"`
+		}
+		payload := map[string]string{"tag_name": version, "name": version, "body": notes}
+		if err := githubRequest("POST", "/repos/"+repo+"/releases", payload, &release); err != nil {
+			return "", fmt.Errorf("creating release failed: %w", err)
+		}
+		return release.HTMLURL, nil
+	}
+	cmd := exec.Command("gh", "release", "create", version, "--repo", repo, "--title", version, "--notes-file", "-", "--verify-tag")
+	cmd.Stdin = strings.NewReader(notes)
+	output, err := runCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("gh release create failed: %w\n%s", err, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Release actions, behind the choices on the release screens
+const (
+	releaseActionPublish    = "publish"
+	releaseActionTag        = "tag"
+	releaseActionEdit       = "edit"
+	releaseActionRegenerate = "regenerate"
+	releaseActionPrint      = "print"
+	releaseActionOffline    = "offline"
+	releaseActionQuit       = "quit"
+)
+
+// releaseModel is the terminal UI of gitcat release
+type releaseModel struct {
+	ctx      context.Context // Cancelled when the UI exits, aborting a request still running
+	phase    string          // "generating", "confirm", "error", "publishing", "done", "failed"
+	version  string
+	previous string // Last tag, "" if the repository has none
+	commits  []releaseCommit
+	repo     string // GitHub repository to publish on, "" if none
+	remote   string // Remote the tag is pushed to
+	notes    string
+	choices  []string
+	actions  []string // Action behind each choice
+	cursor   int
+	errMsg   string
+	summary  string
+	print    bool // Print the notes once the UI exits
+	exitCode int
+	width    int
+}
+
+// enterConfirmPhase shows the notes with what can be done with them
+func (m releaseModel) enterConfirmPhase() releaseModel {
+	m.phase = "confirm"
+	m.cursor = 0
+	m.choices, m.actions = nil, nil
+	if m.repo != "" {
+		m.choices = append(m.choices, tr("Create tag %s and a GitHub release on %s", m.version, m.repo))
+		m.actions = append(m.actions, releaseActionPublish)
+	}
+	m.choices = append(m.choices, tr("Create tag %s only", m.version), tr("Edit in $EDITOR"), tr("Regenerate"), tr("Print the notes and exit"))
+	m.actions = append(m.actions, releaseActionTag, releaseActionEdit, releaseActionRegenerate, releaseActionPrint)
+	return m
+}
+
+// enterErrorPhase shows a generation error with ways to carry on
+func (m releaseModel) enterErrorPhase(msg string) releaseModel {
+	m.phase = "error"
+	m.cursor = 0
+	m.errMsg = msg
+	m.choices = []string{tr("Retry"), tr("Use the commit subjects instead"), tr("Quit")}
+	m.actions = []string{releaseActionRegenerate, releaseActionOffline, releaseActionQuit}
+	return m
+}
+
+// publish tags HEAD and, for publishAction, pushes the tag and creates the
+// GitHub release
+func (m releaseModel) publish(action string) tea.Cmd {
+	version, notes, remote, repo := m.version, m.notes, m.remote, m.repo
+	return func() tea.Msg {
+		if err := createReleaseTag(version, notes); err != nil {
+			return releaseFinishedMsg{err: err, code: exitGitFailure}
+		}
+		if action != releaseActionPublish {
+			return releaseFinishedMsg{summary: tr("✓ Tagged %s", version)}
+		}
+		if err := pushReleaseTag(remote, version, *noVerifyFlag); err != nil {
+			return releaseFinishedMsg{err: fmt.Errorf("%w\nThe tag was created locally; push it with git push %s %s", err, remote, version), code: exitGitFailure}
+		}
+		url, err := createGitHubRelease(repo, version, notes)
+		if err != nil {
+			return releaseFinishedMsg{err: err, code: exitGHFailure}
+		}
+		return releaseFinishedMsg{summary: tr("✓ Tagged %s, pushed it to %s, and published the release: %s", version, remote, url)}
+	}
+}
+
+func (m releaseModel) Init() tea.Cmd {
+	if m.phase == "generating" {
+		return generateReleaseNotes(m.ctx, m.version, m.previous, m.commits)
+	}
+	return nil
+}
+
+func (m releaseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case releaseNotesMsg:
+		m.notes = string(msg)
+		return m.enterConfirmPhase(), nil
+
+	case releaseNotesErrMsg:
+		return m.enterErrorPhase(string(msg)), nil
+
+	case editorFinishedMsg:
+		if msg.target != editTargetRelease {
+			return m, nil
+		}
+		m.errMsg = ""
+		if msg.err != nil {
+			m.errMsg = msg.err.Error()
+		} else if msg.content != "" {
+			m.notes = msg.content
+		}
+		return m, nil
+
+	case releaseFinishedMsg:
+		if msg.err != nil {
+			m.phase = "failed"
+			m.errMsg = msg.err.Error()
+			m.exitCode = msg.code
+			return m, tea.Quit
+		}
+		m.phase = "done"
+		m.summary = msg.summary
+		m.exitCode = exitOK
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			if m.phase != "publishing" {
+				m.exitCode = exitUserAborted
+				return m, tea.Quit
+			}
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.choices)-1 {
+				m.cursor++
+			}
+		case "enter":
+			if m.phase != "confirm" && m.phase != "error" {
+				return m, nil
+			}
+			switch action := m.actions[m.cursor]; action {
+			case releaseActionPublish, releaseActionTag:
+				m.phase = "publishing"
+				return m, m.publish(action)
+			case releaseActionEdit:
+				return m, openEditor(editTargetRelease, m.notes)
+			case releaseActionRegenerate:
+				m.phase = "generating"
+				m.errMsg = ""
+				return m, generateReleaseNotes(m.ctx, m.version, m.previous, m.commits)
+			case releaseActionPrint:
+				m.print = true
+				m.phase = "done"
+				m.exitCode = exitOK
+				return m, tea.Quit
+			case releaseActionOffline:
+				m.notes = offlineReleaseNotes(m.commits)
+				m.errMsg = ""
+				return m.enterConfirmPhase(), nil
+			case releaseActionQuit:
+				m.exitCode = exitAPIFailure
+				return m, tea.Quit
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m releaseModel) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	wrap := lipgloss.NewStyle()
+	if m.width > 0 {
+		wrap = wrap.Width(m.width)
+	}
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	choicesView := func() string {
+		s := ""
+		for i, choice := range m.choices {
+			cursor := " "
+			if m.cursor == i {
+				cursor = ">"
+				choice = selectedStyle.Render(choice)
+			}
+			s += fmt.Sprintf("%s %s\n", cursor, choice)
+		}
+		return s + "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
+	}
+
+	switch m.phase {
+	case "generating":
+		return titleStyle.Render(tr("Generating release notes for %s...", m.version)) + "\n"
+
+	case "error":
+		s := titleStyle.Render(tr("⚠️  API Error")) + "\n\n"
+		s += errorStyle.Render(tr("Failed to generate release notes:")) + "\n"
+		s += wrap.Foreground(lipgloss.Color("8")).Render(m.errMsg) + "\n\n"
+		return s + choicesView()
+
+	case "confirm":
+		s := titleStyle.Render(tr("Release notes for %s", m.version)) + "\n"
+		if m.previous != "" {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("%d commits since %s", len(m.commits), m.previous)) + "\n"
+		}
+		s += "\n" + wrap.Foreground(lipgloss.Color("14")).Render(m.notes) + "\n\n"
+		if m.errMsg != "" {
+			s += errorStyle.Render(m.errMsg) + "\n\n"
+		}
+		return s + choicesView()
+
+	case "publishing":
+		return titleStyle.Render(tr("Creating tag %s...", m.version)) + "\n"
+
+	case "done":
+		if m.summary == "" {
+			return ""
+		}
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render(m.summary) + "\n"
+
+	case "failed":
+		return errorStyle.Render(tr("Error: %s", m.errMsg)) + "\n"
+	}
+	return ""
+}
+
+// runRelease handles gitcat release [<version>]: it generates notes for the
+// commits since the last tag and offers to tag HEAD with them and publish a
+// GitHub release
+func runRelease(args []string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat release [OPTIONS] [<version>]")
+		os.Exit(exitError)
+	}
+	previous := lastTag()
+	commits, err := releaseCommits(previous)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	if len(commits) == 0 {
+		fmt.Fprintf(os.Stderr, "No commits since %s to release.\n", previous)
+		os.Exit(exitNothingToCommit)
+	}
+
+	version := nextVersion(previous, commits)
+	if len(args) == 1 {
+		version = args[0]
+	}
+	if version == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s isn't a semantic version, so the next one can't be worked out; name it: gitcat release <version>\n", previous)
+		os.Exit(exitError)
+	}
+	if _, err := runCommand(exec.Command("git", "check-ref-format", "refs/tags/"+version)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %q is not a valid tag name\n", version)
+		os.Exit(exitError)
+	}
+	if _, err := runCommand(exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/tags/"+version)); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: tag %s already exists\n", version)
+		os.Exit(exitError)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := releaseModel{
+		ctx:      ctx,
+		phase:    "generating",
+		version:  version,
+		previous: previous,
+		commits:  commits,
+		remote:   baseRemote(),
+		repo:     baseGitHubRepo(),
+	}
+	if *offlineFlag {
+		m.notes = offlineReleaseNotes(commits)
+		m = m.enterConfirmPhase()
+	}
+
+	p := tea.NewProgram(m)
+	finalModel, err := p.Run()
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(exitError)
+	}
+	m = finalModel.(releaseModel)
+	if m.print {
+		fmt.Println(m.notes)
+	}
+	os.Exit(m.exitCode)
+}
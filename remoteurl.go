@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// githubHosts are the host names GitHub remotes use; ssh.github.com serves
+// ssh over port 443
+var githubHosts = []string{"github.com", "www.github.com", "ssh.github.com"}
+
+// remoteHostPath splits a remote URL into its host name and repository
+// path, for URLs (https://host/o/n.git, ssh://git@host:22/o/n) and
+// scp-like addresses (git@host:o/n.git). Hosts of ssh remotes are resolved
+// through ~/.ssh/config, so an alias such as work:o/n yields the host it
+// stands for. Local paths and file:// URLs have no host.
+//
+// remote should come from remoteURL, which has already applied git's
+// url.<base>.insteadOf rewrites.
+func remoteHostPath(remote string) (host, path string) {
+	remote = strings.TrimSpace(remote)
+	ssh := false
+	if scheme, _, ok := strings.Cut(remote, "://"); ok {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", ""
+		}
+		switch strings.ToLower(scheme) {
+		case "ssh", "git+ssh", "ssh+git":
+			ssh = true
+		case "https", "http", "git":
+		default:
+			return "", "" // file:// and remote helpers
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// As in git, a colon before any slash makes an scp-like address;
+		// anything else is a local path, as is a Windows drive letter. The
+		// colons of an IPv6 address are skipped by bracketing it, as in
+		// git@[::1]:o/n.
+		sep := ":"
+		if at := strings.Index(remote, "@"); strings.HasPrefix(remote[at+1:], "[") {
+			sep = "]:"
+		}
+		address, p, ok := strings.Cut(remote, sep)
+		if !ok || strings.Contains(address, "/") || len(address) == 1 {
+			return "", ""
+		}
+		host, path, ssh = address[strings.LastIndex(address, "@")+1:], p, true
+		host = strings.TrimPrefix(host, "[")
+	}
+	if host == "" {
+		return "", ""
+	}
+	if ssh {
+		host = sshHostName(host)
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return strings.ToLower(host), path
+}
+
+// sshHostName returns the host ssh connects to for alias, following Host
+// and HostName entries in the ssh config. It returns alias itself when ssh
+// isn't available, or when alias starts with a hyphen, which ssh would take
+// for an option.
+func sshHostName(alias string) string {
+	if strings.HasPrefix(alias, "-") {
+		return alias
+	}
+	output, err := runCommand(exec.Command("ssh", "-G", "--", alias))
+	if err != nil {
+		debugf("ssh -G %s: %v", alias, err)
+		return alias
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if name, ok := strings.CutPrefix(line, "hostname "); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return alias
+}
+
+// githubRepo returns "owner/name" for a GitHub remote URL, or "" for others
+func githubRepo(remote string) string {
+	host, path := remoteHostPath(remote)
+	if !slices.Contains(githubHosts, host) || strings.Count(path, "/") != 1 {
+		return ""
+	}
+	return path
+}
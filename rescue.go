@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// branchUpstream returns the remote-tracking branch that branch follows,
+// falling back to <remote>/<branch> on the base remote (usually
+// origin/<branch>), or "" when there is neither
+func branchUpstream(branch string) string {
+	output, err := runCommand(exec.Command("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{upstream}"))
+	if err == nil {
+		return strings.TrimSpace(string(output))
+	}
+	fallback := baseRemote() + "/" + branch
+	if _, err := runCommand(exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/remotes/"+fallback)); err == nil {
+		return fallback
+	}
+	return ""
+}
+
+// unpushedCommitCount counts the commits on HEAD that upstream doesn't have
+func unpushedCommitCount(upstream string) int {
+	output, err := runCommand(exec.Command("git", "rev-list", "--count", upstream+"..HEAD"))
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return n
+}
+
+// moveCommitsToBranch rescues commits made on a protected branch by mistake:
+// it creates name at HEAD and switches to it, then points from back at
+// upstream. HEAD doesn't move, so the index and working tree are untouched.
+func moveCommitsToBranch(name, from, upstream string) tea.Cmd {
+	return func() tea.Msg {
+		if output, err := runCommand(exec.Command("git", "checkout", "-b", name)); err != nil {
+			return errMsg(fmt.Sprintf("Failed to create branch: %v\n%s", err, string(output)))
+		}
+		if output, err := runCommand(exec.Command("git", "branch", "--force", from, upstream)); err != nil {
+			return errMsg(fmt.Sprintf("Created branch %s with your commits, but failed to reset %s to %s: %v\n%s", name, from, upstream, err, string(output)))
+		}
+		return branchCreatedMsg(name)
+	}
+}
+
+// enterBranchWarningPhase asks whether to leave the protected branch. When
+// it has commits that aren't on its upstream, it also offers to move them
+// to the new branch.
+func (m model) enterBranchWarningPhase() model {
+	m.phase = "branch_warning"
+	m.cursor = 0
+	m.choices = []string{tr("Yes, create a new branch")}
+	if m.unpushedCommits > 0 {
+		m.choices = append(m.choices, tr("Yes, and move my %d unpushed commits to it (reset %s to %s)", m.unpushedCommits, m.currentBranch, m.protectedUpstream))
+	}
+	m.choices = append(m.choices, tr("No, continue on %s", m.currentBranch))
+	return m
+}
+
+// createBranch creates the branch entered by the user, moving the
+// protected branch's unpushed commits along if that was chosen
+func (m model) createBranch() (model, tea.Cmd) {
+	m.phase = "branch_creating"
+	if m.moveCommits {
+		return m, moveCommitsToBranch(m.branchInput, m.currentBranch, m.protectedUpstream)
+	}
+	return m, createAndCheckoutBranch(m.branchInput)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// revertType is the conventional commit type of a revert, whether or not the
+// configured types list it
+var revertType = CommitType{Name: "revert", Description: "Reverts a previous commit"}
+
+// gitRevertNoCommit stages the changes that undo sha without committing
+// them. A revert that doesn't apply cleanly is aborted.
+func gitRevertNoCommit(sha string) error {
+	cmd := exec.Command("git", "revert", "--no-commit", sha)
+	if output, err := runCommand(cmd); err != nil {
+		gitRevertAbort()
+		return fmt.Errorf("git revert failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// gitRevertAbort drops a revert in progress, keeping unrelated local changes
+func gitRevertAbort() error {
+	cmd := exec.Command("git", "revert", "--abort")
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git revert --abort failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// revertMessage puts a generated message in git's revert form: the reverted
+// commit's subject after "revert: ", and a "This reverts commit <sha>." line
+// closing the body
+func revertMessage(message string, target *rewordTarget) string {
+	subject, _, _ := strings.Cut(target.message, "\n")
+	_, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	body = strings.TrimSpace(body)
+
+	reference := fmt.Sprintf("This reverts commit %s.", target.sha)
+	if !strings.Contains(body, reference) {
+		if body != "" {
+			body += "\n\n"
+		}
+		body += reference
+	}
+	return "revert: " + subject + "\n\n" + body
+}
+
+// revertView shows the commit being reverted
+func (m model) revertView() string {
+	if m.revert == nil {
+		return ""
+	}
+	subject, _, _ := strings.Cut(m.revert.message, "\n")
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	s := lipgloss.NewStyle().Bold(true).Render(tr("Reverting:")) + "\n\n"
+	s += m.wrap(dimStyle, m.revert.sha[:7]+" "+subject) + "\n\n"
+	return s
+}
+
+// restoreRevert undoes the staged revert if its commit was never made
+func (m model) restoreRevert() error {
+	if m.revert == nil || m.didCommit {
+		return nil
+	}
+	return gitRevertAbort()
+}
+
+// runRevert reverts a commit, asking for the reason and generating a message
+// that explains it
+func runRevert(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat revert [OPTIONS] <commit>")
+		os.Exit(exitError)
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	if countStagedFiles() > 0 {
+		fmt.Fprintln(os.Stderr, "Error: commit or unstage your staged changes before reverting")
+		os.Exit(exitError)
+	}
+	target, err := loadRewordTarget(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot revert %s: %v\n", args[0], err)
+		os.Exit(exitGitFailure)
+	}
+	if err := gitRevertNoCommit(target.sha); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	diff, err := worktree.StagedDiff()
+	if err != nil {
+		gitRevertAbort()
+		fmt.Fprintf(os.Stderr, "Error getting git diff: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+
+	m := initialModel(diff, false, branch, false, false)
+	m.savedDraft = nil
+	m.revert = target
+	m.typeSelected = 0
+	m.scopeInput = ""
+	m.phase = "intent"
+
+	p := tea.NewProgram(m, programOptions()...)
+	finalModel, err := p.Run()
+	if err != nil {
+		gitRevertAbort()
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(exitError)
+	}
+	exitWith(finalModel.(model))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/burritocatai/gitcat/generate"
+)
+
+type reviewMsg string
+type reviewErrMsg string // API error during the review
+
+// startReview sets the commit flow's first screen aside and asks the PR
+// model to review the staged diff
+func (m model) startReview() model {
+	m.reviewPhase, m.reviewChoices, m.reviewCursor = m.phase, m.choices, m.cursor
+	m.phase = "review_generating"
+	return m
+}
+
+// requestReview asks the PR model for the review, showing its progress
+func (m model) requestReview() (model, tea.Cmd) {
+	m.phase = "review_generating"
+	m, ctx, tick := m.beginRequest(getEffectiveConfig().GetPRModel())
+	return m, tea.Batch(tick, m.generateReview(ctx))
+}
+
+// generateReview sends the staged diff, filtered as for the commit prompt,
+// with a code-review prompt
+func (m model) generateReview(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		config := getEffectiveConfig()
+		config.Model = config.GetPRModel()
+		diff, ok := fitDiffToBudget(m.promptDiff(), diffTokenBudget(config, config.GetReviewParams().MaxTokens), config.MaxDiffLines)
+		if !ok {
+			return reviewErrMsg("The staged changes are too large to review in one request.")
+		}
+
+		prompt := fmt.Sprintf(`You are reviewing a change before it is committed. Based on the following staged git diff, point out problems a careful reviewer would raise.
+
+%s Report only problems you can see there, without speculating about code that isn't shown, and don't praise or summarize the change.
+
+Git diff:
+%s
+%s
+Report in Markdown under these headings, leaving out headings with nothing to report:
+## Potential bugs
+Logic errors, unhandled errors or edge cases, races, leaks, and security problems.
+## Missing tests
+Changed behavior that no test in the diff covers.
+## Style
+Naming, duplication, dead code, and inconsistencies with the surrounding code.
+
+Write one bullet per finding, naming the file and, where possible, the function or line, and keep each to one or two sentences. Report at most 10 findings, the most important first. If there is nothing worth raising, respond with exactly: No issues found.
+
+Respond with ONLY the review, with no preamble or code fences around it.`, groundingRule("diff"), diff, generate.GlossaryPrompt(config.Glossary))
+
+		msg := generateMsg(ctx, config, prompt, config.GetReviewParams(), true)
+		switch msg := msg.(type) {
+		case prContentMsg:
+			return reviewMsg(msg)
+		case prContentErrMsg:
+			return reviewErrMsg(msg)
+		}
+		return msg
+	}
+}
+
+// continueAfterReview returns to the screen the commit flow started on
+func (m model) continueAfterReview() model {
+	m.phase, m.choices, m.cursor = m.reviewPhase, m.reviewChoices, m.reviewCursor
+	return m
+}
+
+// reviewView shows the review, or why it failed, with the way on
+func (m model) reviewView() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+
+	var s string
+	if m.phase == "review_error" {
+		s = titleStyle.Render(tr("⚠️  API Error")) + "\n\n"
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(tr("Failed to review the staged changes:")) + "\n"
+		s += m.wrap(lipgloss.NewStyle().Foreground(lipgloss.Color("8")), m.apiErrorMsg) + "\n\n"
+	} else {
+		s = titleStyle.Render(tr("Review of the staged changes")) + "\n\n"
+		s += renderMarkdown(m.reviewText, m.width) + "\n\n"
+	}
+	for i, choice := range m.choices {
+		cursor := " "
+		if m.cursor == i {
+			cursor = ">"
+			choice = selectedStyle.Render(choice)
+		}
+		s += fmt.Sprintf("%s %s\n", cursor, choice)
+	}
+	return s + "\n" + tr("(use arrow keys to select, enter to confirm, q to quit)") + "\n"
+}
+
+// runReview reviews the staged changes, then offers to carry on into the
+// usual commit flow
+func runReview(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat review [OPTIONS]")
+		os.Exit(exitError)
+	}
+	if *offlineFlag {
+		fmt.Fprintln(os.Stderr, "Error: gitcat review needs an AI provider and can't run with --offline")
+		os.Exit(exitError)
+	}
+	diff, err := worktree.StagedDiff()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting git diff: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	if diff == "" {
+		fmt.Println("Nothing staged to review. Stage changes with git add first.")
+		os.Exit(exitNothingToCommit)
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+
+	m := initialModel(diff, false, branch, branch == "main" || branch == "master", false)
+	// The review sends the diff too, so it gets the same secret scan as
+	// generation; the commit flow asks again before sending anything
+	if getEffectiveConfig().SecretScan != secretScanOff {
+		if findings := scanDiffForSecrets(diff, m.promptAutoExclude); len(findings) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: the staged changes look like they contain secrets, so they were not sent for review:")
+			for _, f := range findings {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", f.path, f.rule)
+			}
+			os.Exit(exitError)
+		}
+	}
+	m = m.startReview()
+
+	p := tea.NewProgram(m, programOptions()...)
+	finalModel, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(exitError)
+	}
+	exitWith(finalModel.(model))
+}
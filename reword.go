@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rewordTarget is an existing commit whose message is being regenerated
+type rewordTarget struct {
+	sha      string
+	message  string
+	diff     string
+	trailers []string // Trailers on the old message, kept on the new one
+	pushed   bool     // Already on a remote-tracking branch
+}
+
+// loadRewordTarget reads rev's message, diff, and trailers. Merge commits
+// are refused since their changes have no single diff to describe.
+func loadRewordTarget(rev string) (*rewordTarget, error) {
+	output, err := runCommand(exec.Command("git", "rev-list", "--parents", "-n", "1", rev))
+	if err != nil {
+		return nil, fmt.Errorf("no commit %s", rev)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) > 2 {
+		return nil, fmt.Errorf("%s is a merge commit", rev)
+	}
+	target, err := loadCommitInfo(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	target.diff, err = getCommitDiff(target.sha)
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// loadCommitInfo reads a commit's message and trailers and whether it has
+// been pushed, leaving the diff empty
+func loadCommitInfo(sha string) (*rewordTarget, error) {
+	target := &rewordTarget{sha: sha}
+	output, err := runCommand(exec.Command("git", "log", "-1", "--format=%B", sha))
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	target.message = strings.TrimSpace(string(output))
+
+	output, err = runCommand(exec.Command("git", "log", "-1", "--format=%(trailers:only,unfold)", sha))
+	if err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				target.trailers = append(target.trailers, line)
+			}
+		}
+	}
+
+	output, err = runCommand(exec.Command("git", "branch", "-r", "--contains", sha))
+	target.pushed = err == nil && strings.TrimSpace(string(output)) != ""
+	return target, nil
+}
+
+// getCommitDiff returns the changes a commit made, as a patch
+func getCommitDiff(rev string) (string, error) {
+	cmd := exec.Command("git", "show", "--format=", "--patch", rev)
+	output, err := runCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("git show failed: %w", err)
+	}
+	return normalizeNewlines(string(output)), nil
+}
+
+// getCommitDiffStat returns the --stat summary of a commit's changes
+func getCommitDiffStat(rev string) (string, error) {
+	cmd := exec.Command("git", "show", "--format=", "--stat", rev)
+	output, err := runCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("git show --stat failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// rewordEdit is a new message approved for one commit of a batch reword
+type rewordEdit struct {
+	target  *rewordTarget
+	message string
+	opts    commitOptions
+}
+
+// initialRewordModel starts the usual type, scope, and generation flow
+// for an existing commit, preselecting the type and scope it already has.
+// Confirming amends the commit with --only, so staged changes stay out.
+func initialRewordModel(target *rewordTarget, branch string) model {
+	m := initialModel(target.diff, false, branch, false, false)
+	m.savedDraft = nil
+	m.verifyCommand = "" // The commit exists; there is nothing to check before it
+	m = m.useRewordTarget(target)
+	return m.enterTypePhase()
+}
+
+// initialBatchRewordModel goes through targets oldest first, generating
+// each message with the type and scope the commit already has (the AI
+// picks one where it has none). Nothing is rewritten until every message
+// has been approved or kept.
+func initialBatchRewordModel(targets []*rewordTarget, base, branch string) model {
+	m := initialModel("", false, branch, false, false)
+	m.savedDraft = nil
+	m.verifyCommand = ""
+	m.rewordQueue = targets
+	m.rewordBase = base
+	m = m.useRewordTarget(targets[0])
+	m.phase = "generating"
+	return m
+}
+
+// useRewordTarget switches the model to rewording target
+func (m model) useRewordTarget(target *rewordTarget) model {
+	m.reword = target
+	m.diff = target.diff
+	m.generatedMsg = ""
+	m.secretsAcknowledged = false
+	m.commitOpts = currentCommitOptions()
+	m.commitOpts.Amend = true
+	m.commitOpts.Trailers = append(m.commitOpts.Trailers, target.trailers...)
+	if m.changeID != "" {
+		m.changeID = newChangeID()
+	}
+	for _, trailer := range target.trailers {
+		// Keep Gerrit's Change-Id so the review follows the commit
+		if id, ok := strings.CutPrefix(trailer, "Change-Id: "); ok {
+			m.changeID = id
+		}
+	}
+
+	subject, _, _ := strings.Cut(target.message, "\n")
+	m.typeSelected = 0
+	if m.rewordQueue != nil {
+		m.typeSelected = len(m.commitTypes)
+	}
+	if i := slices.IndexFunc(m.commitTypes, func(t CommitType) bool { return t.Name == messageType(subject) }); i >= 0 {
+		m.typeSelected = i
+	}
+	m.scopeInput = messageScope(subject)
+	return m
+}
+
+// acceptReword records the confirmed message for a batch (an empty message
+// keeps the commit's own) and moves on to the next commit, rewriting the
+// branch once the last one is decided
+func (m model) acceptReword(message string) (model, tea.Cmd) {
+	if message != "" {
+		m.rewordEdits = append(m.rewordEdits, rewordEdit{target: m.reword, message: message, opts: m.commitOptions()})
+	}
+	m.rewordIndex++
+	if m.rewordIndex < len(m.rewordQueue) {
+		m = m.useRewordTarget(m.rewordQueue[m.rewordIndex])
+		return m.startGeneration()
+	}
+	if len(m.rewordEdits) > 0 {
+		if err := applyRewords(m.rewordBase, m.rewordEdits); err != nil {
+			m.errorMsg = fmt.Sprintf("Error rewording commits: %v", err)
+			m.exitCode = exitGitFailure
+			return m, tea.Quit
+		}
+		m.didCommit = true
+	}
+	m.phase = "done"
+	return m, tea.Quit
+}
+
+// applyRewords rewrites the branch with an interactive rebase onto base
+// whose todo list amends each edited commit's message right after picking it
+func applyRewords(base string, edits []rewordEdit) error {
+	dir, err := os.MkdirTemp("", "gitcat-reword-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	messages := make(map[string]string, len(edits))
+	var todo strings.Builder
+	for i, edit := range edits {
+		messageFile := filepath.Join(dir, fmt.Sprintf("message-%d", i))
+		if err := os.WriteFile(messageFile, []byte(edit.message), 0600); err != nil {
+			return err
+		}
+		args := append([]string{"git", "commit", "--allow-empty", "-F", messageFile}, edit.opts.Args()...)
+		for j := range args {
+			args[j] = shellQuote(args[j])
+		}
+		messages[edit.target.sha] = "exec " + strings.Join(args, " ") + "\n"
+	}
+
+	output, err := runCommand(exec.Command("git", "rev-list", "--reverse", base+"..HEAD"))
+	if err != nil {
+		return fmt.Errorf("git rev-list failed: %w", err)
+	}
+	for _, sha := range strings.Fields(string(output)) {
+		todo.WriteString("pick " + sha + "\n")
+		todo.WriteString(messages[sha])
+	}
+	todoFile := filepath.Join(dir, "todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0600); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "rebase", "--interactive", "--autostash", base)
+	// Replace git's todo list with ours and keep any editor from opening.
+	// The path uses forward slashes, which git's sh on Windows also takes.
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(filepath.ToSlash(todoFile)), "GIT_EDITOR=true")
+	if output, err := runCommand(cmd); err != nil {
+		if _, abortErr := runCommand(exec.Command("git", "rebase", "--abort")); abortErr != nil {
+			debugf("git rebase --abort failed: %v", abortErr)
+		}
+		return fmt.Errorf("git rebase failed: %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// shellQuote quotes s for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// loadRewordRange resolves a range such as "main..", "HEAD~3..HEAD", or
+// "HEAD~3" (up to HEAD) to its commits, oldest first, and the base to
+// rebase onto
+func loadRewordRange(spec string) ([]*rewordTarget, string, error) {
+	from, to, isRange := strings.Cut(spec, "..")
+	if isRange && to != "" {
+		toSHA, err := runCommand(exec.Command("git", "rev-parse", "--verify", to+"^{commit}"))
+		if err != nil {
+			return nil, "", fmt.Errorf("unknown revision %s", to)
+		}
+		headSHA, err := runCommand(exec.Command("git", "rev-parse", "--verify", "HEAD"))
+		if err != nil || strings.TrimSpace(string(toSHA)) != strings.TrimSpace(string(headSHA)) {
+			return nil, "", fmt.Errorf("the range must end at HEAD")
+		}
+	}
+	output, err := runCommand(exec.Command("git", "merge-base", from, "HEAD"))
+	if err != nil {
+		return nil, "", fmt.Errorf("no common ancestor of %s and HEAD", from)
+	}
+	base := strings.TrimSpace(string(output))
+
+	output, err = runCommand(exec.Command("git", "rev-list", "--reverse", base+"..HEAD"))
+	if err != nil {
+		return nil, "", fmt.Errorf("git rev-list failed: %w", err)
+	}
+	var targets []*rewordTarget
+	for _, sha := range strings.Fields(string(output)) {
+		target, err := loadRewordTarget(sha)
+		if err != nil {
+			return nil, "", err
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		return nil, "", fmt.Errorf("no commits in %s", spec)
+	}
+	return targets, base, nil
+}
+
+// runReword regenerates HEAD's message and amends it, or with a range,
+// rewords each commit in it
+func runReword(args []string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat reword [OPTIONS] [<range>]")
+		os.Exit(exitError)
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+
+	var m model
+	if len(args) > 0 {
+		targets, base, err := loadRewordRange(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot reword %s: %v\n", args[0], err)
+			os.Exit(exitGitFailure)
+		}
+		m = initialBatchRewordModel(targets, base, branch)
+	} else {
+		target, err := loadRewordTarget("HEAD")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot reword: %v\n", err)
+			os.Exit(exitGitFailure)
+		}
+		m = initialRewordModel(target, branch)
+	}
+
+	p := tea.NewProgram(m, programOptions()...)
+	finalModel, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(exitError)
+	}
+	exitWith(finalModel.(model))
+}
+
+// rewordView shows the message being replaced on the confirm screen, and
+// warns when the commit has already been pushed
+func (m model) rewordView() string {
+	if m.reword == nil {
+		return ""
+	}
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	var s string
+	if m.rewordQueue != nil {
+		s = dimStyle.Render(tr("Commit %d of %d: %s", m.rewordIndex+1, len(m.rewordQueue), m.reword.sha[:7])) + "\n\n"
+	}
+	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	if m.squashBase != "" {
+		s += lipgloss.NewStyle().Bold(true).Render(tr("Squashing %d commits:", m.squashCount)) + "\n\n"
+		s += m.wrap(dimStyle, m.reword.message) + "\n\n"
+		if m.reword.pushed {
+			s += warningStyle.Render(tr("⚠️  These commits are already pushed; squashing them rewrites published history")) + "\n\n"
+		}
+		return s
+	}
+	s += lipgloss.NewStyle().Bold(true).Render(tr("Current message:")) + "\n\n"
+	s += m.wrap(dimStyle, m.reword.message) + "\n\n"
+	if m.reword.pushed {
+		s += warningStyle.Render(tr("⚠️  This commit is already pushed; rewording it rewrites published history")) + "\n\n"
+	}
+	return s
+}
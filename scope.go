@@ -0,0 +1,191 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxScopeSuggestions = 5
+	scopeHistoryCommits = 200 // Recent commits searched for scopes used on the same paths
+)
+
+// emptyScope matches the "()" of a subject written with an empty scope,
+// such as "feat(): add login"
+var emptyScope = regexp.MustCompile(`^([a-zA-Z]+)\(\s*\)(!?): `)
+
+// monorepoDirs hold one package per subdirectory (packages/foo, apps/web)
+var monorepoDirs = map[string]bool{
+	"packages": true, "apps": true, "libs": true, "services": true, "modules": true, "crates": true,
+}
+
+// packageManifests mark a directory as its own package or module
+var packageManifests = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml"}
+
+// genericDirs are layout directories that say nothing about what changed,
+// so scopes come from the directory below them
+var genericDirs = map[string]bool{
+	"src": true, "lib": true, "pkg": true, "internal": true, "cmd": true,
+	"app": true, "apps": true, "packages": true, "modules": true, "source": true,
+}
+
+// skipScopeEnabled reports whether the scope input is skipped because the
+// repository writes bare "type: description" subjects, from the gitcat
+// config or the repository's gitcat.skipScope git config
+func skipScopeEnabled() bool {
+	return getEffectiveConfig().SkipScope || gitConfigBool("gitcat.skipScope")
+}
+
+// dropEmptyScope turns "feat(): add login" into "feat: add login"
+func dropEmptyScope(message string) string {
+	return emptyScope.ReplaceAllString(message, "$1$2: ")
+}
+
+// suggestScopes proposes scopes for a change: scopes recent commits used on
+// the same paths first, then names derived from the changed directories,
+// each ordered by how many files they cover
+func suggestScopes(paths []string) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	add := func(candidates []string) {
+		for _, s := range candidates {
+			if s != "" && !seen[s] && len(scopes) < maxScopeSuggestions {
+				seen[s] = true
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	add(historyScopes(paths))
+
+	counts := make(map[string]int)
+	for _, p := range paths {
+		counts[pathScope(p)]++
+	}
+	add(rankByCount(counts))
+	return scopes
+}
+
+// historyScopes returns the scopes of recent conventional commits that
+// touched any of paths, most used first
+func historyScopes(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	args := []string{"log", "--format=%s", "-n", strconv.Itoa(scopeHistoryCommits), "--"}
+	for _, p := range paths {
+		args = append(args, ":(top,literal)"+p) // Diff paths are relative to the repo root
+	}
+	output, err := runCommand(exec.Command("git", args...))
+	if err != nil {
+		return nil // New repo or paths without history
+	}
+	counts := make(map[string]int)
+	for _, subject := range strings.Split(string(output), "\n") {
+		if scope := messageScope(subject); scope != "" {
+			counts[scope]++
+		}
+	}
+	return rankByCount(counts)
+}
+
+// pathScope derives a scope from a path: the first directory below any
+// generic layout directories, or the file name for top-level files
+func pathScope(p string) string {
+	dirs := strings.Split(path.Dir(p), "/")
+	for _, dir := range dirs {
+		if dir != "." && !genericDirs[dir] && !strings.HasPrefix(dir, ".") {
+			return strings.ToLower(dir)
+		}
+	}
+	if len(dirs) > 1 || dirs[0] != "." {
+		// Only generic or hidden directories (e.g. .github): use the last one
+		return strings.ToLower(strings.TrimPrefix(dirs[len(dirs)-1], "."))
+	}
+	base := path.Base(p)
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSuffix(base, path.Ext(base)), "."))
+}
+
+// messageScope extracts the scope from a conventional commit subject such
+// as "fix(api): ...", or "" if it has none
+func messageScope(subject string) string {
+	prefix, _, ok := strings.Cut(subject, ":")
+	if !ok {
+		return ""
+	}
+	open := strings.Index(prefix, "(")
+	end := strings.LastIndex(prefix, ")")
+	if open < 0 || end < open || strings.ContainsAny(prefix[:open], " \t") {
+		return ""
+	}
+	return strings.TrimSpace(prefix[open+1 : end])
+}
+
+// rankByCount returns the keys ordered by descending count, then name
+func rankByCount(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// packageRoot returns the monorepo package containing the repo-relative
+// path p: the deepest ancestor directory below the root that has a package
+// manifest, or a direct child of a directory like packages/ or apps/.
+// It returns "" for files outside any package.
+func packageRoot(repoRoot, p string) string {
+	dirs := strings.Split(path.Dir(p), "/")
+	if dirs[0] == "." {
+		return ""
+	}
+	for i := len(dirs); i > 0; i-- {
+		dir := strings.Join(dirs[:i], "/")
+		for _, manifest := range packageManifests {
+			if _, err := os.Stat(filepath.Join(repoRoot, filepath.FromSlash(dir), manifest)); err == nil {
+				return dir
+			}
+		}
+	}
+	for i := 0; i+1 < len(dirs); i++ {
+		if monorepoDirs[dirs[i]] {
+			return strings.Join(dirs[:i+2], "/")
+		}
+	}
+	return ""
+}
+
+// changedPackages returns the distinct monorepo packages the paths fall in,
+// and whether every path is inside one of them
+func changedPackages(paths []string) (packages []string, allInPackages bool) {
+	repoRoot, err := getRepoRoot()
+	if err != nil {
+		return nil, false
+	}
+	seen := make(map[string]bool)
+	allInPackages = len(paths) > 0
+	for _, p := range paths {
+		pkg := packageRoot(repoRoot, p)
+		if pkg == "" {
+			allInPackages = false
+			continue
+		}
+		if !seen[pkg] {
+			seen[pkg] = true
+			packages = append(packages, pkg)
+		}
+	}
+	sort.Strings(packages)
+	return packages, allInPackages
+}
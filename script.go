@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+)
+
+// maxScriptSteps caps how many prompts are answered in a row, in case an
+// answer leads back to a prompt already answered
+const maxScriptSteps = 20
+
+// scriptStepMsg starts answering the prompts of a scripted run, since Init
+// can't answer the first one itself
+type scriptStepMsg struct{}
+
+// scripted reports whether flags answer some of the prompts of this run
+func scripted() bool {
+	return *yesFlag || *addAllFlag || *pushFlag || *createPRFlag || *branchFlag != ""
+}
+
+// scriptAnswer selects the answer the flags give to the prompt on screen,
+// and reports whether they give one:
+//   - --branch names the new branch, --add-all stages every changed file
+//   - --push and --create-pr push the branch, and then open the PR
+//   - --yes accepts the defaults: a new branch off main or master, the type
+//     the AI chooses, the prefilled scope, the generated message, and no
+//     push or PR unless asked for. A saved draft is discarded so that the
+//     run starts over, and unstaged changes are left in place.
+func (m model) scriptAnswer() (model, bool) {
+	switch m.phase {
+	case "branch_warning":
+		if !*yesFlag {
+			return m, false
+		}
+		m.cursor = 0
+	case "branch_input":
+		if *branchFlag == "" && !*yesFlag {
+			return m, false
+		}
+	case "add":
+		if !*addAllFlag {
+			return m, false
+		}
+		for i := range m.files {
+			m.selected[i] = struct{}{}
+		}
+	case "resume_draft", "stash_prompt":
+		if !*yesFlag {
+			return m, false
+		}
+		m.cursor = 1
+	case "type":
+		if !*yesFlag {
+			return m, false
+		}
+		m.typeSelected = len(m.commitTypes)
+	case "scope", "intent", "review", "candidates", "confirm":
+		if !*yesFlag {
+			return m, false
+		}
+		m.cursor = 0
+	case "loop_prompt":
+		if !*yesFlag {
+			return m, false
+		}
+		m.cursor = len(m.choices) - 1
+	case "push_prompt", "upstream_prompt":
+		if *pushFlag || *createPRFlag {
+			m.cursor = 0
+		} else if *yesFlag {
+			// Skip pushing
+			m.cursor = len(m.choices) - 1
+		} else {
+			return m, false
+		}
+	case "pr_prompt":
+		if *createPRFlag {
+			m.cursor = 0
+		} else if *yesFlag {
+			// Skip the PR
+			m.cursor = 1
+		} else {
+			return m, false
+		}
+	case "pr_confirm":
+		if !*createPRFlag && !*yesFlag {
+			return m, false
+		}
+		m.cursor = 0
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// prompting reports whether the screen waits for the user to answer
+func (m model) prompting() bool {
+	switch m.phase {
+	case "branch_warning", "branch_input", "branch_conflict", "add", "unstage", "exclude", "coauthors",
+		"resume_draft", "type", "scope", "intent", "stash_prompt", "secrets_warning", "review", "review_error",
+		"candidates", "confirm", "edit", "manual_input", "commit_error", "hook_failed", "verify_failed",
+		"split_plan", "loop_prompt", "push_prompt", "upstream_prompt", "pr_prompt", "pr_base", "pr_confirm",
+		"pr_error", "pr_manual_title", "pr_manual_body":
+		return true
+	}
+	return false
+}
+
+// runScript answers the prompts the flags decide, one after another, until
+// the run waits on a request or reaches a prompt left to the user. With
+// --yes nobody is there to answer it, so the run stops with an error.
+func (m model) runScript(cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	if !scripted() {
+		return m, cmd
+	}
+	cmds := []tea.Cmd{cmd}
+	for range maxScriptSteps {
+		if m.errorMsg != "" {
+			break
+		}
+		answered, ok := m.scriptAnswer()
+		if !ok {
+			break
+		}
+		debugf("script: answering %s", m.phase)
+		next, cmd := answered.update(tea.KeyMsg{Type: tea.KeyEnter})
+		cmds = append(cmds, cmd)
+		if next.(model).phase == m.phase {
+			m = next.(model)
+			break
+		}
+		m = next.(model)
+	}
+	if *yesFlag && m.errorMsg == "" && m.prompting() {
+		m.errorMsg = m.unansweredPrompt()
+		m.exitCode = m.quitExitCode()
+		return m, tea.Quit
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// unansweredPrompt explains why a run with --yes stopped at a prompt: the
+// failure the prompt offers to recover from, or else the prompt itself
+func (m model) unansweredPrompt() string {
+	switch {
+	case m.apiErrorMsg != "":
+		return m.apiErrorMsg
+	case m.phase == "add":
+		return "nothing is staged; stage the changes to commit first, or pass --add-all"
+	case m.phase == "hook_failed":
+		return fmt.Sprintf("the commit hook failed (the message is kept as a draft):\n%s", m.hookOutput)
+	case m.phase == "verify_failed":
+		return fmt.Sprintf("the verify command failed:\n%s", m.verifyOutput)
+	}
+	return fmt.Sprintf("no flag answers the %s prompt; run without --yes to answer it", strings.ReplaceAll(m.phase, "_", " "))
+}
+
+// programOptions lets a run with --yes go without a terminal to read keys
+// from, as in CI or a git hook
+func programOptions() []tea.ProgramOption {
+	if *yesFlag && !term.IsTerminal(os.Stdin.Fd()) {
+		return []tea.ProgramOption{tea.WithInput(nil)}
+	}
+	return nil
+}
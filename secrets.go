@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Secret scan modes (Config.SecretScan)
+const (
+	secretScanWarn  = "warn"  // Show findings and let the user decide (default)
+	secretScanBlock = "block" // Refuse to send or commit when secrets are found
+	secretScanOff   = "off"   // Skip scanning
+)
+
+// secretRule is a named pattern for a well-known credential format
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+var secretRules = []secretRule{
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+	{"Anthropic API key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_-]{32,}`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Stripe secret key", regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Private key", regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`)},
+}
+
+// secretAssignment matches key = "value" style assignments whose value is
+// then checked for entropy to avoid flagging placeholders
+var secretAssignment = regexp.MustCompile(`(?i)(api[_-]?key|secret|token|passw(or)?d|credential)[A-Za-z0-9_-]*["']?\s*[:=]\s*["']?([A-Za-z0-9+/=_\-.]{16,})`)
+
+// highEntropyToken matches long base64/hex-like runs worth an entropy check
+var highEntropyToken = regexp.MustCompile(`[A-Za-z0-9+/=_-]{32,}`)
+
+// secretFinding is a suspected secret on an added line of the diff
+type secretFinding struct {
+	path string
+	rule string
+	line string // The offending line with the secret masked
+}
+
+// scanDiffForSecrets checks the lines a diff adds for known credential
+// formats and high-entropy strings. Files matched by noEntropy (lockfiles
+// full of checksums) only get the known-format checks.
+func scanDiffForSecrets(diff string, noEntropy ignoreMatcher) []secretFinding {
+	var findings []secretFinding
+	for _, f := range splitDiff(diff) {
+		entropyChecks := !noEntropy.match(f.path)
+		for _, line := range strings.Split(f.hunks, "\n") {
+			if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
+				continue
+			}
+			added := line[1:]
+			if rule, match := detectSecret(added, entropyChecks); rule != "" {
+				findings = append(findings, secretFinding{
+					path: f.path,
+					rule: rule,
+					line: strings.TrimSpace(strings.Replace(added, match, maskSecret(match), 1)),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// detectSecret returns the rule name and matched text for the first secret
+// found in line, or empty strings if none
+func detectSecret(line string, entropyChecks bool) (string, string) {
+	for _, rule := range secretRules {
+		if match := rule.pattern.FindString(line); match != "" {
+			return rule.name, match
+		}
+	}
+	if !entropyChecks {
+		return "", ""
+	}
+	if m := secretAssignment.FindStringSubmatch(line); m != nil {
+		if value := m[3]; shannonEntropy(value) >= 3.5 {
+			return "High-entropy credential assignment", value
+		}
+	}
+	for _, token := range highEntropyToken.FindAllString(line, -1) {
+		if shannonEntropy(token) >= 4.5 {
+			return "High-entropy string", token
+		}
+	}
+	return "", ""
+}
+
+// shannonEntropy returns the bits of entropy per character in s
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// maskSecret keeps the first four characters so findings stay recognizable
+func maskSecret(s string) string {
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + strings.Repeat("*", min(len(s)-4, 16))
+}
+
+// redactedText replaces redaction matches that don't set their own replacement
+const redactedText = "[REDACTED]"
+
+// redaction is a compiled RedactRule
+type redaction struct {
+	pattern *regexp.Regexp
+	replace string
+}
+
+// compileRedactions compiles the configured redaction rules
+func compileRedactions(rules []RedactRule) ([]redaction, error) {
+	var compiled []redaction
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", rule.Pattern, err)
+		}
+		replace := rule.Replace
+		if replace == "" {
+			replace = redactedText
+		}
+		compiled = append(compiled, redaction{pattern: re, replace: replace})
+	}
+	return compiled, nil
+}
+
+// applyRedactions masks every redaction match in text
+func applyRedactions(text string, redactions []redaction) string {
+	for _, r := range redactions {
+		text = r.pattern.ReplaceAllString(text, r.replace)
+	}
+	return text
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// splitGroup is one commit of a split plan, as proposed by the model
+type splitGroup struct {
+	Type    string   `json:"type"`
+	Scope   string   `json:"scope"`
+	Summary string   `json:"summary"`
+	Files   []string `json:"files"` // Paths, or path#n for a file's nth hunk
+
+	changes []changedFile // The staged entries for whole files, renames included
+	hunks   []splitHunk   // Single hunks of files split between groups
+}
+
+// splitHunk is one hunk of a staged file, for plans that put a file's
+// changes in different commits
+type splitHunk struct {
+	id     string // path#n, as the plan names it
+	n      int    // Position in the file, from 1
+	file   changedFile
+	header string // The file's diff header, which a patch of the hunk needs
+	text   string // The hunk, from its @@ line
+}
+
+// summary returns the hunk's @@ line, e.g. "@@ -10,7 +10,8 @@ func refresh"
+func (h splitHunk) summary() string {
+	line, _, _ := strings.Cut(h.text, "\n")
+	return strings.TrimSpace(line)
+}
+
+// label names the group as the plan shows it, e.g. "feat(api): add retries"
+func (g splitGroup) label() string {
+	if g.Summary == "" {
+		return tr("Files the plan left out")
+	}
+	prefix := g.Type
+	if g.Scope != "" {
+		prefix += "(" + g.Scope + ")"
+	}
+	if prefix == "" {
+		return g.Summary
+	}
+	return prefix + ": " + g.Summary
+}
+
+type splitPlanMsg []splitGroup
+type splitPlanErrMsg string // API or parse error while planning a split
+
+// splitPrompt asks the model to group the staged files, or the hunks of
+// files with several, into commits. diff may be empty, in which case the
+// plan is made from the paths alone.
+func splitPrompt(files []changedFile, hunks map[string][]splitHunk, diff string, types []CommitType) string {
+	var typeList, fileList []string
+	for _, t := range types {
+		typeList = append(typeList, fmt.Sprintf("- %s: %s", t.Name, t.Description))
+	}
+	for _, f := range files {
+		fileList = append(fileList, f.Status+" "+f.Path)
+		for _, h := range hunks[f.Path] {
+			fileList = append(fileList, fmt.Sprintf("    %s: %s", h.id, h.summary()))
+		}
+	}
+	if diff == "" {
+		diff = "(not available, group the files by their paths)"
+	}
+
+	return fmt.Sprintf(`You are helping split staged changes into small, logical commits. Group the files below so that each group is one self-contained change (a feature, a fix, a refactor, documentation, ...) that could be reviewed on its own. Keep files that depend on each other in the same group. Use as few groups as make sense; a single group is fine if everything belongs together.
+
+Commit types:
+%s
+
+Staged files:
+%s
+
+Git diff:
+%s
+
+Respond with ONLY a JSON array, no explanations or markdown code blocks, in this form:
+[{"type": "feat", "scope": "api", "summary": "add token refresh", "files": ["api/auth.go", "api/client.go"]}]
+
+Every staged file must appear in exactly one group. When a file listed with its hunks mixes changes that belong to different groups, list its hunks (e.g. "api/client.go#2") in those groups instead of the file; each hunk must then appear in exactly one group. The summary is a short imperative description of the group's change.`, strings.Join(typeList, "\n"), strings.Join(fileList, "\n"), diff)
+}
+
+// stagedHunks returns the hunks of each staged file that has several, so
+// that a plan can put them in different commits. New, deleted, renamed, and
+// binary files are only placed whole. The diff is read as is, without the
+// CRLF normalization of the prompt diff, so that it still applies.
+func stagedHunks(files []changedFile) (map[string][]splitHunk, error) {
+	cmd := exec.Command("git", "diff", "--staged", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/")
+	output, err := runCommand(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	modified := make(map[string]changedFile)
+	for _, f := range files {
+		if f.Status == "M" {
+			modified[f.Path] = f
+		}
+	}
+	hunks := make(map[string][]splitHunk)
+	for _, fd := range splitDiff(string(output)) {
+		file, ok := modified[fd.path]
+		if !ok {
+			continue
+		}
+		texts := hunkTexts(fd.hunks)
+		if len(texts) < 2 {
+			continue
+		}
+		for i, text := range texts {
+			hunks[fd.path] = append(hunks[fd.path], splitHunk{
+				id:     fmt.Sprintf("%s#%d", fd.path, i+1),
+				n:      i + 1,
+				file:   file,
+				header: fd.header,
+				text:   text,
+			})
+		}
+	}
+	return hunks, nil
+}
+
+// hunkTexts splits a file's hunks at their @@ lines
+func hunkTexts(hunks string) []string {
+	var texts []string
+	for _, line := range strings.SplitAfter(hunks, "\n") {
+		if strings.HasPrefix(line, "@@") || len(texts) == 0 {
+			texts = append(texts, "")
+		}
+		texts[len(texts)-1] += line
+	}
+	if len(texts) == 1 && texts[0] == "" {
+		return nil
+	}
+	return texts
+}
+
+// generateSplitPlan asks the commit model for a split plan
+func generateSplitPlan(ctx context.Context, files []changedFile, hunks map[string][]splitHunk, diff string, types []CommitType) tea.Cmd {
+	return func() tea.Msg {
+		config := getEffectiveConfig()
+		config.Model = config.GetCommitModel()
+
+		prompt := splitPrompt(files, hunks, diff, types)
+
+		result := generateMsg(ctx, config, prompt, config.GetSplitParams(), false)
+		response, ok := result.(commitMsgMsg)
+		if !ok {
+			return splitPlanErrMsg(fmt.Sprint(result))
+		}
+		groups, err := parseSplitPlan(string(response), files, hunks)
+		if err != nil {
+			return splitPlanErrMsg(err.Error())
+		}
+		return splitPlanMsg(groups)
+	}
+}
+
+// parseSplitPlan reads the model's JSON plan. Paths and hunks that aren't
+// staged or were already placed are dropped, as are hunks of a file placed
+// whole, and the files and hunks the model left out are collected in a
+// final group so nothing is lost.
+func parseSplitPlan(response string, files []changedFile, hunks map[string][]splitHunk) ([]splitGroup, error) {
+	start, end := strings.Index(response, "["), strings.LastIndex(response, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("the model did not return a plan")
+	}
+	var proposed []splitGroup
+	if err := json.Unmarshal([]byte(response[start:end+1]), &proposed); err != nil {
+		return nil, fmt.Errorf("failed to parse the plan: %w", err)
+	}
+
+	staged := make(map[string]changedFile, len(files))
+	for _, f := range files {
+		staged[f.Path] = f
+	}
+	byID := make(map[string]splitHunk)
+	for _, fileHunks := range hunks {
+		for _, h := range fileHunks {
+			byID[h.id] = h
+		}
+	}
+	// placed holds whole files and hunk IDs; split holds files whose hunks
+	// are placed one by one
+	placed := make(map[string]bool, len(files))
+	split := make(map[string]bool)
+	var groups []splitGroup
+	for _, g := range proposed {
+		var kept []string
+		g.changes, g.hunks = nil, nil
+		for _, p := range g.Files {
+			if f, ok := staged[p]; ok && !placed[p] && !split[p] {
+				placed[p] = true
+				kept = append(kept, p)
+				g.changes = append(g.changes, f)
+			} else if h, ok := byID[p]; ok && !placed[p] && !placed[h.file.Path] {
+				placed[p], split[h.file.Path] = true, true
+				g.hunks = append(g.hunks, h)
+			}
+		}
+		g.hunks = sortHunks(g.hunks)
+		for _, h := range g.hunks {
+			kept = append(kept, h.id)
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		g.Files = kept
+		g.Summary = strings.TrimSpace(g.Summary)
+		if g.Summary == "" {
+			g.Summary = "update " + strings.Join(kept, ", ")
+		}
+		groups = append(groups, g)
+	}
+
+	var leftover splitGroup
+	for _, f := range files {
+		switch {
+		case split[f.Path]:
+			for _, h := range hunks[f.Path] {
+				if !placed[h.id] {
+					leftover.Files = append(leftover.Files, h.id)
+					leftover.hunks = append(leftover.hunks, h)
+				}
+			}
+		case !placed[f.Path]:
+			leftover.Files = append(leftover.Files, f.Path)
+			leftover.changes = append(leftover.changes, f)
+		}
+	}
+	if len(leftover.Files) > 0 {
+		groups = append(groups, leftover)
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("the plan contained none of the staged files")
+	}
+	return groups, nil
+}
+
+// sortHunks orders hunks by file and then position, so that each file's
+// hunks make one patch
+func sortHunks(hunks []splitHunk) []splitHunk {
+	slices.SortStableFunc(hunks, func(a, b splitHunk) int {
+		if c := strings.Compare(a.file.Path, b.file.Path); c != 0 {
+			return c
+		}
+		return a.n - b.n
+	})
+	return hunks
+}
+
+// writeStagedTree saves the index as a tree object so the staged content,
+// partial staging included, can be restaged group by group
+func writeStagedTree() (string, error) {
+	cmd := exec.Command("git", "write-tree")
+	output, err := runCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("git write-tree failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// gitResetIndex unstages everything, keeping working tree changes
+func gitResetIndex() error {
+	cmd := exec.Command("git", "reset", "-q")
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git reset failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// stageFromTree stages files with their content from tree, leaving the
+// working tree alone
+func stageFromTree(tree string, files []changedFile) error {
+	args := []string{"restore", "--staged", "--source=" + tree, "--"}
+	for _, f := range files {
+		args = append(args, f.Path)
+		if f.OrigPath != "" {
+			args = append(args, f.OrigPath)
+		}
+	}
+	cmd := exec.Command("git", args...)
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git restore --staged failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// stageHunks stages single hunks by applying them to the index, leaving
+// the working tree alone. Hunks left out of a file only shift the lines of
+// later ones, which git apply finds by their context.
+func stageHunks(hunks []splitHunk) error {
+	if len(hunks) == 0 {
+		return nil
+	}
+	cmd := exec.Command("git", "apply", "--cached", "--whitespace=nowarn")
+	cmd.Stdin = strings.NewReader(hunkPatch(hunks))
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git apply --cached failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// hunkPatch builds a patch of hunks sorted by file, with each file's diff
+// header once
+func hunkPatch(hunks []splitHunk) string {
+	var patch strings.Builder
+	for i, h := range hunks {
+		if i == 0 || hunks[i-1].file.Path != h.file.Path {
+			patch.WriteString(h.header)
+		}
+		patch.WriteString(h.text)
+	}
+	return patch.String()
+}
+
+// startSplitPlan asks the model to group the staged files and hunks. When
+// the secret scan finds something, only the paths are sent.
+func (m model) startSplitPlan() (model, tea.Cmd) {
+	files, err := worktree.StagedFiles()
+	if err == nil {
+		m.splitHunks, err = stagedHunks(files)
+	}
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Error listing staged files: %v", err)
+		m.exitCode = exitGitFailure
+		return m, tea.Quit
+	}
+	diff, note, ok := m.generationDiff()
+	if !ok {
+		diff, note = "", tr("The diff is too large; planning from file names only")
+	}
+	// The scan covers what is sent: the redacted, budgeted diff
+	if scanMode := getEffectiveConfig().SecretScan; scanMode != secretScanOff && len(scanDiffForSecrets(diff, m.promptAutoExclude)) > 0 {
+		diff, note = "", tr("Possible secrets in the diff; planning from file names only")
+	}
+	m.phase = "split_planning"
+	m.promptNote = note
+	m.apiErrorMsg = ""
+	m, ctx, tick := m.beginRequest(getEffectiveConfig().GetCommitModel())
+	return m, tea.Batch(tick, generateSplitPlan(ctx, files, m.splitHunks, diff, m.commitTypes))
+}
+
+// enterSplitPlanPhase shows the proposed groups for approval
+func (m model) enterSplitPlanPhase(groups []splitGroup) model {
+	m.phase = "split_plan"
+	m.cursor = 0
+	m.splitGroups = groups
+	m.choices = []string{tr("Commit in %d commits", len(groups)), tr("Regenerate plan"), tr("Cancel")}
+	return m
+}
+
+// startSplit saves the staged content, unstages everything, and starts on
+// the first group
+func (m model) startSplit() (model, tea.Cmd) {
+	tree, err := writeStagedTree()
+	if err == nil {
+		err = gitResetIndex()
+	}
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Error preparing split: %v", err)
+		m.exitCode = exitGitFailure
+		return m, tea.Quit
+	}
+	m.splitTree = tree
+	m.splitIndex = 0
+	// A breaking change belongs to one commit, not every group
+	m.breaking = false
+	return m.stageSplitGroup()
+}
+
+// stageSplitGroup stages the current group and generates its message with
+// the plan's type and scope
+func (m model) stageSplitGroup() (model, tea.Cmd) {
+	group := m.splitGroups[m.splitIndex]
+	err := stageFromTree(m.splitTree, group.changes)
+	if err == nil {
+		err = stageHunks(group.hunks)
+	}
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Error staging files: %v", err)
+		m.exitCode = exitGitFailure
+		return m, tea.Quit
+	}
+	diff, err := worktree.StagedDiff()
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Error getting diff: %v", err)
+		m.exitCode = exitGitFailure
+		return m, tea.Quit
+	}
+	m.diff = diff
+	m.generatedMsg = ""
+	m.typeSelected = len(m.commitTypes)
+	if i := slices.IndexFunc(m.commitTypes, func(t CommitType) bool { return t.Name == group.Type }); i >= 0 {
+		m.typeSelected = i
+	}
+	m.scopeInput = group.Scope
+	return m.startGeneration()
+}
+
+// splitPending reports whether split groups remain after the current one
+func (m model) splitPending() bool {
+	return m.splitTree != "" && m.splitIndex < len(m.splitGroups)-1
+}
+
+// restoreSplit restages the groups not yet committed, so quitting partway
+// through a split leaves them staged as they were. A file split by hunks is
+// restaged whole: its committed hunks are already in HEAD, so the staged
+// file differs from it by the rest.
+func (m model) restoreSplit() error {
+	if m.splitTree == "" {
+		return nil
+	}
+	var files []changedFile
+	restaged := make(map[string]bool)
+	for _, g := range m.splitGroups[m.splitIndex:] {
+		files = append(files, g.changes...)
+		for _, h := range g.hunks {
+			if !restaged[h.file.Path] {
+				restaged[h.file.Path] = true
+				files = append(files, h.file)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return stageFromTree(m.splitTree, files)
+}
+
+// splitProgress shows which group of a split is being committed
+func (m model) splitProgress() string {
+	if m.splitTree == "" {
+		return ""
+	}
+	group := m.splitGroups[m.splitIndex]
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(tr("Commit %d of %d: %s", m.splitIndex+1, len(m.splitGroups), group.label())) + "\n\n"
+}
+
+// entries lists the group's files and hunks as the plan shows them
+func (g splitGroup) entries() []string {
+	entries := make([]string, 0, len(g.Files))
+	for _, f := range g.Files {
+		i := slices.IndexFunc(g.hunks, func(h splitHunk) bool { return h.id == f })
+		if i >= 0 {
+			f += "  " + g.hunks[i].summary()
+		}
+		entries = append(entries, f)
+	}
+	return entries
+}
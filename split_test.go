@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// splitFixture returns staged files where a.go has three hunks
+func splitFixture() ([]changedFile, map[string][]splitHunk) {
+	files := []changedFile{
+		{Status: "M", Path: "a.go"},
+		{Status: "M", Path: "b.go"},
+		{Status: "A", Path: "c.go"},
+	}
+	hunks := make(map[string][]splitHunk)
+	for n := 1; n <= 3; n++ {
+		hunks["a.go"] = append(hunks["a.go"], splitHunk{
+			id:     fmt.Sprintf("a.go#%d", n),
+			n:      n,
+			file:   files[0],
+			header: "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n",
+			text:   fmt.Sprintf("@@ -%d0,1 +%d0,1 @@\n-old\n+new\n", n, n),
+		})
+	}
+	return files, hunks
+}
+
+func TestParseSplitPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string // label = files, for each group
+	}{
+		{
+			"whole files",
+			`[{"type": "feat", "scope": "api", "summary": "add retries", "files": ["a.go", "b.go"]}, {"type": "docs", "summary": "document it", "files": ["c.go"]}]`,
+			[]string{"feat(api): add retries = a.go, b.go", "docs: document it = c.go"},
+		},
+		{
+			"surrounding text",
+			"Here is the plan:\n```json\n[{\"type\": \"fix\", \"summary\": \"all of it\", \"files\": [\"a.go\", \"b.go\", \"c.go\"]}]\n```",
+			[]string{"fix: all of it = a.go, b.go, c.go"},
+		},
+		{
+			"duplicate and unknown paths",
+			`[{"type": "fix", "summary": "one", "files": ["b.go", "b.go", "missing.go"]}, {"type": "feat", "summary": "two", "files": ["b.go", "a.go", "c.go"]}]`,
+			[]string{"fix: one = b.go", "feat: two = a.go, c.go"},
+		},
+		{
+			"group left empty is dropped",
+			`[{"type": "fix", "summary": "one", "files": ["a.go", "b.go", "c.go"]}, {"type": "feat", "summary": "two", "files": ["a.go", "missing.go"]}]`,
+			[]string{"fix: one = a.go, b.go, c.go"},
+		},
+		{
+			"hunks of a file placed whole",
+			`[{"type": "fix", "summary": "one", "files": ["a.go"]}, {"type": "feat", "summary": "two", "files": ["a.go#2", "b.go", "c.go"]}]`,
+			[]string{"fix: one = a.go", "feat: two = b.go, c.go"},
+		},
+		{
+			"file placed whole after its hunks",
+			`[{"type": "fix", "summary": "one", "files": ["a.go#2"]}, {"type": "feat", "summary": "two", "files": ["a.go", "b.go", "c.go"]}]`,
+			[]string{"fix: one = a.go#2", "feat: two = b.go, c.go", "Files the plan left out = a.go#1, a.go#3"},
+		},
+		{
+			"hunks sorted by position",
+			`[{"type": "fix", "summary": "one", "files": ["a.go#3", "b.go", "a.go#1"]}, {"type": "feat", "summary": "two", "files": ["a.go#2", "c.go"]}]`,
+			[]string{"fix: one = b.go, a.go#1, a.go#3", "feat: two = c.go, a.go#2"},
+		},
+		{
+			"leftovers",
+			`[{"type": "docs", "summary": "docs", "files": ["c.go"]}]`,
+			[]string{"docs: docs = c.go", "Files the plan left out = a.go, b.go"},
+		},
+		{
+			"missing summary",
+			`[{"type": "chore", "summary": " ", "files": ["a.go", "b.go", "c.go"]}]`,
+			[]string{"chore: update a.go, b.go, c.go = a.go, b.go, c.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, hunks := splitFixture()
+			groups, err := parseSplitPlan(tt.response, files, hunks)
+			if err != nil {
+				t.Fatalf("parseSplitPlan() error = %v", err)
+			}
+			var got []string
+			for _, g := range groups {
+				got = append(got, g.label()+" = "+strings.Join(g.Files, ", "))
+				// Every entry is staged either whole or as a hunk
+				if len(g.changes)+len(g.hunks) != len(g.Files) {
+					t.Errorf("group %q has %d changes and %d hunks for %d entries", g.label(), len(g.changes), len(g.hunks), len(g.Files))
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseSplitPlan() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseSplitPlanErrors(t *testing.T) {
+	files, hunks := splitFixture()
+	tests := []struct {
+		name     string
+		response string
+		files    []changedFile
+	}{
+		{"no JSON", "I can't split these changes.", files},
+		{"invalid JSON", `[{"files": ["a.go"]`, files},
+		// Otherwise the files left out make a group of their own
+		{"nothing staged", `[{"type": "fix", "summary": "one", "files": ["missing.go"]}]`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if groups, err := parseSplitPlan(tt.response, tt.files, hunks); err == nil {
+				t.Errorf("parseSplitPlan() = %d groups, want an error", len(groups))
+			}
+		})
+	}
+}
+
+func TestHunkTexts(t *testing.T) {
+	tests := []struct {
+		name  string
+		hunks string
+		want  []string
+	}{
+		{"none", "", nil},
+		{"one", "@@ -1 +1 @@\n-a\n+b\n", []string{"@@ -1 +1 @@\n-a\n+b\n"}},
+		{
+			"two",
+			"@@ -1 +1 @@\n-a\n+b\n@@ -9 +9 @@ func f\n-c\n+d\n",
+			[]string{"@@ -1 +1 @@\n-a\n+b\n", "@@ -9 +9 @@ func f\n-c\n+d\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hunkTexts(tt.hunks); !slices.Equal(got, tt.want) {
+				t.Errorf("hunkTexts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunkPatch(t *testing.T) {
+	_, hunks := splitFixture()
+	other := splitHunk{
+		id:     "b.go#1",
+		n:      1,
+		file:   changedFile{Status: "M", Path: "b.go"},
+		header: "diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n",
+		text:   "@@ -5,1 +5,1 @@\n-x\n+y\n",
+	}
+	patch := hunkPatch(sortHunks([]splitHunk{hunks["a.go"][2], other, hunks["a.go"][0]}))
+
+	want := hunks["a.go"][0].header + hunks["a.go"][0].text + hunks["a.go"][2].text + other.header + other.text
+	if patch != want {
+		t.Errorf("hunkPatch() =\n%s\nwant\n%s", patch, want)
+	}
+}
+
+func TestStageHunks(t *testing.T) {
+	git := gitRepo(t)
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	write := func() {
+		if err := os.WriteFile("a.txt", []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write()
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "initial")
+	for _, i := range []int{1, 15, 28} {
+		lines[i] = "changed " + lines[i]
+	}
+	write()
+	git("add", "a.txt")
+
+	files, err := worktree.StagedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hunks, err := stagedHunks(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hunks["a.txt"]) != 3 {
+		t.Fatalf("stagedHunks() = %d hunks of a.txt, want 3", len(hunks["a.txt"]))
+	}
+	if err := gitResetIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the middle hunk, the last one still applies by its context
+	if err := stageHunks([]splitHunk{hunks["a.txt"][0], hunks["a.txt"][2]}); err != nil {
+		t.Fatalf("stageHunks() error = %v", err)
+	}
+	staged := git("diff", "--staged")
+	for _, want := range []string{"+changed line 2", "+changed line 29"} {
+		if !strings.Contains(staged, want) {
+			t.Errorf("staged diff lacks %q:\n%s", want, staged)
+		}
+	}
+	if strings.Contains(staged, "changed line 16") {
+		t.Errorf("the hunk left out was staged:\n%s", staged)
+	}
+	if unstaged := git("diff"); !strings.Contains(unstaged, "+changed line 16") {
+		t.Errorf("the working tree lost the hunk left out:\n%s", unstaged)
+	}
+}
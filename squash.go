@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/gitcat/gitops"
+)
+
+// loadSquashTarget describes the commits between base's merge base and
+// HEAD as one change: the combined diff, the old messages oldest first, and
+// their trailers (only the first Change-Id is kept). It returns the merge
+// base and the number of commits.
+func loadSquashTarget(base string) (*rewordTarget, string, int, error) {
+	output, err := runCommand(exec.Command("git", "merge-base", base, "HEAD"))
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("no common ancestor of %s and HEAD", base)
+	}
+	mergeBase := strings.TrimSpace(string(output))
+
+	count := branchCommitCount(mergeBase)
+	if count < 2 {
+		return nil, "", 0, fmt.Errorf("there is nothing to squash: %d commit(s) since %s", count, base)
+	}
+	output, err = runCommand(exec.Command("git", "rev-parse", "HEAD"))
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	target := &rewordTarget{sha: strings.TrimSpace(string(output))}
+
+	// Merge commits only bring in changes the combined diff already covers
+	output, err = runCommand(exec.Command("git", "rev-list", "--reverse", "--no-merges", mergeBase+"..HEAD"))
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("git rev-list failed: %w", err)
+	}
+	var messages []string
+	hasChangeID := false
+	for _, sha := range strings.Fields(string(output)) {
+		commit, err := loadCommitInfo(sha)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		messages = append(messages, commit.message)
+		target.pushed = target.pushed || commit.pushed
+		for _, trailer := range commit.trailers {
+			if strings.HasPrefix(trailer, "Change-Id: ") {
+				if hasChangeID {
+					continue
+				}
+				hasChangeID = true
+			}
+			if !slices.Contains(target.trailers, trailer) {
+				target.trailers = append(target.trailers, trailer)
+			}
+		}
+	}
+	target.message = strings.Join(messages, "\n\n")
+
+	output, err = runCommand(exec.Command("git", "diff", mergeBase, "HEAD"))
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("git diff failed: %w", err)
+	}
+	target.diff = normalizeNewlines(string(output))
+	return target, mergeBase, count, nil
+}
+
+// getRangeDiffStat returns the --stat summary of the changes from base to head
+func getRangeDiffStat(base, head string) (string, error) {
+	cmd := exec.Command("git", "diff", "--stat", base, head)
+	output, err := runCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("git diff --stat failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// gitResetSoft moves the branch to rev, keeping every change staged
+func gitResetSoft(rev string) error {
+	cmd := exec.Command("git", "reset", "--soft", rev)
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git reset --soft failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// gitPushForce pushes a rewritten branch, refusing to overwrite commits
+// pushed by someone else since the last fetch
+func gitPushForce(branch string, noVerify bool) error {
+	return worktree.Push(pushRemote(branch), branch, gitops.PushOptions{ForceWithLease: true, SetUpstream: true, NoVerify: noVerify})
+}
+
+// branchCommitCount counts the commits on HEAD that aren't on base
+func branchCommitCount(base string) int {
+	output, err := runCommand(exec.Command("git", "rev-list", "--count", base+"..HEAD"))
+	if err != nil {
+		return 0
+	}
+	n := 0
+	fmt.Sscan(strings.TrimSpace(string(output)), &n)
+	return n
+}
+
+// startSquash moves to the usual type, scope, and generation flow for the
+// squashed commit, or to an error if the branch can't be squashed. With
+// thenPR the squashed branch is force-pushed and a PR generated from it.
+func (m model) startSquash(base string, thenPR bool) (model, tea.Cmd) {
+	if m = m.useSquashTarget(base, thenPR); m.errorMsg != "" {
+		return m, tea.Quit
+	}
+	return m.continueToGeneration()
+}
+
+// useSquashTarget switches the model to squashing the branch onto base and
+// enters the type phase, leaving generation to be started by the caller,
+// or by Init when the flags skip straight to it. It sets errorMsg if the
+// branch can't be squashed.
+func (m model) useSquashTarget(base string, thenPR bool) model {
+	if countStagedFiles() > 0 {
+		m.errorMsg = "Commit or unstage your staged changes before squashing"
+		m.exitCode = exitError
+		return m
+	}
+	target, mergeBase, count, err := loadSquashTarget(base)
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Cannot squash: %v", err)
+		m.exitCode = exitGitFailure
+		return m
+	}
+	m.verifyCommand = ""
+	m = m.useRewordTarget(target)
+	m.commitOpts.Amend = false
+	m.squashBase = mergeBase
+	m.squashCount = count
+	m.squashPR = thenPR
+	return m.enterTypePhase()
+}
+
+// finishSquash continues after the squashed commit is made: force-pushing
+// and generating the PR when squashing for one, otherwise exiting
+func (m model) finishSquash() (tea.Model, tea.Cmd) {
+	if !m.squashPR {
+		m.phase = "done"
+		return m, tea.Quit
+	}
+	if err := gitPushForce(m.currentBranch, m.commitOpts.NoVerify); err != nil {
+		m.errorMsg = fmt.Sprintf("Error pushing: %v", err)
+		m.exitCode = exitGitFailure
+		return m, tea.Quit
+	}
+	m.didPush = true
+	return m.startPRGeneration()
+}
+
+// restoreSquash puts the branch back where it was if it was reset for a
+// squash whose commit was never made
+func (m model) restoreSquash() error {
+	if !m.squashReset {
+		return nil
+	}
+	return gitResetSoft(m.reword.sha)
+}
+
+// runSquash squashes the branch's commits since base (by default the
+// remote's default branch) into one with a generated message
+func runSquash(args []string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat squash [OPTIONS] [<base>]")
+		os.Exit(exitError)
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current branch: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	base := defaultBaseRef()
+	if len(args) > 0 {
+		base = args[0]
+	}
+
+	m := initialModel("", false, branch, false, false)
+	m.savedDraft = nil
+	m = m.useSquashTarget(base, false)
+	if m.errorMsg != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", m.errorMsg)
+		os.Exit(m.exitCode)
+	}
+	p := tea.NewProgram(m, programOptions()...)
+	finalModel, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(exitError)
+	}
+	exitWith(finalModel.(model))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/burritocatai/gitcat/generate"
+)
+
+// standupCommit is one commit going into the standup summary
+type standupCommit struct {
+	sha     string
+	branch  string // Branch the commit was found through
+	message string
+}
+
+// standupAuthor resolves --author: "me" is the user's git user.email
+func standupAuthor(author string) (string, error) {
+	if author != "me" {
+		return author, nil
+	}
+	output, err := runCommand(exec.Command("git", "config", "user.email"))
+	email := strings.TrimSpace(string(output))
+	if err != nil || email == "" {
+		return "", fmt.Errorf("git user.email isn't set; pass --author")
+	}
+	return email, nil
+}
+
+// standupCommits lists author's commits since the given date on all local
+// and remote-tracking branches, oldest first. A commit on several branches
+// appears once, under the first branch git reaches it through.
+func standupCommits(since, author string) ([]standupCommit, error) {
+	output, err := runCommand(exec.Command("git", "log", "--branches", "--remotes", "--source", "--no-merges", "--reverse",
+		"--since="+since, "--author="+author, "--format=%h%x00%S%x00%B%x1e"))
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w\n%s", err, string(output))
+	}
+	var commits []standupCommit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		branch := strings.TrimPrefix(strings.TrimPrefix(fields[1], "refs/heads/"), "refs/remotes/")
+		commits = append(commits, standupCommit{sha: fields[0], branch: branch, message: strings.TrimSpace(fields[2])})
+	}
+	return commits, nil
+}
+
+// standupByBranch groups commits by branch, in the order the branches
+// first appear
+func standupByBranch(commits []standupCommit) (branches []string, byBranch map[string][]standupCommit) {
+	byBranch = make(map[string][]standupCommit)
+	for _, c := range commits {
+		if _, ok := byBranch[c.branch]; !ok {
+			branches = append(branches, c.branch)
+		}
+		byBranch[c.branch] = append(byBranch[c.branch], c)
+	}
+	return branches, byBranch
+}
+
+// offlineStandup lists the commit subjects under their branches, for use
+// without an AI provider
+func offlineStandup(commits []standupCommit) string {
+	var b strings.Builder
+	branches, byBranch := standupByBranch(commits)
+	for _, branch := range branches {
+		fmt.Fprintf(&b, "%s:\n", branch)
+		for _, c := range byBranch[branch] {
+			subject, _, _ := strings.Cut(c.message, "\n")
+			fmt.Fprintf(&b, "• %s\n", subject)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// standupPrompt asks for a short update on the work the commits show
+func standupPrompt(since string, commits []standupCommit, glossary map[string]string) string {
+	var b strings.Builder
+	branches, byBranch := standupByBranch(commits)
+	for _, branch := range branches {
+		fmt.Fprintf(&b, "Branch %s:\n", branch)
+		for _, c := range byBranch[branch] {
+			subject, body, _ := strings.Cut(c.message, "\n")
+			fmt.Fprintf(&b, "- %s\n", subject)
+			if body = strings.TrimSpace(body); body != "" {
+				if len(body) > commitBodyLimit {
+					body = body[:commitBodyLimit] + "..."
+				}
+				b.WriteString("  " + strings.ReplaceAll(body, "\n", "\n  ") + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return fmt.Sprintf(`You are helping a developer write their standup update. Based on the following commits they made since %s, grouped by branch, summarize what they worked on.
+
+%s Don't make up plans or blockers.
+
+Commits:
+%s%s
+Write a short update in the first person, as the developer would post it in a chat channel: a few bullets starting with "• ", one per piece of work rather than per commit, in plain words a teammate outside the code would follow. Mention a branch only when it helps tell pieces of work apart. Keep it under 8 bullets.
+
+Respond with ONLY the bullets, with no heading, greeting, Markdown headings, or code blocks.`, since, groundingRule("commits"), b.String(), generate.GlossaryPrompt(glossary))
+}
+
+// runStandup handles gitcat standup: it prints a summary of the author's
+// commits since --since across branches, ready to paste into chat
+func runStandup(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: gitcat standup [--since <date>] [--author <who>]")
+		os.Exit(exitError)
+	}
+	author, err := standupAuthor(*authorFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	commits, err := standupCommits(*sinceFlag, author)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitGitFailure)
+	}
+	if len(commits) == 0 {
+		fmt.Fprintf(os.Stderr, "No commits by %s since %s.\n", author, *sinceFlag)
+		os.Exit(exitNothingToCommit)
+	}
+	if *offlineFlag {
+		fmt.Println(offlineStandup(commits))
+		return
+	}
+
+	config := getEffectiveConfig()
+	config.Model = config.GetPRModel()
+	prompt := standupPrompt(*sinceFlag, commits, config.Glossary)
+
+	fmt.Fprintf(os.Stderr, "Summarizing %d commits since %s...\n", len(commits), *sinceFlag)
+	msg := generateMsg(context.Background(), config, prompt, config.GetPRParams(), true)
+	summary, ok := msg.(prContentMsg)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", msg)
+		os.Exit(exitAPIFailure)
+	}
+	fmt.Println(strings.TrimSpace(string(summary)))
+}
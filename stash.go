@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Unstaged stash modes (Config.StashUnstaged)
+const (
+	stashUnstagedAsk    = "ask"    // Offer to stash when unstaged changes exist (default)
+	stashUnstagedAlways = "always" // Stash without asking
+	stashUnstagedNever  = "never"  // Leave unstaged changes in place
+)
+
+// unstagedStashMessage labels the stash entry holding the unstaged changes
+const unstagedStashMessage = "gitcat: unstaged changes"
+
+// hasUnstagedChanges reports whether tracked files differ from the index
+func hasUnstagedChanges() bool {
+	_, err := runCommand(exec.Command("git", "diff", "--quiet"))
+	return err != nil
+}
+
+// stashUnstaged saves the unstaged changes to tracked files in a stash entry
+// and removes them from the working tree, so it holds exactly what is
+// staged. Untracked files are left alone. It returns the stash commit.
+func stashUnstaged() (string, error) {
+	output, err := runCommand(exec.Command("git", "stash", "create", unstagedStashMessage))
+	if err != nil {
+		return "", fmt.Errorf("git stash create failed: %w\n%s", err, string(output))
+	}
+	sha := strings.TrimSpace(string(output))
+	if sha == "" {
+		return "", fmt.Errorf("there are no local changes to stash")
+	}
+	// Listed in git stash so the changes can be recovered by hand if gitcat
+	// dies before restoring them
+	if output, err := runCommand(exec.Command("git", "stash", "store", "-m", unstagedStashMessage, sha)); err != nil {
+		return "", fmt.Errorf("git stash store failed: %w\n%s", err, string(output))
+	}
+	if output, err := runCommand(exec.Command("git", "restore", "--worktree", "--", ":/")); err != nil {
+		return "", fmt.Errorf("git restore failed: %w\n%s", err, string(output))
+	}
+	return sha, nil
+}
+
+// unstashUnstaged reapplies the changes saved by stashUnstaged on top of the
+// working tree, then drops the stash entry. Only the unstaged part of the
+// stash is applied; what was staged has been committed or is still staged.
+func unstashUnstaged(sha string) error {
+	// The stash's second parent records the index, so this is the unstaged part
+	patch, err := runCommand(exec.Command("git", "diff", "--binary", sha+"^2", sha))
+	if err != nil {
+		return fmt.Errorf("git diff failed: %w\n%s", err, string(patch))
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "apply", "--whitespace=nowarn")
+	cmd.Dir = root
+	cmd.Stdin = bytes.NewReader(patch)
+	if output, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("git apply failed: %w\n%s", err, string(output))
+	}
+	return dropStash(sha)
+}
+
+// dropStash removes the stash entry for sha, wherever it is in the list
+func dropStash(sha string) error {
+	output, err := runCommand(exec.Command("git", "stash", "list", "--format=%H"))
+	if err != nil {
+		return fmt.Errorf("git stash list failed: %w", err)
+	}
+	i := slices.Index(strings.Fields(string(output)), sha)
+	if i < 0 {
+		return nil
+	}
+	if output, err := runCommand(exec.Command("git", "stash", "drop", "-q", fmt.Sprintf("stash@{%d}", i))); err != nil {
+		return fmt.Errorf("git stash drop failed: %w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// shouldOfferStash reports whether to deal with unstaged changes before
+// verifying and generating. Rewording, squashing, reverting, merges, and
+// empty commits don't commit the index as staged, and split groups are
+// unstaged on purpose.
+func (m model) shouldOfferStash() bool {
+	if m.stashAsked || m.reword != nil || m.revert != nil || m.merge != nil || m.emptyCommit || m.splitTree != "" {
+		return false
+	}
+	if getEffectiveConfig().StashUnstaged == stashUnstagedNever {
+		return false
+	}
+	return hasUnstagedChanges()
+}
+
+// enterStashPromptPhase offers to set the unstaged changes aside, or does so
+// straight away when the config says to always stash
+func (m model) enterStashPromptPhase() (model, tea.Cmd) {
+	if getEffectiveConfig().StashUnstaged == stashUnstagedAlways {
+		return m.stashAndContinue()
+	}
+	m.phase = "stash_prompt"
+	m.cursor = 0
+	m.choices = []string{tr("Yes, stash them until the commit is made"), tr("No, leave them in place")}
+	return m, nil
+}
+
+// stashAndContinue stashes the unstaged changes and carries on generating
+func (m model) stashAndContinue() (model, tea.Cmd) {
+	m.stashAsked = true
+	sha, err := stashUnstaged()
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Error stashing unstaged changes: %v", err)
+		m.exitCode = exitGitFailure
+		return m, tea.Quit
+	}
+	m.unstagedStash = sha
+	return m.startGeneration()
+}
+
+// restoreUnstaged brings the stashed unstaged changes back, if any. On
+// failure the stash entry is kept and the returned error says how to
+// recover it.
+func (m model) restoreUnstaged() (model, error) {
+	if m.unstagedStash == "" {
+		return m, nil
+	}
+	if err := unstashUnstaged(m.unstagedStash); err != nil {
+		return m, fmt.Errorf("%v\nYour unstaged changes are saved in the stash; restore them with: git stash apply %s", err, m.unstagedStash)
+	}
+	m.unstagedStash = ""
+	return m, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const maxStyleExampleLen = 400 // Characters kept from each example message
+
+// maxExemplarLen caps each exemplar message, which is shown whole since it
+// sets the standard for the body as well as the subject
+const maxExemplarLen = 1500
+
+// getStyleExamples returns up to n recent commit messages to show the model
+// how this repository writes them. Merges, fixups, and reverts are skipped
+// since they follow git's wording rather than the project's.
+func getStyleExamples(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	// Read extra commits so skipped ones don't leave too few examples
+	cmd := exec.Command("git", "log", "--no-merges", "-n", strconv.Itoa(n*2), "--format=%B%x00")
+	output, err := runCommand(cmd)
+	if err != nil {
+		return nil // No commits yet
+	}
+	var examples []string
+	for _, message := range strings.Split(string(output), "\x00") {
+		message = strings.TrimSpace(message)
+		if message == "" || strings.HasPrefix(message, "fixup!") || strings.HasPrefix(message, "squash!") || strings.HasPrefix(message, "Revert \"") {
+			continue
+		}
+		examples = append(examples, truncateRunes(message, maxStyleExampleLen))
+		if len(examples) == n {
+			break
+		}
+	}
+	return examples
+}
+
+// getExemplars returns the messages of the commits chosen as the
+// repository's gold standard, from example_commits in the config and
+// gitcat.exampleCommit in git config. Commits that don't resolve, such as
+// ones from another repository, are skipped.
+func getExemplars() []string {
+	var exemplars []string
+	for _, rev := range mergeValues(getEffectiveConfig().ExampleCommits, gitConfigAll("gitcat.exampleCommit")) {
+		output, err := runCommand(exec.Command("git", "log", "-1", "--format=%B", rev+"^{commit}", "--"))
+		if err != nil {
+			continue
+		}
+		if message := strings.TrimSpace(string(output)); message != "" {
+			exemplars = append(exemplars, truncateRunes(message, maxExemplarLen))
+		}
+	}
+	return exemplars
+}
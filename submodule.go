@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// submoduleMaxCommits caps the commits listed for one submodule update
+const submoduleMaxCommits = 20
+
+// submoduleCommits parses the old and new commits from a submodule's
+// "Subproject commit" lines. Either is empty when the submodule was added
+// or removed.
+func submoduleCommits(hunks string) (oldSHA, newSHA string, ok bool) {
+	for _, line := range strings.Split(hunks, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		sha, found := strings.CutPrefix(line[1:], "Subproject commit ")
+		if !found {
+			continue
+		}
+		sha = strings.TrimSuffix(strings.TrimSpace(sha), "-dirty")
+		switch line[0] {
+		case '-':
+			oldSHA, ok = sha, true
+		case '+':
+			newSHA, ok = sha, true
+		}
+	}
+	return oldSHA, newSHA, ok
+}
+
+// submoduleLog lists the submodule's commits in from..to, newest first, as
+// "abc1234 subject" lines. Missing commits are fetched once, since a
+// submodule is often bumped to commits the local clone hasn't seen.
+func submoduleLog(path, from, to string) ([]string, error) {
+	args := []string{"-C", path, "log", "--no-merges", "--format=%h %s", from + ".." + to}
+	output, err := runCommand(exec.Command("git", args...))
+	if err != nil {
+		fetch := exec.Command("git", "-C", path, "fetch", "--quiet")
+		fetch.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		if _, fetchErr := runCommand(fetch); fetchErr != nil {
+			return nil, fmt.Errorf("git log failed: %w", err)
+		}
+		if output, err = runCommand(exec.Command("git", args...)); err != nil {
+			return nil, fmt.Errorf("git log failed: %w", err)
+		}
+	}
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
+// isAncestor reports whether a is an ancestor of b in the submodule at path
+func isAncestor(path, a, b string) bool {
+	_, err := runCommand(exec.Command("git", "-C", path, "merge-base", "--is-ancestor", a, b))
+	return err == nil
+}
+
+// describeSubmodule replaces a submodule pointer change, which the diff
+// shows only as opaque "Subproject commit" lines, with the commits it brings
+// in (or drops), e.g. "# submodule lib updated a1b2c3d -> d4e5f6a, 2 new
+// commits:" followed by their subjects. Other files are returned unchanged.
+func describeSubmodule(f fileDiff) fileDiff {
+	oldSHA, newSHA, ok := submoduleCommits(f.hunks)
+	if !ok {
+		return f
+	}
+
+	var notes []string
+	switch {
+	case oldSHA == "":
+		notes = append(notes, fmt.Sprintf("# submodule %s added at %s", f.path, shortSHA(newSHA)))
+	case newSHA == "":
+		notes = append(notes, fmt.Sprintf("# submodule %s removed (was at %s)", f.path, shortSHA(oldSHA)))
+	default:
+		from, to, verb := oldSHA, newSHA, "new"
+		if isAncestor(f.path, newSHA, oldSHA) {
+			// Moved back: list what the rewind drops
+			from, to, verb = newSHA, oldSHA, "dropped"
+		}
+		note := fmt.Sprintf("# submodule %s updated %s -> %s", f.path, shortSHA(oldSHA), shortSHA(newSHA))
+		commits, err := submoduleLog(f.path, from, to)
+		if err != nil {
+			debugf("submodule log for %s unavailable: %v", f.path, err)
+			notes = append(notes, note+" (commit log unavailable)")
+			break
+		}
+		notes = append(notes, fmt.Sprintf("%s, %d %s commit(s):", note, len(commits), verb))
+		for i, commit := range commits {
+			if i == submoduleMaxCommits {
+				notes = append(notes, fmt.Sprintf("#   ... and %d more", len(commits)-i))
+				break
+			}
+			notes = append(notes, "#   "+commit)
+		}
+	}
+
+	// Keep only the "diff --git" line; the rest of the header is git metadata
+	first, _, _ := strings.Cut(f.header, "\n")
+	return fileDiff{path: f.path, header: first + "\n" + strings.Join(notes, "\n") + "\n"}
+}
+
+// shortSHA abbreviates a commit hash for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
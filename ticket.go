@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Ticket placements (Config.TicketPlacement)
+const (
+	ticketFooter = "footer" // "Refs: JIRA-123" footer, and a line in the PR body (default)
+	ticketPrefix = "prefix" // Before the description: "feat(api): JIRA-123 add ..."
+	ticketOff    = "off"
+)
+
+// defaultTicketPattern matches JIRA-style keys (ABC-123) and GitHub issue
+// numbers (#456) in branch names
+const defaultTicketPattern = `[A-Z][A-Z0-9]+-[0-9]+|#[0-9]+`
+
+// defaultTicketTrailer is the footer key used for tickets
+const defaultTicketTrailer = "Refs"
+
+// validateTicketConfig checks the ticket pattern and placement
+func validateTicketConfig(config *Config) error {
+	switch config.TicketPlacement {
+	case "", ticketFooter, ticketPrefix, ticketOff:
+	default:
+		return fmt.Errorf("unknown ticket_placement %q (use footer, prefix, or off)", config.TicketPlacement)
+	}
+	if config.TicketPattern != "" {
+		if _, err := regexp.Compile(config.TicketPattern); err != nil {
+			return fmt.Errorf("invalid ticket_pattern: %w", err)
+		}
+	}
+	if strings.ContainsAny(config.TicketTrailer, " \t:") {
+		return fmt.Errorf("invalid ticket_trailer %q", config.TicketTrailer)
+	}
+	if config.TicketFooter != "" {
+		// Executing against empty data also catches unknown fields
+		tmpl, err := template.New("ticket_footer").Parse(config.TicketFooter)
+		if err == nil {
+			err = tmpl.Execute(io.Discard, trailerContext{})
+		}
+		if err != nil {
+			return fmt.Errorf("invalid ticket_footer: %w", err)
+		}
+	}
+	return nil
+}
+
+// branchTicket extracts the ticket ID from a branch name: the pattern's
+// first capture group if it has one, otherwise the whole match. --ticket
+// takes the place of the branch name's.
+func branchTicket(config *Config, branch string) string {
+	if config.TicketPlacement == ticketOff {
+		return ""
+	}
+	if *ticketFlag != "" {
+		return *ticketFlag
+	}
+	pattern := config.TicketPattern
+	if pattern == "" {
+		pattern = defaultTicketPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "" // Validated when the config was loaded
+	}
+	match := re.FindStringSubmatch(branch)
+	if match == nil {
+		return ""
+	}
+	if len(match) > 1 && match[1] != "" {
+		return match[1]
+	}
+	return match[0]
+}
+
+// ticketTrailer returns the footer key for tickets
+func ticketTrailer(config *Config) string {
+	if config.TicketTrailer != "" {
+		return config.TicketTrailer
+	}
+	return defaultTicketTrailer
+}
+
+// renderTicketFooter renders the footer that references the ticket: ticket_footer,
+// a template over the same values as trailers, or "Refs: <ticket>"
+func renderTicketFooter(config *Config, ctx trailerContext) string {
+	fallback := ticketTrailer(config) + ": " + ctx.Ticket
+	if config.TicketFooter == "" {
+		return fallback
+	}
+	tmpl, err := template.New("ticket_footer").Parse(config.TicketFooter)
+	if err != nil {
+		return fallback // Validated when the config was loaded
+	}
+	var footer strings.Builder
+	if err := tmpl.Execute(&footer, ctx); err != nil {
+		debugf("ticket_footer: %v", err)
+		return fallback
+	}
+	return strings.TrimSpace(footer.String())
+}
+
+// prefixTicket puts ticket before the description of a subject, after the
+// conventional "type(scope): " if there is one
+func prefixTicket(subject, ticket string) string {
+	if prefix, description, ok := strings.Cut(subject, ": "); ok && messageType(subject) != "" {
+		return prefix + ": " + ticket + " " + description
+	}
+	return ticket + " " + subject
+}
+
+// addTicket adds the branch's ticket to a commit message as a footer or
+// subject prefix, unless the message already mentions it
+func addTicket(config *Config, message string, ctx trailerContext) string {
+	ticket := ctx.Ticket
+	if ticket == "" || strings.Contains(message, ticket) {
+		return message
+	}
+	if config.TicketPlacement == ticketPrefix {
+		subject, rest, hasBody := strings.Cut(message, "\n")
+		subject = prefixTicket(subject, ticket)
+		if hasBody {
+			return subject + "\n" + rest
+		}
+		return subject
+	}
+	footer := renderTicketFooter(config, ctx)
+	if footer == "" {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + footer
+}
+
+// addPRTicket adds the branch's ticket to a PR title or body, matching
+// where commits get it
+func addPRTicket(config *Config, title, body string, ctx trailerContext) (string, string) {
+	ticket := ctx.Ticket
+	if ticket == "" || strings.Contains(title, ticket) || strings.Contains(body, ticket) {
+		return title, body
+	}
+	if config.TicketPlacement == ticketPrefix {
+		return prefixTicket(title, ticket), body
+	}
+	footer := renderTicketFooter(config, ctx)
+	if footer == "" {
+		return title, body
+	}
+	return title, strings.TrimRight(body, "\n") + "\n\n" + footer
+}
@@ -0,0 +1,104 @@
+package main
+
+import "testing"
+
+func TestValidateTicketConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"defaults", Config{}, false},
+		{"prefix", Config{TicketPlacement: ticketPrefix, TicketPattern: `(?i)gc-[0-9]+`}, false},
+		{"footer template", Config{TicketFooter: "Closes {{.Ticket}}"}, false},
+		{"unknown placement", Config{TicketPlacement: "suffix"}, true},
+		{"invalid pattern", Config{TicketPattern: `[A-Z`}, true},
+		{"invalid trailer", Config{TicketTrailer: "Fixes:"}, true},
+		{"unknown footer field", Config{TicketFooter: "Closes {{.Issue}}"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTicketConfig(&tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("validateTicketConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBranchTicket(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		flag   string
+		branch string
+		want   string
+	}{
+		{"JIRA key", Config{}, "", "feat/JIRA-123-retries", "JIRA-123"},
+		{"issue number", Config{}, "", "fix/#456-crash", "#456"},
+		{"none", Config{}, "", "main", ""},
+		{"lowercase key", Config{}, "", "feat/jira-123-retries", ""},
+		{"custom pattern", Config{TicketPattern: `gc-[0-9]+`}, "", "feat/gc-7-retries", "gc-7"},
+		{"capture group", Config{TicketPattern: `issue-([0-9]+)`}, "", "issue-42-crash", "42"},
+		{"--ticket", Config{}, "OPS-9", "feat/JIRA-123-retries", "OPS-9"},
+		{"off", Config{TicketPlacement: ticketOff}, "OPS-9", "feat/JIRA-123-retries", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, ticketFlag, tt.flag)
+			if got := branchTicket(&tt.config, tt.branch); got != tt.want {
+				t.Errorf("branchTicket(%q) = %q, want %q", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTicket(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		ticket  string
+		message string
+		want    string
+	}{
+		{"no ticket", Config{}, "", "feat: add retries", "feat: add retries"},
+		{"footer", Config{}, "JIRA-12", "feat: add retries\n", "feat: add retries\n\nRefs: JIRA-12"},
+		{"footer after body", Config{}, "JIRA-12", "feat: add retries\n\nRetry twice.", "feat: add retries\n\nRetry twice.\n\nRefs: JIRA-12"},
+		{"custom trailer", Config{TicketTrailer: "Issue"}, "#45", "fix: crash", "fix: crash\n\nIssue: #45"},
+		{"footer template", Config{TicketFooter: "Closes {{.Ticket}} on {{.Branch}}"}, "#45", "fix: crash", "fix: crash\n\nCloses #45 on fix/#45"},
+		{"prefix", Config{TicketPlacement: ticketPrefix}, "JIRA-12", "feat(api): add retries\n\nRetry twice.", "feat(api): JIRA-12 add retries\n\nRetry twice."},
+		{"prefix without type", Config{TicketPlacement: ticketPrefix}, "JIRA-12", "Add retries", "JIRA-12 Add retries"},
+		{"already mentioned", Config{}, "JIRA-12", "feat: add retries\n\nPart of JIRA-12.", "feat: add retries\n\nPart of JIRA-12."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := trailerContext{Ticket: tt.ticket, Branch: "fix/#45"}
+			if got := addTicket(&tt.config, tt.message, ctx); got != tt.want {
+				t.Errorf("addTicket(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddPRTicket(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		title     string
+		body      string
+		wantTitle string
+		wantBody  string
+	}{
+		{"footer", Config{}, "Add retries", "Retries failed requests.\n", "Add retries", "Retries failed requests.\n\nRefs: JIRA-12"},
+		{"prefix", Config{TicketPlacement: ticketPrefix}, "feat: add retries", "Body", "feat: JIRA-12 add retries", "Body"},
+		{"in title", Config{}, "JIRA-12: add retries", "Body", "JIRA-12: add retries", "Body"},
+		{"in body", Config{}, "Add retries", "Fixes JIRA-12", "Add retries", "Fixes JIRA-12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, body := addPRTicket(&tt.config, tt.title, tt.body, trailerContext{Ticket: "JIRA-12"})
+			if title != tt.wantTitle || body != tt.wantBody {
+				t.Errorf("addPRTicket() = %q, %q, want %q, %q", title, body, tt.wantTitle, tt.wantBody)
+			}
+		})
+	}
+}
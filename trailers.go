@@ -0,0 +1,174 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// trailerContext is the data available to trailer value templates
+type trailerContext struct {
+	Branch   string
+	Ticket   string // Ticket ID from the branch name (see branchTicket)
+	Type     string
+	Scope    string
+	Author   string // Committer name
+	Email    string // Committer email
+	Date     string // YYYY-MM-DD
+	ChangeID string // Gerrit-style Change-Id, stable for the run
+}
+
+// validateTrailerRules checks trailer keys and parses their templates
+func validateTrailerRules(rules []TrailerRule) error {
+	for _, rule := range rules {
+		if rule.Key == "" || strings.ContainsAny(rule.Key, " \t:") {
+			return fmt.Errorf("invalid trailer key %q", rule.Key)
+		}
+		// Executing against empty data also catches unknown fields
+		tmpl, err := template.New(rule.Key).Parse(rule.Value)
+		if err == nil {
+			err = tmpl.Execute(io.Discard, trailerContext{})
+		}
+		if err != nil {
+			return fmt.Errorf("invalid template for trailer %s: %w", rule.Key, err)
+		}
+	}
+	return nil
+}
+
+// renderTrailers expands the configured trailers into "Key: value" lines
+func renderTrailers(rules []TrailerRule, ctx trailerContext) []string {
+	var trailers []string
+	for _, rule := range rules {
+		tmpl, err := template.New(rule.Key).Parse(rule.Value)
+		if err != nil {
+			continue // Validated when the config was loaded
+		}
+		var value strings.Builder
+		if err := tmpl.Execute(&value, ctx); err != nil {
+			debugf("trailer %s: %v", rule.Key, err)
+			continue
+		}
+		if v := strings.TrimSpace(value.String()); v != "" {
+			trailers = append(trailers, rule.Key+": "+v)
+		}
+	}
+	return trailers
+}
+
+// newChangeID returns a random Gerrit-style Change-Id ("I" + 40 hex digits)
+func newChangeID() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return "I" + hex.EncodeToString(b)
+}
+
+// trailerContext gathers the values trailer templates can use
+func (m model) trailerContext() trailerContext {
+	name, email, _ := strings.Cut(strings.TrimSuffix(m.committerIdent, ">"), " <")
+	return trailerContext{
+		Branch:   m.currentBranch,
+		Ticket:   branchTicket(getEffectiveConfig(), m.currentBranch),
+		Type:     messageType(m.generatedMsg),
+		Scope:    m.scopeInput,
+		Author:   name,
+		Email:    email,
+		Date:     time.Now().Format("2006-01-02"),
+		ChangeID: m.changeID,
+	}
+}
+
+// stringListFlag collects the values of a flag that may be repeated
+type stringListFlag []string
+
+// stringListVar defines a repeatable string flag
+func stringListVar(name, usage string) *stringListFlag {
+	f := new(stringListFlag)
+	flag.Var(f, name, usage)
+	return f
+}
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// resolveCoAuthor expands a --co-author value. A full "Name <email>" is
+// used as is; anything else must match exactly one configured co-author by
+// case-insensitive substring (e.g. a first name or email user).
+func resolveCoAuthor(value string, known []string) (string, error) {
+	if strings.Contains(value, "<") && strings.HasSuffix(value, ">") {
+		return value, nil
+	}
+	var matches []string
+	for _, k := range known {
+		if strings.Contains(strings.ToLower(k), strings.ToLower(value)) {
+			matches = append(matches, k)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return "", fmt.Errorf("co-author %q is not in co_authors; use \"Name <email>\"", value)
+	}
+	return "", fmt.Errorf("co-author %q is ambiguous: %s", value, strings.Join(matches, ", "))
+}
+
+// enterCoAuthorsPhase shows the co-author checklist
+func (m model) enterCoAuthorsPhase() model {
+	m.phase = "coauthors"
+	m.cursor = 0
+	return m
+}
+
+// commitOptions returns the commit switches with trailers for the chosen
+// co-authors and the configured custom trailers
+func (m model) commitOptions() commitOptions {
+	opts := m.commitOpts
+	opts.Trailers = append([]string{}, opts.Trailers...)
+	for _, author := range m.coAuthors {
+		if m.coAuthorsSelected[author] {
+			opts.Trailers = append(opts.Trailers, "Co-authored-by: "+author)
+		}
+	}
+	if rules := getEffectiveConfig().Trailers; len(rules) > 0 {
+		opts.Trailers = append(opts.Trailers, renderTrailers(rules, m.trailerContext())...)
+	}
+	// Gerrit tracks a change across amended patch sets by its Change-Id
+	hasChangeID := slices.ContainsFunc(opts.Trailers, func(trailer string) bool {
+		return strings.HasPrefix(trailer, "Change-Id: ")
+	})
+	if m.gerrit != nil && !hasChangeID {
+		opts.Trailers = append(opts.Trailers, "Change-Id: "+m.changeID)
+	}
+	// A reworded commit's own trailers may repeat the configured ones
+	seen := make(map[string]bool, len(opts.Trailers))
+	opts.Trailers = slices.DeleteFunc(opts.Trailers, func(trailer string) bool {
+		duplicate := seen[trailer]
+		seen[trailer] = true
+		return duplicate
+	})
+	return opts
+}
+
+// selectedCoAuthorCount counts the co-authors chosen for the commit
+func (m model) selectedCoAuthorCount() int {
+	n := 0
+	for _, author := range m.coAuthors {
+		if m.coAuthorsSelected[author] {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidateTrailerRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []TrailerRule
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"literal", []TrailerRule{{Key: "Reviewed-by", Value: "Team"}}, false},
+		{"template", []TrailerRule{{Key: "Change-Id", Value: "{{.ChangeID}}"}, {Key: "Branch", Value: "{{.Branch}}"}}, false},
+		{"empty key", []TrailerRule{{Key: "", Value: "x"}}, true},
+		{"key with space", []TrailerRule{{Key: "Signed off", Value: "x"}}, true},
+		{"key with colon", []TrailerRule{{Key: "Refs:", Value: "x"}}, true},
+		{"unparsable template", []TrailerRule{{Key: "Refs", Value: "{{.Ticket"}}, true},
+		{"unknown field", []TrailerRule{{Key: "Refs", Value: "{{.Issue}}"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTrailerRules(tt.rules); (err != nil) != tt.wantErr {
+				t.Errorf("validateTrailerRules() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderTrailers(t *testing.T) {
+	ctx := trailerContext{Branch: "feat/JIRA-12-retries", Ticket: "JIRA-12", Type: "feat", Author: "Ada", ChangeID: "I0123"}
+	tests := []struct {
+		name  string
+		rules []TrailerRule
+		want  []string
+	}{
+		{"literal", []TrailerRule{{Key: "Reviewed-by", Value: "Team"}}, []string{"Reviewed-by: Team"}},
+		{
+			"templates",
+			[]TrailerRule{{Key: "Change-Id", Value: "{{.ChangeID}}"}, {Key: "Branch", Value: " {{.Branch}} "}},
+			[]string{"Change-Id: I0123", "Branch: feat/JIRA-12-retries"},
+		},
+		{"empty value skipped", []TrailerRule{{Key: "Scope", Value: "{{.Scope}}"}, {Key: "Type", Value: "{{.Type}}"}}, []string{"Type: feat"}},
+		{"conditional", []TrailerRule{{Key: "Refs", Value: "{{if .Ticket}}{{.Ticket}}{{end}}"}}, []string{"Refs: JIRA-12"}},
+		{"invalid skipped", []TrailerRule{{Key: "Refs", Value: "{{.Ticket"}, {Key: "By", Value: "{{.Author}}"}}, []string{"By: Ada"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTrailers(tt.rules, ctx); !slices.Equal(got, tt.want) {
+				t.Errorf("renderTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewChangeID(t *testing.T) {
+	id := newChangeID()
+	if len(id) != 41 || id[0] != 'I' {
+		t.Errorf("newChangeID() = %q, want I and 40 hex digits", id)
+	}
+	if newChangeID() == id {
+		t.Error("newChangeID() returned the same ID twice")
+	}
+}
+
+func TestResolveCoAuthor(t *testing.T) {
+	known := []string{"Ada Lovelace <ada@example.com>", "Alan Turing <alan@example.com>", "Grace Hopper <grace@example.com>"}
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"full identity", "Linus <linus@example.com>", "Linus <linus@example.com>", false},
+		{"first name", "grace", "Grace Hopper <grace@example.com>", false},
+		{"email user", "alan@", "Alan Turing <alan@example.com>", false},
+		{"ambiguous", "a", "", true},
+		{"unknown", "barbara", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveCoAuthor(tt.value, known)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("resolveCoAuthor(%q) = %q, %v, want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
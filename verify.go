@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// verifyDoneMsg reports the result of the verify command
+type verifyDoneMsg struct {
+	output string
+	err    error
+}
+
+// verifyTickMsg refreshes the elapsed time while the verify command runs
+type verifyTickMsg struct{}
+
+// getVerifyCommand returns the check to run before generating a message:
+// the repository's gitcat.verifyCommand git config, or verify_command from
+// the gitcat config
+func getVerifyCommand() string {
+	output, err := runCommand(exec.Command("git", "config", "--get", "gitcat.verifyCommand"))
+	if command := strings.TrimSpace(string(output)); err == nil && command != "" {
+		return command
+	}
+	return strings.TrimSpace(getEffectiveConfig().VerifyCommand)
+}
+
+// runVerify runs command through the shell from the repository root.
+// Cancelling ctx kills it along with the commands it started.
+func runVerify(ctx context.Context, command string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := shellCommandContext(ctx, command)
+		killProcessTreeOnCancel(cmd)
+		// Output pipes held open by anything that escaped the kill don't
+		// keep the result waiting
+		cmd.WaitDelay = time.Second
+		if root, err := getRepoRoot(); err == nil {
+			cmd.Dir = root
+		}
+		output, err := runCommand(cmd)
+		return verifyDoneMsg{output: string(output), err: err}
+	}
+}
+
+// verifyTick schedules the next elapsed-time refresh
+func verifyTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return verifyTickMsg{}
+	})
+}
+
+// startVerify runs the verify command and shows its progress. esc and
+// quitting stop it.
+func (m model) startVerify() (model, tea.Cmd) {
+	m.phase = "verifying"
+	m.verifyOutput = ""
+	ctx, cancel := context.WithCancel(context.Background())
+	m = m.trackRequest(m.verifyCommand, cancel)
+	return m, tea.Batch(runVerify(ctx, m.verifyCommand), verifyTick())
+}
+
+// enterVerifyFailedPhase shows the output of a failed verify command
+func (m model) enterVerifyFailedPhase(output string) model {
+	m.phase = "verify_failed"
+	m.verifyOutput = output
+	m.cursor = 0
+	m.choices = []string{tr("Retry"), tr("Quit")}
+	return m
+}
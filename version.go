@@ -1,3 +1,5 @@
 package main
 
-const ( Version = "v0.0.18" )
+const (
+	Version = "v0.0.18"
+)